@@ -0,0 +1,155 @@
+package app
+
+import (
+	"os"
+	"sync"
+	"time"
+
+	"github.com/PhilippHeuer/fuzzmux/pkg/config"
+	"github.com/PhilippHeuer/fuzzmux/pkg/layout"
+	"github.com/PhilippHeuer/fuzzmux/pkg/recon"
+	"github.com/PhilippHeuer/fuzzmux/pkg/util"
+	"github.com/rs/zerolog/log"
+)
+
+// RenderPreview renders the preview of the option, followed by the output of the preview command if configured
+func RenderPreview(conf config.Config, module recon.Module, option *recon.Option) string {
+	preview := renderPreview(conf, module, option)
+
+	command := PreviewCommand(conf, module, option)
+	if command == "" {
+		return preview
+	}
+
+	output := util.RunPreviewCommand(option.ResolvePlaceholders(command), previewDirectory(option), previewOptions(conf))
+	return preview + "\n" + output
+}
+
+// renderPreview renders the preview of the option, the preview template of the module is used if configured
+// template errors are shown in the preview, followed by the default preview of the module
+func renderPreview(conf config.Config, module recon.Module, option *recon.Option) string {
+	previewConfig, ok := conf.Previews[module.Name()]
+	if !ok || (previewConfig.Template == "" && previewConfig.TemplateFile == "") {
+		return recon.RenderPreview(module, option)
+	}
+
+	tmpl, err := previewConfig.ResolveTemplate()
+	if err == nil {
+		var preview string
+		preview, err = recon.RenderPreviewTemplate(module, option, tmpl)
+		if err == nil {
+			return preview
+		}
+	}
+
+	log.Debug().Err(err).Str("recon", module.Name()).Msg("failed to render preview template")
+	return err.Error() + "\n\n" + recon.RenderPreview(module, option)
+}
+
+// previewCommandWait is the time the embedded finder waits for the output of a preview command while drawing
+const previewCommandWait = 100 * time.Millisecond
+
+// PreviewRenderer returns the preview function of the embedded finder
+// the finder renders the preview while drawing, so preview commands run in the background and their output is shown on the next redraw
+func PreviewRenderer(conf config.Config, modules []recon.Module) func(option *recon.Option) string {
+	commands := &previewCommands{outputs: make(map[string]*previewCommandOutput)}
+
+	return func(option *recon.Option) string {
+		module, err := FindReconModuleByName(modules, option.ProviderName)
+		if err != nil {
+			return option.RenderPreview()
+		}
+		preview := renderPreview(conf, module, option)
+
+		command := PreviewCommand(conf, module, option)
+		if command == "" {
+			return preview
+		}
+
+		output, ok := commands.run(option.ResolvePlaceholders(command), previewDirectory(option), previewOptions(conf), previewCommandWait)
+		if !ok {
+			return preview + "\nrunning preview command ..."
+		}
+		return preview + "\n" + output
+	}
+}
+
+// previewCommands runs every preview command once, the output is kept for the lifetime of the finder
+type previewCommands struct {
+	mutex   sync.Mutex
+	outputs map[string]*previewCommandOutput
+}
+
+type previewCommandOutput struct {
+	done   chan struct{}
+	output string
+}
+
+// run starts the command if it has not been started yet and waits up to wait for its output, ok is false if it is still running
+func (c *previewCommands) run(command string, dir string, opts util.PreviewCommandOptions, wait time.Duration) (string, bool) {
+	key := dir + "\x00" + command
+
+	c.mutex.Lock()
+	result, exists := c.outputs[key]
+	if !exists {
+		result = &previewCommandOutput{done: make(chan struct{})}
+		c.outputs[key] = result
+		go func() {
+			result.output = util.RunPreviewCommand(command, dir, opts)
+			close(result.done)
+		}()
+	}
+	c.mutex.Unlock()
+
+	select {
+	case <-result.done:
+		return result.output, true
+	case <-time.After(wait):
+		return "", false
+	}
+}
+
+// PreviewCommand returns the preview command of the option, the command of the module takes precedence over the command of the layout
+func PreviewCommand(conf config.Config, module recon.Module, option *recon.Option) string {
+	if previewConfig, ok := conf.Previews[module.Name()]; ok && previewConfig.Command != "" {
+		return previewConfig.Command
+	}
+
+	l, err := layout.GetLayout(conf, option, "", DefaultLayoutName(option))
+	if err != nil {
+		return ""
+	}
+	return l.PreviewCommand
+}
+
+// DefaultLayoutName returns the layout used if no layout rule matches, defaults to the module name and can be overridden using the layout context key
+func DefaultLayoutName(option *recon.Option) string {
+	if option.Context["layout"] != "" {
+		return option.Context["layout"]
+	}
+	return option.ProviderName
+}
+
+// previewDirectory returns the directory of the preview command, the start directory is only created when the option is opened
+func previewDirectory(option *recon.Option) string {
+	dir := option.ResolveStartDirectory(true)
+	if _, err := os.Stat(dir); err != nil {
+		return os.Getenv("HOME")
+	}
+	return dir
+}
+
+func previewOptions(conf config.Config) util.PreviewCommandOptions {
+	var finder config.FinderConfig
+	if conf.Finder != nil {
+		finder = *conf.Finder
+	}
+
+	opts := util.PreviewCommandOptions{
+		Timeout: time.Duration(finder.PreviewTimeout) * time.Second,
+	}
+	if finder.PreviewCacheAge != nil {
+		opts.CacheAge = time.Duration(*finder.PreviewCacheAge) * time.Second
+	}
+	return opts
+}
@@ -9,7 +9,11 @@ import (
 	"github.com/PhilippHeuer/fuzzmux/pkg/recon"
 	"github.com/PhilippHeuer/fuzzmux/pkg/recon/backstage"
 	"github.com/PhilippHeuer/fuzzmux/pkg/recon/chrome"
+	"github.com/PhilippHeuer/fuzzmux/pkg/recon/confluence"
+	"github.com/PhilippHeuer/fuzzmux/pkg/recon/container"
+	"github.com/PhilippHeuer/fuzzmux/pkg/recon/database"
 	"github.com/PhilippHeuer/fuzzmux/pkg/recon/firefox"
+	"github.com/PhilippHeuer/fuzzmux/pkg/recon/githost"
 	"github.com/PhilippHeuer/fuzzmux/pkg/recon/jira"
 	"github.com/PhilippHeuer/fuzzmux/pkg/recon/keycloak"
 	"github.com/PhilippHeuer/fuzzmux/pkg/recon/kubernetes"
@@ -39,6 +43,8 @@ func ConfigToReconModules(conf config.Config) []recon.Module {
 			modules = append(modules, kubernetes.NewModule(*cfg))
 		case *usql.ModuleConfig:
 			modules = append(modules, usql.NewModule(*cfg))
+		case *database.ModuleConfig:
+			modules = append(modules, database.NewModule(*cfg))
 		case *ldap.ModuleConfig:
 			modules = append(modules, ldap.NewModule(*cfg))
 		case *keycloak.ModuleConfig:
@@ -47,12 +53,18 @@ func ConfigToReconModules(conf config.Config) []recon.Module {
 			modules = append(modules, backstage.NewModule(*cfg))
 		case *jira.ModuleConfig:
 			modules = append(modules, jira.NewModule(*cfg))
+		case *confluence.ModuleConfig:
+			modules = append(modules, confluence.NewModule(*cfg))
 		case *rundeck.ModuleConfig:
 			modules = append(modules, rundeck.NewModule(*cfg))
 		case *firefox.ModuleConfig:
 			modules = append(modules, firefox.NewModule(*cfg))
 		case *chrome.ModuleConfig:
 			modules = append(modules, chrome.NewModule(*cfg))
+		case *container.ModuleConfig:
+			modules = append(modules, container.NewModule(*cfg))
+		case *githost.ModuleConfig:
+			modules = append(modules, githost.NewModule(*cfg))
 		default:
 			log.Error().Interface("module", m).Msg("unrecognized module type")
 		}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/PhilippHeuer/fuzzmux/pkg/config"
+	"github.com/PhilippHeuer/fuzzmux/pkg/finder"
+	"github.com/PhilippHeuer/fuzzmux/pkg/recon"
+	"github.com/PhilippHeuer/fuzzmux/pkg/types"
+	"github.com/rs/zerolog/log"
+)
+
+// runOptionAction runs an action of the module on the selected option, the action is chosen using the finder if actionId is empty
+func runOptionAction(module recon.Module, selected *recon.Option, actionId string, input string, finderConfig config.FinderConfig) error {
+	actionModule, ok := module.(recon.ActionModule)
+	if !ok {
+		return fmt.Errorf("module %s does not support actions", module.Name())
+	}
+
+	actions, err := actionModule.Actions(selected)
+	if err != nil {
+		return fmt.Errorf("failed to query actions: %w", err)
+	}
+
+	// choose action
+	var action *recon.Action
+	if actionId == "" {
+		var options []recon.Option
+		for _, a := range actions {
+			options = append(options, recon.Option{Id: a.Id, DisplayName: a.Name})
+		}
+
+		// the preview command only knows about module options
+		finderConfig.Preview = false
+		s, err := finder.FuzzyFinder(options, finderConfig)
+		if err != nil {
+			return errors.Join(types.ErrNoOptionSelected, err)
+		}
+		actionId = s.Id
+	}
+	for _, a := range actions {
+		if a.Id == actionId {
+			action = &a
+			break
+		}
+	}
+	if action == nil {
+		return fmt.Errorf("action %q is not available for %s", actionId, selected.Id)
+	}
+
+	// input
+	if action.Prompt != "" && input == "" {
+		input, err = finder.Prompt(action.Prompt)
+		if err != nil {
+			return err
+		}
+	}
+
+	log.Debug().Str("action", action.Id).Str("id", selected.Id).Msg("running action")
+	err = actionModule.RunAction(selected, action.Id, input)
+	if err != nil {
+		return err
+	}
+
+	// keep the cached option up to date, e.g. the new status of a ticket
+	return recon.UpdateCachedOption(selected.ProviderName, *selected)
+}
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+
+	"github.com/PhilippHeuer/fuzzmux/pkg/app"
+	"github.com/PhilippHeuer/fuzzmux/pkg/config"
+	"github.com/PhilippHeuer/fuzzmux/pkg/recon"
+	"github.com/PhilippHeuer/fuzzmux/pkg/recon/database"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+func utilDatabaseConnectCmd() *cobra.Command {
+	var client string
+
+	cmd := &cobra.Command{
+		Use:   "db-connect <option-id>",
+		Short: "Connect to a database of the database module, credentials are passed to the client using environment variables",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			conf, err := config.ResolvedConfig()
+			if err != nil {
+				log.Fatal().Err(err).Msg("failed to load configuration")
+			}
+
+			// find connection
+			var conn *database.Connection
+			for _, m := range app.ConfigToReconModules(conf) {
+				module, ok := m.(database.Module)
+				if !ok {
+					continue
+				}
+
+				options, err := module.OptionsOrCache(3600)
+				if err != nil {
+					log.Debug().Err(err).Str("recon", module.Name()).Msg("failed to get options")
+				}
+				option, err := recon.OptionById(options, args[0])
+				if err != nil {
+					continue
+				}
+				conn, err = module.Connection(option)
+				if err != nil {
+					log.Fatal().Err(err).Str("id", args[0]).Msg("failed to read connection")
+				}
+				break
+			}
+			if conn == nil {
+				log.Fatal().Str("id", args[0]).Msg("database connection not found")
+			}
+
+			// client
+			if client == "" {
+				client = conn.DefaultClient()
+			}
+			clientArgs, env, err := conn.ClientCommand(client)
+			if err != nil {
+				log.Fatal().Err(err).Str("id", args[0]).Msg("failed to prepare client")
+			}
+
+			log.Debug().Strs("args", clientArgs).Msg("starting database client")
+			c := exec.Command(clientArgs[0], clientArgs[1:]...)
+			c.Env = append(os.Environ(), env...)
+			c.Stdin = os.Stdin
+			c.Stdout = os.Stdout
+			c.Stderr = os.Stderr
+			if err = c.Run(); err != nil {
+				var exitErr *exec.ExitError
+				if errors.As(err, &exitErr) {
+					os.Exit(exitErr.ExitCode())
+				}
+				log.Fatal().Err(err).Str("client", client).Msg("failed to start database client")
+			}
+		},
+	}
+
+	cmd.Flags().StringVar(&client, "client", "", "client used to connect (psql, mysql or usql), defaults to psql or mysql based on the driver, usql otherwise")
+
+	return cmd
+}
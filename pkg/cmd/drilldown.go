@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/PhilippHeuer/fuzzmux/pkg/config"
+	"github.com/PhilippHeuer/fuzzmux/pkg/finder"
+	"github.com/PhilippHeuer/fuzzmux/pkg/recon"
+	"github.com/PhilippHeuer/fuzzmux/pkg/types"
+	"github.com/rs/zerolog/log"
+)
+
+// runDrilldown shows the related options of the selected option (e.g. the members of a group) and returns the chosen one
+func runDrilldown(module recon.Module, selected recon.Option, finderConfig config.FinderConfig) (recon.Option, error) {
+	drilldownModule, ok := module.(recon.DrilldownModule)
+	if !ok {
+		return recon.Option{}, fmt.Errorf("module %s does not support drilldowns", module.Name())
+	}
+
+	drilldowns := drilldownModule.Drilldowns(&selected)
+	if len(drilldowns) == 0 {
+		return recon.Option{}, fmt.Errorf("no related options available for %s", selected.Id)
+	}
+
+	// choose drilldown, if there is more than one
+	drilldownId := drilldowns[0].Id
+	if len(drilldowns) > 1 {
+		var options []recon.Option
+		for _, d := range drilldowns {
+			options = append(options, recon.Option{Id: d.Id, DisplayName: d.Name})
+		}
+
+		// the preview command only knows about module options
+		choiceConfig := finderConfig
+		choiceConfig.Preview = false
+		s, err := finder.FuzzyFinder(options, choiceConfig)
+		if err != nil {
+			return recon.Option{}, errors.Join(types.ErrNoOptionSelected, err)
+		}
+		drilldownId = s.Id
+	}
+
+	// choose option
+	options, err := drilldownModule.DrilldownOptions(&selected, drilldownId)
+	if err != nil {
+		return recon.Option{}, fmt.Errorf("failed to query related options: %w", err)
+	}
+	s, err := finder.FuzzyFinder(options, finderConfig)
+	if err != nil {
+		return recon.Option{}, errors.Join(types.ErrNoOptionSelected, err)
+	}
+	log.Debug().Str("drilldown", drilldownId).Str("id", s.Id).Msg("selected related option")
+
+	err = module.SelectOption(&s)
+	if err != nil {
+		return recon.Option{}, err
+	}
+
+	return s, nil
+}
@@ -56,13 +56,14 @@ func previewCmd() *cobra.Command {
 			if err != nil {
 				log.Fatal().Err(err).Str("recon", option.ProviderName).Msg("failed to get recon of selected option")
 			}
+			option.Preview = true
 			err = selectedProvider.SelectOption(option)
 			if err != nil {
 				log.Fatal().Err(err).Str("recon", option.ProviderName).Msg("failed to run option select")
 			}
 
 			// print preview
-			fmt.Printf("%s\n", option.RenderPreview())
+			fmt.Printf("%s\n", app.RenderPreview(conf, selectedProvider, option))
 		},
 	}
 
@@ -6,14 +6,17 @@ import (
 	"github.com/PhilippHeuer/fuzzmux/pkg/launcher"
 	"github.com/PhilippHeuer/fuzzmux/pkg/layout"
 	"github.com/PhilippHeuer/fuzzmux/pkg/recon"
+	"io"
 	"os"
 	"strings"
 
 	"github.com/PhilippHeuer/fuzzmux/pkg/config"
 	"github.com/PhilippHeuer/fuzzmux/pkg/extensions"
 	"github.com/PhilippHeuer/fuzzmux/pkg/finder"
+	"github.com/PhilippHeuer/fuzzmux/pkg/secret"
 	"github.com/PhilippHeuer/fuzzmux/pkg/types"
 	"github.com/cidverse/cidverseutils/zerologconfig"
+	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
 )
@@ -25,6 +28,10 @@ type RootFlags struct {
 	template    string
 	mode        string
 	selected    string
+	input       string
+	actions     bool
+	action      string
+	drilldown   bool
 	maxCacheAge int
 	showTags    []string
 	hideTags    []string
@@ -37,7 +44,7 @@ func rootCmd() *cobra.Command {
 		Use:   `tmx`,
 		Short: `scans source directories for projects to create tmux sessions`,
 		PersistentPreRun: func(cmd *cobra.Command, args []string) {
-			zerologconfig.Configure(cfg)
+			configureLogging(cfg)
 		},
 		Args: cobra.MinimumNArgs(0),
 		Run: func(cmd *cobra.Command, args []string) {
@@ -51,10 +58,7 @@ func rootCmd() *cobra.Command {
 			selected, err := optionFuzzyFinder(conf, args, flags)
 
 			// layout
-			defaultLayout := selected.ProviderName
-			if selected.Context["layout"] != "" {
-				defaultLayout = selected.Context["layout"]
-			}
+			defaultLayout := app.DefaultLayoutName(&selected)
 			log.Debug().Str("default-layout", defaultLayout).Msg("default layout, if template is not specified")
 
 			// template
@@ -88,6 +92,10 @@ func rootCmd() *cobra.Command {
 	cmd.PersistentFlags().StringVarP(&flags.template, "template", "t", "", "template to create the tmux session")
 	cmd.PersistentFlags().StringVar(&flags.mode, "mode", "", "return data in custom format to use an external fuzzy finder (valid: telescope)")
 	cmd.PersistentFlags().StringVar(&flags.selected, "select", "", "skips the finder and directly selects the given id")
+	cmd.PersistentFlags().StringVar(&flags.input, "input", "", "input for options that require it (e.g. the repository to clone), prompts if not set")
+	cmd.PersistentFlags().BoolVar(&flags.actions, "actions", false, "choose an action for the selected option (e.g. transition a ticket) instead of opening it")
+	cmd.PersistentFlags().StringVar(&flags.action, "action", "", "runs the given action id on the selected option instead of opening it")
+	cmd.PersistentFlags().BoolVar(&flags.drilldown, "drilldown", false, "choose a related option of the selected option (e.g. a member of a group) and open it instead")
 	cmd.PersistentFlags().IntVar(&flags.maxCacheAge, "cache-age", 300, "maximum age of the cache in seconds")
 	cmd.PersistentFlags().StringSliceVar(&flags.showTags, "show-tags", []string{}, "only show elements with the given tags, all others will be hidden")
 	cmd.PersistentFlags().StringSliceVar(&flags.hideTags, "hide-tags", []string{}, "tags to hide from the fuzzy finder")
@@ -128,6 +136,7 @@ func optionFuzzyFinder(conf config.Config, args []string, flags RootFlags) (reco
 	}
 
 	// fuzzy finder or direct selection
+	conf.Finder.PreviewRenderer = app.PreviewRenderer(conf, modules)
 	var selected recon.Option
 	if flags.selected == "" {
 		s, err := finder.FuzzyFinder(options, *conf.Finder)
@@ -155,5 +164,64 @@ func optionFuzzyFinder(conf config.Config, args []string, flags RootFlags) (reco
 		log.Fatal().Err(err).Str("recon", selected.ProviderName).Msg("failed to run select")
 	}
 
+	// run an action instead of opening the option
+	if flags.actions || flags.action != "" {
+		err = runOptionAction(selectedProvider, &selected, flags.action, flags.input, *conf.Finder)
+		if err != nil {
+			log.Fatal().Err(err).Str("recon", selected.ProviderName).Msg("failed to run action")
+		}
+		os.Exit(0)
+	}
+
+	// open a related option instead, e.g. a member of the selected group
+	if flags.drilldown {
+		selected, err = runDrilldown(selectedProvider, selected, *conf.Finder)
+		if err != nil {
+			log.Fatal().Err(err).Str("recon", selected.ProviderName).Msg("failed to choose related option")
+		}
+	}
+
+	// options that require user input, e.g. clone a repository
+	if inputModule, ok := selectedProvider.(recon.InputModule); ok {
+		if prompt := inputModule.InputPrompt(&selected); prompt != "" {
+			input := flags.input
+			if input == "" {
+				input, err = finder.Prompt(prompt)
+				if err != nil {
+					log.Fatal().Err(err).Str("recon", selected.ProviderName).Msg("failed to read input")
+				}
+			}
+
+			err = inputModule.ApplyInput(&selected, input)
+			if err != nil {
+				log.Fatal().Err(err).Str("recon", selected.ProviderName).Msg("failed to apply input")
+			}
+		}
+	}
+
+	// side effects, e.g. clone a repository
+	if prepareModule, ok := selectedProvider.(recon.PrepareModule); ok {
+		err = prepareModule.PrepareOption(&selected)
+		if err != nil {
+			log.Fatal().Err(err).Str("recon", selected.ProviderName).Msg("failed to prepare option")
+		}
+	}
+
 	return selected, nil
 }
+
+// configureLogging configures zerolog, resolved secrets and sensitive fields are redacted from all log events
+func configureLogging(cfg zerologconfig.LogConfig) {
+	zerologconfig.Configure(cfg)
+
+	var out io.Writer
+	switch cfg.LogFormat {
+	case "json":
+		out = os.Stderr
+	case "color":
+		out = zerolog.ConsoleWriter{Out: os.Stderr}
+	default:
+		out = zerolog.ConsoleWriter{Out: os.Stderr, NoColor: true}
+	}
+	log.Logger = log.Output(secret.NewRedactWriter(out))
+}
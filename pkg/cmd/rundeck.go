@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PhilippHeuer/fuzzmux/pkg/config"
+	"github.com/PhilippHeuer/fuzzmux/pkg/finder"
+	"github.com/PhilippHeuer/fuzzmux/pkg/recon"
+	"github.com/PhilippHeuer/fuzzmux/pkg/recon/rundeck"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+// rundeckFollowInterval is the interval used to poll the execution output
+const rundeckFollowInterval = 2 * time.Second
+
+func utilRundeckRunCmd() *cobra.Command {
+	var host, token string
+	var values []string
+
+	cmd := &cobra.Command{
+		Use:   "rundeck-run <job-id>",
+		Short: "Run a rundeck job, prompts for the job options and follows the execution output",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			conf, err := config.ResolvedConfig()
+			if err != nil {
+				log.Fatal().Err(err).Msg("failed to load configuration")
+			}
+			client := rundeck.NewClient(host, token)
+
+			// option values
+			given := make(map[string]string)
+			for _, v := range values {
+				key, value, _ := strings.Cut(v, "=")
+				given[key] = value
+			}
+			jobOptions, err := client.GetJobOptions(args[0])
+			if err != nil {
+				log.Fatal().Err(err).Str("job", args[0]).Msg("failed to query job options")
+			}
+			optionValues, err := promptJobOptions(jobOptions, given, *conf.Finder)
+			if err != nil {
+				log.Fatal().Err(err).Str("job", args[0]).Msg("failed to read job options")
+			}
+
+			// run
+			execution, err := client.RunJob(args[0], optionValues)
+			if err != nil {
+				log.Fatal().Err(err).Str("job", args[0]).Msg("failed to run job")
+			}
+			fmt.Printf("execution #%d started: %s\n", execution.ID, execution.Permalink)
+
+			followRundeckExecution(client, execution.ID)
+		},
+	}
+
+	cmd.Flags().StringVar(&host, "host", os.Getenv("RD_URL"), "rundeck url, defaults to RD_URL")
+	cmd.Flags().StringVar(&token, "token", os.Getenv("RD_TOKEN"), "rundeck api token, defaults to RD_TOKEN")
+	cmd.Flags().StringArrayVar(&values, "option", []string{}, "job option value as key=value, prompts for all other options")
+
+	return cmd
+}
+
+func utilRundeckFollowCmd() *cobra.Command {
+	var host, token string
+
+	cmd := &cobra.Command{
+		Use:   "rundeck-follow <execution-id>",
+		Short: "Follow the output of a rundeck execution",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			executionID, err := strconv.Atoi(args[0])
+			if err != nil {
+				log.Fatal().Err(err).Str("execution", args[0]).Msg("invalid execution id")
+			}
+
+			followRundeckExecution(rundeck.NewClient(host, token), executionID)
+		},
+	}
+
+	cmd.Flags().StringVar(&host, "host", os.Getenv("RD_URL"), "rundeck url, defaults to RD_URL")
+	cmd.Flags().StringVar(&token, "token", os.Getenv("RD_TOKEN"), "rundeck api token, defaults to RD_TOKEN")
+
+	return cmd
+}
+
+// followRundeckExecution prints the execution output and exits with a non-zero code if the execution did not succeed
+func followRundeckExecution(client *rundeck.Client, executionID int) {
+	state, err := rundeck.FollowExecution(client, executionID, os.Stdout, rundeckFollowInterval)
+	if err != nil {
+		log.Fatal().Err(err).Int("execution", executionID).Msg("failed to follow execution")
+	}
+
+	fmt.Printf("execution #%d finished: %s\n", executionID, state)
+	if !strings.EqualFold(state, "succeeded") {
+		os.Exit(1)
+	}
+}
+
+// promptJobOptions asks for all job options that are not given, enforced values are selected using the finder
+func promptJobOptions(jobOptions []rundeck.JobOption, given map[string]string, finderConfig config.FinderConfig) (map[string]string, error) {
+	result := make(map[string]string)
+	finderConfig.Preview = false
+
+	for _, o := range jobOptions {
+		if v, ok := given[o.Name]; ok {
+			result[o.Name] = v
+			continue
+		}
+
+		label := o.Name
+		if o.Description != "" {
+			label = fmt.Sprintf("%s (%s)", o.Name, o.Description)
+		}
+		if o.Value != "" && !o.Secure {
+			label = fmt.Sprintf("%s [%s]", label, o.Value)
+		}
+
+		var value string
+		var err error
+		if o.Enforced && len(o.Values) > 0 {
+			var choices []recon.Option
+			for _, v := range o.Values {
+				choices = append(choices, recon.Option{Id: v, DisplayName: fmt.Sprintf("%s: %s", o.Name, v)})
+			}
+			var selected recon.Option
+			selected, err = finder.FuzzyFinder(choices, finderConfig)
+			value = selected.Id
+		} else if o.Secure {
+			value, err = finder.PromptSecret(label)
+		} else {
+			value, err = finder.Prompt(label)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if value == "" {
+			value = o.Value
+		}
+		if value == "" && o.Required {
+			return nil, fmt.Errorf("option %s is required", o.Name)
+		}
+		if value != "" {
+			result[o.Name] = value
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+
+	"github.com/PhilippHeuer/fuzzmux/pkg/app"
+	"github.com/PhilippHeuer/fuzzmux/pkg/config"
+	"github.com/PhilippHeuer/fuzzmux/pkg/recon"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+func utilWithSecretsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "with-secrets <module> <option-id> -- <command> [args...]",
+		Short: "Run a command with the secrets of an option, the secrets are resolved by the module and passed as environment variables",
+		Args:  cobra.MinimumNArgs(3),
+		Run: func(cmd *cobra.Command, args []string) {
+			if cmd.ArgsLenAtDash() != 2 {
+				log.Fatal().Msg("the command must be separated by --, e.g. tmx util with-secrets ldap <id> -- bash")
+			}
+
+			conf, err := config.ResolvedConfig()
+			if err != nil {
+				log.Fatal().Err(err).Msg("failed to load configuration")
+			}
+
+			// find option
+			module, err := app.FindReconModuleByName(app.ConfigToReconModules(conf), args[0])
+			if err != nil {
+				log.Fatal().Err(err).Str("recon", args[0]).Msg("module not found")
+			}
+			options, err := module.OptionsOrCache(3600)
+			if err != nil {
+				log.Fatal().Err(err).Str("recon", module.Name()).Msg("failed to get options")
+			}
+			option, err := recon.SecretOptionById(module, options, args[1])
+			if err != nil {
+				log.Fatal().Err(err).Str("recon", module.Name()).Msg("option not found")
+			}
+
+			// secrets
+			env, err := recon.SecretEnv(module, option)
+			if err != nil {
+				log.Fatal().Err(err).Str("recon", module.Name()).Str("id", option.Id).Msg("failed to resolve secrets")
+			}
+
+			log.Debug().Strs("args", args[2:]).Strs("env", option.Secrets).Msg("starting command with secrets")
+			c := exec.Command(args[2], args[3:]...)
+			c.Env = append(os.Environ(), env...)
+			c.Stdin = os.Stdin
+			c.Stdout = os.Stdout
+			c.Stderr = os.Stderr
+			if err = c.Run(); err != nil {
+				var exitErr *exec.ExitError
+				if errors.As(err, &exitErr) {
+					os.Exit(exitErr.ExitCode())
+				}
+				log.Fatal().Err(err).Str("command", args[2]).Msg("failed to start command")
+			}
+		},
+	}
+
+	return cmd
+}
@@ -21,6 +21,10 @@ func utilCmd() *cobra.Command {
 	cmd.AddCommand(utilFocusedPidCmd())
 	cmd.AddCommand(utilFocusedCwdCmd())
 	cmd.AddCommand(utilFocusedKillCmd())
+	cmd.AddCommand(utilRundeckRunCmd())
+	cmd.AddCommand(utilRundeckFollowCmd())
+	cmd.AddCommand(utilDatabaseConnectCmd())
+	cmd.AddCommand(utilWithSecretsCmd())
 
 	return cmd
 }
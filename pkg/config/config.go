@@ -5,7 +5,11 @@ import (
 
 	"github.com/PhilippHeuer/fuzzmux/pkg/recon/backstage"
 	"github.com/PhilippHeuer/fuzzmux/pkg/recon/chrome"
+	"github.com/PhilippHeuer/fuzzmux/pkg/recon/confluence"
+	"github.com/PhilippHeuer/fuzzmux/pkg/recon/container"
+	"github.com/PhilippHeuer/fuzzmux/pkg/recon/database"
 	"github.com/PhilippHeuer/fuzzmux/pkg/recon/firefox"
+	"github.com/PhilippHeuer/fuzzmux/pkg/recon/githost"
 	"github.com/PhilippHeuer/fuzzmux/pkg/recon/jira"
 	"github.com/PhilippHeuer/fuzzmux/pkg/recon/keycloak"
 	"github.com/PhilippHeuer/fuzzmux/pkg/recon/kubernetes"
@@ -22,6 +26,9 @@ type Config struct {
 	// Modules is a list of recon modules
 	Modules []ModuleConfig `yaml:"-"`
 
+	// Previews contains the preview templates and commands of the modules, keyed by module name
+	Previews map[string]PreviewConfig `yaml:"-"`
+
 	// Layouts is a map of tmux layouts
 	Layouts map[string]Layout `yaml:"layouts"`
 
@@ -52,6 +59,7 @@ func (c *Config) UnmarshalYAML(value *yaml.Node) error {
 		return err
 	}
 	c.Modules = nil
+	c.Previews = make(map[string]PreviewConfig)
 	c.Finder = aux.Finder
 	c.Layouts = aux.Layouts
 	c.Launcher = aux.Launcher
@@ -59,12 +67,23 @@ func (c *Config) UnmarshalYAML(value *yaml.Node) error {
 	// parse the "recon" field into the appropriate ModuleConfig types
 	for key, moduleNode := range aux.Modules {
 		var typeInfo struct {
-			Type string `yaml:"type"`
+			Type    string        `yaml:"type"`
+			Name    string        `yaml:"name"`
+			Preview PreviewConfig `yaml:",inline"`
 		}
 		if err := moduleNode.Decode(&typeInfo); err != nil {
 			return fmt.Errorf("failed to decode type for module at index %d: %w", key, err)
 		}
 
+		// preview templates and commands are supported by all modules, the module name defaults to the type
+		if typeInfo.Preview != (PreviewConfig{}) {
+			name := typeInfo.Name
+			if name == "" {
+				name = typeInfo.Type
+			}
+			c.Previews[name] = typeInfo.Preview
+		}
+
 		var module ModuleConfig
 		switch typeInfo.Type {
 		case "static":
@@ -77,6 +96,8 @@ func (c *Config) UnmarshalYAML(value *yaml.Node) error {
 			module = &kubernetes.ModuleConfig{}
 		case "usql":
 			module = &usql.ModuleConfig{}
+		case "database":
+			module = &database.ModuleConfig{}
 		case "ldap":
 			module = &ldap.ModuleConfig{}
 		case "keycloak":
@@ -85,12 +106,18 @@ func (c *Config) UnmarshalYAML(value *yaml.Node) error {
 			module = &backstage.ModuleConfig{}
 		case "jira":
 			module = &jira.ModuleConfig{}
+		case "confluence":
+			module = &confluence.ModuleConfig{}
 		case "rundeck":
 			module = &rundeck.ModuleConfig{}
 		case "firefox":
 			module = &firefox.ModuleConfig{}
 		case "chrome":
 			module = &chrome.ModuleConfig{}
+		case "container":
+			module = &container.ModuleConfig{}
+		case "githost":
+			module = &githost.ModuleConfig{}
 		default:
 			return fmt.Errorf("unknown module type '%s' for key %d", typeInfo.Type, key)
 		}
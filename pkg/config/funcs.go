@@ -34,6 +34,13 @@ func ResolvedConfig() (Config, error) {
 	if config.Finder.FZFDelimiter == "" {
 		config.Finder.FZFDelimiter = "\x1F"
 	}
+	if config.Finder.PreviewTimeout == 0 {
+		config.Finder.PreviewTimeout = 3
+	}
+	if config.Finder.PreviewCacheAge == nil {
+		cacheAge := 30
+		config.Finder.PreviewCacheAge = &cacheAge
+	}
 
 	// load default templates
 	if config.Layouts == nil {
@@ -127,5 +134,15 @@ func MergeConfig(a Config, b Config) Config {
 		}
 	}
 
+	// merge previews
+	if b.Previews != nil {
+		if a.Previews == nil {
+			a.Previews = make(map[string]PreviewConfig)
+		}
+		for key, preview := range b.Previews {
+			a.Previews[key] = preview
+		}
+	}
+
 	return a
 }
@@ -1,5 +1,14 @@
 package config
 
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/PhilippHeuer/fuzzmux/pkg/recon"
+)
+
 type FinderConfig struct {
 	// Executable is the fuzzy finder, e.g. "fzf" or "embedded"
 	Executable string `yaml:"executable"`
@@ -9,6 +18,45 @@ type FinderConfig struct {
 
 	// FZFPreview can be used to overwrite the option delimiter
 	FZFDelimiter string `yaml:"fzf-delimiter"`
+
+	// PreviewTimeout is the maximum runtime of preview commands in seconds (default: 3)
+	PreviewTimeout int `yaml:"preview-timeout"`
+
+	// PreviewCacheAge is the time in seconds the output of preview commands is reused (default: 30), 0 disables the cache
+	PreviewCacheAge *int `yaml:"preview-cache-age"`
+
+	// PreviewRenderer renders the preview of the embedded finder, defaults to the default preview of the option
+	PreviewRenderer func(option *recon.Option) string `yaml:"-" json:"-"`
+}
+
+// PreviewConfig overrides the preview of a module using a text/template
+type PreviewConfig struct {
+	// Template is rendered with the option as data, e.g. {{ .Name }}, {{ .Context.status }} or {{ join .Tags ", " }}
+	Template string `yaml:"preview-template,omitempty"`
+
+	// TemplateFile is the path of a template file, e.g. ~/.config/fuzzmux/jira.md
+	TemplateFile string `yaml:"preview-template-file,omitempty"`
+
+	// Command is run when an option is highlighted, the output is shown below the preview (e.g. git log --oneline -n 10)
+	Command string `yaml:"preview-command,omitempty"`
+}
+
+// ResolveTemplate returns the template, the template file is read if no inline template is set
+func (c PreviewConfig) ResolveTemplate() (string, error) {
+	if c.Template != "" || c.TemplateFile == "" {
+		return c.Template, nil
+	}
+
+	file := c.TemplateFile
+	if rest, ok := strings.CutPrefix(file, "~/"); ok {
+		file = filepath.Join(os.Getenv("HOME"), rest)
+	}
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return "", fmt.Errorf("failed to read preview template: %w", err)
+	}
+
+	return string(content), nil
 }
 
 type Layout struct {
@@ -18,6 +66,9 @@ type Layout struct {
 	// Rules is a list of rules, at least one must match for this layout to be selected
 	Rules []string `yaml:"rules,omitempty"`
 
+	// PreviewCommand is run when an option using this layout is highlighted, unless the module defines a preview command
+	PreviewCommand string `yaml:"preview-command,omitempty"`
+
 	// ClearWorkspace indicates if the workspace should be cleared before starting the applications (only applies to window managers, default: false)
 	ClearWorkspace bool `yaml:"clear-workspace,omitempty"`
 }
@@ -4,6 +4,7 @@ import (
 	"slices"
 
 	"github.com/PhilippHeuer/fuzzmux/pkg/recon"
+	"github.com/PhilippHeuer/fuzzmux/pkg/secret"
 	"github.com/PhilippHeuer/fuzzmux/pkg/util"
 )
 
@@ -31,7 +32,7 @@ func GenerateOptionTable(options []recon.Option, columns []recon.Column) ([]stri
 			case "directory":
 				value = option.ResolveStartDirectory(true)
 			default:
-				value = option.Context[column.Key]
+				value = secret.RedactValue(column.Key, option.Context[column.Key])
 			}
 			row = append(row, value)
 		}
@@ -17,6 +17,9 @@ func FuzzyFinderEmbedded(options []recon.Option, cfg config.FinderConfig) (recon
 			if i == -1 {
 				return ""
 			}
+			if cfg.PreviewRenderer != nil {
+				return cfg.PreviewRenderer(&options[i])
+			}
 			return options[i].RenderPreview()
 		}))
 	}
@@ -0,0 +1,35 @@
+package finder
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// Prompt asks the user for a single line of input
+func Prompt(label string) (string, error) {
+	fmt.Fprintf(os.Stderr, "%s: ", label)
+
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", fmt.Errorf("failed to read input: %w", err)
+	}
+
+	return strings.TrimSpace(line), nil
+}
+
+// PromptSecret asks the user for a single line of input without echoing it
+func PromptSecret(label string) (string, error) {
+	fmt.Fprintf(os.Stderr, "%s: ", label)
+	defer fmt.Fprintln(os.Stderr)
+
+	value, err := term.ReadPassword(int(os.Stdin.Fd()))
+	if err != nil {
+		return "", fmt.Errorf("failed to read input: %w", err)
+	}
+
+	return strings.TrimSpace(string(value)), nil
+}
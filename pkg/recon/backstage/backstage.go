@@ -4,13 +4,14 @@ import (
 	"context"
 	"fmt"
 	"net/http"
-	"slices"
+	"net/url"
 	"strings"
 
 	"github.com/PhilippHeuer/fuzzmux/pkg/recon"
+	"github.com/PhilippHeuer/fuzzmux/pkg/recon/project"
 	"github.com/PhilippHeuer/fuzzmux/pkg/types"
 	"github.com/PhilippHeuer/fuzzmux/pkg/util"
-	"github.com/datolabs-io/go-backstage/v3"
+	"github.com/rs/zerolog/log"
 	"golang.org/x/oauth2"
 )
 
@@ -39,13 +40,27 @@ type ModuleConfig struct {
 	// AttributeMapping is a list of field mappings used to map additional attributes to context fields
 	AttributeMapping []types.FieldMapping `yaml:"attribute-mapping"`
 
-	// Query is a list of content types that should be queried
+	// Query is a list of kinds (e.g. component) or kinds with spec type (e.g. component/service) that should be queried
 	Query []string `yaml:"query"`
+
+	// WebLink is the link used as web url, either a link type or title or "source" for the source location, defaults to the catalog page
+	WebLink string `yaml:"web-link"`
+
+	// CloneDirectory enables cloning the source repository on selection into this directory
+	CloneDirectory string `yaml:"clone-directory"`
+
+	// CloneTemplate is the path of the clone inside the CloneDirectory, defaults to {{host}}/{{owner}}/{{repo}} (same as the project module)
+	CloneTemplate string `yaml:"clone-template"`
+
+	// CloneProtocol is https (default) or ssh
+	CloneProtocol string `yaml:"clone-protocol"`
 }
 
-func (c *ModuleConfig) DecodeConfig() {
-	c.Host = util.ResolveCredentialValue(c.Host)
-	c.BearerToken = util.ResolveCredentialValue(c.BearerToken)
+// sourceLocationAnnotation points to the repository of the entity
+const sourceLocationAnnotation = "backstage.io/source-location"
+
+func (c *ModuleConfig) DecodeConfig() error {
+	return util.ResolveCredentialValues(&c.Host, &c.BearerToken)
 }
 
 func (p Module) Name() string {
@@ -60,11 +75,13 @@ func (p Module) Type() string {
 }
 
 func (p Module) Options() ([]recon.Option, error) {
-	p.Config.DecodeConfig()
+	if err := p.Config.DecodeConfig(); err != nil {
+		return nil, fmt.Errorf("failed to resolve credentials: %w", err)
+	}
 	var result []recon.Option
 
 	// httpClient
-	var httpClient *http.Client
+	httpClient := http.DefaultClient
 	if p.Config.BearerToken != "" {
 		httpClient = oauth2.NewClient(context.Background(), oauth2.StaticTokenSource(&oauth2.Token{
 			AccessToken: p.Config.BearerToken,
@@ -72,18 +89,8 @@ func (p Module) Options() ([]recon.Option, error) {
 		}))
 	}
 
-	// connect
-	client, err := backstage.NewClient(p.Config.Host, "default", httpClient)
-	if err != nil {
-		return nil, fmt.Errorf("failed to connect to backstage: %w", err)
-	}
-
 	// query
-	entities, _, err := client.Catalog.Entities.List(context.Background(), &backstage.ListEntityOptions{
-		Filters: []string{},
-		Fields:  []string{},
-		Order:   []backstage.ListEntityOrder{{Direction: backstage.OrderDescending, Field: "metadata.name"}},
-	})
+	entities, err := queryEntities(httpClient, p.Config.Host, queryFilters(p.Config.Query))
 	if err != nil {
 		return nil, fmt.Errorf("failed to query backstage: %w", err)
 	}
@@ -94,46 +101,79 @@ func (p Module) Options() ([]recon.Option, error) {
 		if entityType != "" {
 			queryId = fmt.Sprintf("%s/%s", queryId, entityType)
 		}
+		namespace := entity.Metadata.Namespace
+		if namespace == "" {
+			namespace = "default"
+		}
 
-		if slices.Contains(p.Config.Query, queryId) || len(p.Config.Query) == 0 {
-			data := map[string]interface{}{
-				"kind":                 entity.Kind,
-				"spec.type":            getStringValue(entity.Spec, "type"),
-				"metadata.name":        entity.Metadata.Name,
-				"metadata.namespace":   entity.Metadata.Namespace,
-				"metadata.description": entity.Metadata.Description,
-				"consumedBy":           entityRelationToString(entity.Relations, "apiConsumedBy"),
-				"dependsOn":            entityRelationToString(entity.Relations, "dependsOn"),
-				"ownedBy":              entityRelationToString(entity.Relations, "ownedBy"),
-				"partOf":               entityRelationToString(entity.Relations, "partOf"),
+		data := map[string]interface{}{
+			"kind":                 entity.Kind,
+			"spec.type":            entityType,
+			"metadata.name":        entity.Metadata.Name,
+			"metadata.namespace":   namespace,
+			"metadata.title":       entity.Metadata.Title,
+			"metadata.description": entity.Metadata.Description,
+			"metadata.tags":        entity.Metadata.Tags,
+			"consumedBy":           entityRelationToString(entity.Relations, "apiConsumedBy"),
+			"dependsOn":            entityRelationToString(entity.Relations, "dependsOn"),
+			"ownedBy":              entityRelationToString(entity.Relations, "ownedBy"),
+			"partOf":               entityRelationToString(entity.Relations, "partOf"),
+		}
+		flattenValue("spec", entity.Spec, data)
+		flattenValue("metadata.labels", entity.Metadata.Labels, data)
+		flattenValue("metadata.annotations", entity.Metadata.Annotations, data)
+		for i, link := range entity.Metadata.Links {
+			data[fmt.Sprintf("metadata.links.%d.url", i)] = link.URL
+			data[fmt.Sprintf("metadata.links.%d.title", i)] = link.Title
+			data[fmt.Sprintf("metadata.links.%d.type", i)] = link.Type
+		}
+		sourceURL := sourceLocationURL(entity.Metadata.Annotations[sourceLocationAnnotation])
+		data["sourceUrl"] = sourceURL
+		attributes := recon.AttributeMapping(data, p.Config.AttributeMapping)
+
+		// web url
+		web := fmt.Sprintf("%s/catalog/%s/%s/%s", p.Config.Host, namespace, strings.ToLower(entity.Kind), entity.Metadata.Name)
+		if p.Config.WebLink == "source" && sourceURL != "" {
+			web = sourceURL
+		} else if link := findLink(entity.Metadata.Links, p.Config.WebLink); p.Config.WebLink != "" && link != "" {
+			web = link
+		}
+
+		opt := recon.Option{
+			ProviderName: p.Name(),
+			ProviderType: p.Type(),
+			Id:           entity.Metadata.Name,
+			DisplayName:  fmt.Sprintf("%s [%s]", entity.Metadata.Name, queryId),
+			Name:         entity.Metadata.Name,
+			Description:  entity.Metadata.Description,
+			Web:          web,
+			Tags:         []string{"backstage", entityType},
+			Context:      attributes,
+		}
+
+		// the source repository is cloned on selection
+		if p.Config.CloneDirectory != "" && sourceURL != "" {
+			ref, err := p.repositoryRef(sourceURL)
+			var path string
+			if err == nil {
+				path, err = project.ClonePath(p.cloneSource(), ref)
 			}
-			for key, value := range entity.Spec {
-				if str, ok := value.(string); ok { // TODO: handle nested objects
-					data["spec."+key] = str
+			if err == nil {
+				opt.StartDirectory = path
+				opt.ModuleContext = map[string]string{
+					"cloneUrl": ref.URL,
+					"host":     ref.Host,
+					"owner":    ref.Owner,
+					"repo":     ref.Repo,
 				}
+				opt.Context["layout"] = "project"
+			} else {
+				log.Debug().Err(err).Str("entity", entity.Metadata.Name).Msg("failed to parse source location")
 			}
-			for key, value := range entity.Metadata.Labels {
-				data["metadata.labels."+key] = value
-			}
-			for key, value := range entity.Metadata.Annotations {
-				data["metadata.annotations."+key] = value
-			}
-			attributes := recon.AttributeMapping(data, p.Config.AttributeMapping)
-
-			opt := recon.Option{
-				ProviderName: p.Name(),
-				ProviderType: p.Type(),
-				Id:           entity.Metadata.Name,
-				DisplayName:  fmt.Sprintf("%s [%s]", entity.Metadata.Name, queryId),
-				Name:         entity.Metadata.Name,
-				Description:  entity.Metadata.Description,
-				Web:          fmt.Sprintf("%s/catalog/%s/%s/%s", p.Config.Host, entity.Metadata.Namespace, strings.ToLower(entity.Kind), entity.Metadata.Name),
-				Tags:         []string{"backstage", entityType},
-				Context:      attributes,
-			}
-			opt.ProcessUserTemplateStrings(p.Config.DisplayName, p.Config.StartDirectory)
-			result = append(result, opt)
 		}
+
+		opt.ProcessUserTemplateStrings(p.Config.DisplayName, p.Config.StartDirectory)
+		result = append(result, opt)
 	}
 
 	return result, nil
@@ -144,6 +184,11 @@ func (p Module) OptionsOrCache(maxAge float64) ([]recon.Option, error) {
 }
 
 func (p Module) SelectOption(option *recon.Option) error {
+	// the directory is created by the clone
+	if option.ModuleContext["cloneUrl"] != "" {
+		return nil
+	}
+
 	err := option.CreateStartDirectoryIfMissing()
 	if err != nil {
 		return err
@@ -152,6 +197,44 @@ func (p Module) SelectOption(option *recon.Option) error {
 	return nil
 }
 
+// PrepareOption clones the source repository of the option, if cloning is enabled and it's not cloned yet
+func (p Module) PrepareOption(option *recon.Option) error {
+	if p.Config.CloneDirectory == "" || option.ModuleContext["cloneUrl"] == "" {
+		return nil
+	}
+
+	ref := project.RepositoryRef{
+		URL:   option.ModuleContext["cloneUrl"],
+		Host:  option.ModuleContext["host"],
+		Owner: option.ModuleContext["owner"],
+		Repo:  option.ModuleContext["repo"],
+	}
+	path, err := project.CloneRepository(p.cloneSource(), ref)
+	if err != nil {
+		return err
+	}
+	option.StartDirectory = path
+
+	return nil
+}
+
+func (p Module) cloneSource() project.SourceDirectory {
+	return project.SourceDirectory{
+		Directory:     p.Config.CloneDirectory,
+		CloneTemplate: p.Config.CloneTemplate,
+	}
+}
+
+// repositoryRef parses the source url, the protocol is applied to the host/owner/repo form
+func (p Module) repositoryRef(sourceURL string) (project.RepositoryRef, error) {
+	u, err := url.Parse(sourceURL)
+	if err != nil || u.Host == "" {
+		return project.RepositoryRef{}, fmt.Errorf("invalid source url %q", sourceURL)
+	}
+
+	return project.ParseRepositoryQuery(strings.Trim(u.Path, "/"), u.Host, p.Config.CloneProtocol)
+}
+
 func (p Module) Columns() []recon.Column {
 	return recon.DefaultColumns()
 }
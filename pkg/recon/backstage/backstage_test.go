@@ -0,0 +1,125 @@
+package backstage
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOptions(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path != "/api/catalog/entities/by-query" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		requests = append(requests, r.URL.RawQuery)
+
+		if r.URL.Query().Get("cursor") == "" {
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"items": []map[string]interface{}{{
+					"kind": "Component",
+					"metadata": map[string]interface{}{
+						"name":        "payments",
+						"namespace":   "default",
+						"description": "Payment service",
+						"tags":        []string{"go", "grpc"},
+						"annotations": map[string]string{"backstage.io/source-location": "url:https://github.com/acme/payments/tree/main/"},
+						"links":       []map[string]string{{"url": "https://grafana.example.com/d/payments", "title": "Dashboard", "type": "dashboard"}},
+					},
+					"spec": map[string]interface{}{
+						"type":         "service",
+						"lifecycle":    "production",
+						"providesApis": []string{"payments-api"},
+						"profile":      map[string]interface{}{"email": "team@example.com"},
+						"ports":        []map[string]interface{}{{"name": "http", "port": 8080}},
+					},
+				}},
+				"pageInfo": map[string]string{"nextCursor": "page2"},
+			})
+		} else {
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"items": []map[string]interface{}{{
+					"kind":     "Component",
+					"metadata": map[string]interface{}{"name": "web"},
+					"spec":     map[string]interface{}{"type": "website"},
+				}},
+			})
+		}
+	}))
+	defer server.Close()
+
+	cloneDir := t.TempDir()
+	module := NewModule(ModuleConfig{
+		Host:           server.URL,
+		BearerToken:    "secret",
+		Query:          []string{"component/service", "Component/Website", "api"},
+		WebLink:        "dashboard",
+		CloneDirectory: cloneDir,
+	})
+	options, err := module.Options()
+	require.NoError(t, err)
+	require.Len(t, options, 2)
+
+	// filters are only sent with the first request, the cursor contains them
+	assert.Equal(t, "filter=kind%3Dcomponent%2Cspec.type%3Dservice&filter=kind%3Dcomponent%2Cspec.type%3Dwebsite&filter=kind%3Dapi&limit=500&orderField=metadata.name%2Casc", requests[0])
+	assert.Equal(t, "cursor=page2&limit=500", requests[1])
+
+	opt := options[0]
+	assert.Equal(t, "payments [component/service]", opt.DisplayName)
+	assert.Equal(t, "https://grafana.example.com/d/payments", opt.Web)
+	assert.Equal(t, "production", opt.Context["spec.lifecycle"])
+	assert.Equal(t, "payments-api", opt.Context["spec.providesApis"])
+	assert.Equal(t, "team@example.com", opt.Context["spec.profile.email"])
+	assert.Equal(t, "8080", opt.Context["spec.ports.0.port"])
+	assert.Equal(t, "go, grpc", opt.Context["metadata.tags"])
+	assert.Equal(t, "Dashboard", opt.Context["metadata.links.0.title"])
+	assert.Equal(t, "https://github.com/acme/payments", opt.Context["sourceUrl"])
+
+	// clone target
+	assert.Equal(t, filepath.Join(cloneDir, "github.com", "acme", "payments"), opt.StartDirectory)
+	assert.Equal(t, "https://github.com/acme/payments.git", opt.ModuleContext["cloneUrl"])
+	assert.Equal(t, "project", opt.Context["layout"])
+
+	// fallback to the catalog page
+	assert.Equal(t, server.URL+"/catalog/default/component/web", options[1].Web)
+	assert.Empty(t, options[1].StartDirectory)
+}
+
+func TestQueryEntitiesPageLimit(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"items":    []map[string]interface{}{{"kind": "Component", "metadata": map[string]interface{}{"name": "svc"}}},
+			"pageInfo": map[string]string{"nextCursor": "next"},
+		})
+	}))
+	defer server.Close()
+
+	// the result is truncated once the page limit is reached
+	entities, err := queryEntities(server.Client(), server.URL, nil)
+	require.NoError(t, err)
+	assert.Equal(t, maxPages, requests)
+	assert.Len(t, entities, maxPages)
+}
+
+func TestSourceLocationURL(t *testing.T) {
+	assert.Equal(t, "https://github.com/acme/payments", sourceLocationURL("url:https://github.com/acme/payments/tree/main/"))
+	assert.Equal(t, "https://gitlab.com/group/sub/repo", sourceLocationURL("url:https://gitlab.com/group/sub/repo/-/tree/main/"))
+	assert.Equal(t, "https://github.com/acme/payments", sourceLocationURL("url:https://github.com/acme/payments.git"))
+	assert.Equal(t, "https://github.com/acme/tree", sourceLocationURL("url:https://github.com/acme/tree/blob/main/x"))
+	assert.Equal(t, "https://github.com/src/blob", sourceLocationURL("url:https://github.com/src/blob/tree/main/"))
+	assert.Equal(t, "https://gitlab.com/group/tree/repo", sourceLocationURL("url:https://gitlab.com/group/tree/repo/-/blob/main/x"))
+	assert.Equal(t, "https://bitbucket.org/acme/payments", sourceLocationURL("url:https://bitbucket.org/acme/payments/src/main/?at=main"))
+	assert.Equal(t, "", sourceLocationURL("file:/catalog-info.yaml"))
+}
@@ -0,0 +1,112 @@
+package backstage
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/datolabs-io/go-backstage/v3"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// pageSize is the number of entities requested per page
+	pageSize = 500
+
+	// maxPages limits the number of requested pages
+	maxPages = 100
+)
+
+type queryResponse struct {
+	Items      []backstage.Entity `json:"items"`
+	TotalItems int                `json:"totalItems"`
+	PageInfo   struct {
+		NextCursor string `json:"nextCursor"`
+	} `json:"pageInfo"`
+}
+
+// queryEntities queries the catalog using the by-query endpoint, which supports cursor based pagination
+func queryEntities(httpClient *http.Client, host string, filters []string) ([]backstage.Entity, error) {
+	var result []backstage.Entity
+
+	baseURL := strings.TrimSuffix(host, "/")
+	if !strings.HasSuffix(baseURL, "/api") {
+		baseURL += "/api"
+	}
+
+	cursor := ""
+	for page := 0; page < maxPages; page++ {
+		query := url.Values{}
+		if cursor != "" {
+			// the cursor contains the filters and order of the first request
+			query.Set("cursor", cursor)
+		} else {
+			for _, f := range filters {
+				query.Add("filter", f)
+			}
+			query.Set("orderField", "metadata.name,asc")
+		}
+		query.Set("limit", strconv.Itoa(pageSize))
+
+		log.Debug().Strs("filters", filters).Int("page", page).Msg("querying backstage entities")
+		var response queryResponse
+		if err := getJSON(httpClient, baseURL+"/catalog/entities/by-query?"+query.Encode(), &response); err != nil {
+			return nil, err
+		}
+
+		result = append(result, response.Items...)
+		cursor = response.PageInfo.NextCursor
+		if cursor == "" {
+			break
+		}
+	}
+
+	if cursor != "" {
+		log.Warn().Strs("filters", filters).Int("entities", len(result)).Int("maxPages", maxPages).Msg("backstage query exceeded the page limit, the result is truncated - use filters to narrow the query")
+	}
+
+	return result, nil
+}
+
+func getJSON(httpClient *http.Client, requestURL string, v interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, requestURL, nil)
+	if err != nil {
+		return fmt.Errorf("could not create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	if err = json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return nil
+}
+
+// queryFilters translates the query (kind or kind/type, e.g. component/service) into catalog filter expressions
+// conditions within a filter are combined with AND, multiple filters with OR
+func queryFilters(query []string) []string {
+	var filters []string
+	for _, q := range query {
+		kind, entityType, _ := strings.Cut(strings.ToLower(q), "/")
+		filter := "kind=" + kind
+		if entityType != "" {
+			filter += ",spec.type=" + entityType
+		}
+		filters = append(filters, filter)
+	}
+
+	return filters
+}
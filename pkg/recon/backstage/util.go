@@ -2,6 +2,10 @@ package backstage
 
 import (
 	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
 	"github.com/datolabs-io/go-backstage/v3"
 )
 
@@ -20,3 +24,75 @@ func entityRelationToString(data []backstage.EntityRelation, relationType string
 	}
 	return ""
 }
+
+// flattenValue adds nested maps and lists as dot-path keys, e.g. spec.profile.email or metadata.links.0.url
+// lists of scalar values are joined
+func flattenValue(prefix string, value interface{}, data map[string]interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			flattenValue(prefix+"."+key, item, data)
+		}
+	case map[string]string:
+		for key, item := range v {
+			data[prefix+"."+key] = item
+		}
+	case []interface{}:
+		var scalars []string
+		for i, item := range v {
+			switch item.(type) {
+			case map[string]interface{}, []interface{}:
+				flattenValue(fmt.Sprintf("%s.%d", prefix, i), item, data)
+			default:
+				scalars = append(scalars, fmt.Sprint(item))
+			}
+		}
+		if len(scalars) > 0 {
+			data[prefix] = strings.Join(scalars, ", ")
+		}
+	case nil:
+	default:
+		data[prefix] = fmt.Sprint(v)
+	}
+}
+
+// sourceLocationURL returns the repository url of a source-location annotation, e.g. url:https://github.com/org/repo/tree/main/ -> https://github.com/org/repo
+// gitlab marks the end of the project path (which can include subgroups) with a - segment, other hosts use tree, blob or src after owner/repo
+func sourceLocationURL(location string) string {
+	location, found := strings.CutPrefix(location, "url:")
+	if !found {
+		return ""
+	}
+	u, err := url.Parse(location)
+	if err != nil || u.Host == "" {
+		return ""
+	}
+
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if idx := slices.Index(segments, "-"); idx > 0 {
+		segments = segments[:idx]
+	} else {
+		for i := 2; i < len(segments); i++ {
+			if segments[i] == "tree" || segments[i] == "blob" || segments[i] == "src" {
+				segments = segments[:i]
+				break
+			}
+		}
+	}
+	path := strings.TrimSuffix(strings.Join(segments, "/"), ".git")
+	if path == "" {
+		return ""
+	}
+
+	return (&url.URL{Scheme: u.Scheme, User: u.User, Host: u.Host, Path: "/" + path}).String()
+}
+
+// findLink returns the url of the first link with a matching type or title
+func findLink(links []backstage.EntityLink, name string) string {
+	for _, l := range links {
+		if strings.EqualFold(l.Type, name) || strings.EqualFold(l.Title, name) {
+			return l.URL
+		}
+	}
+	return ""
+}
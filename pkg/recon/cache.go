@@ -13,7 +13,16 @@ import (
 
 var dataDir = filepath.Join(xdg.StateHome, "fuzzmux")
 
+// StateFile returns the path of a file in the state directory, modules can use it to persist data between runs
+func StateFile(name string) string {
+	return filepath.Join(dataDir, name)
+}
+
+// cacheVersion is increased when the cached options are incompatible, e.g. version 1 removed credentials from the module context
+const cacheVersion = 1
+
 type OptionsCache struct {
+	Version      int
 	ProviderName string
 	Options      []Option
 	CreatedAt    time.Time
@@ -21,6 +30,7 @@ type OptionsCache struct {
 
 func SaveOptions(providerName string, options []Option) error {
 	jsonData, err := json.MarshalIndent(OptionsCache{
+		Version:      cacheVersion,
 		ProviderName: providerName,
 		Options:      options,
 		CreatedAt:    time.Now(),
@@ -29,23 +39,35 @@ func SaveOptions(providerName string, options []Option) error {
 		return fmt.Errorf("failed to marshal options: %w", err)
 	}
 
-	err = os.MkdirAll(dataDir, 0755)
+	err = WriteCacheFile(filepath.Join(dataDir, fmt.Sprintf("recon-%s.json", providerName)), jsonData)
+	if err != nil {
+		return fmt.Errorf("failed to write options: %w", err)
+	}
+
+	return nil
+}
+
+// WriteCacheFile writes the file only readable by the current user, the permissions of existing files are corrected as well
+// missing parent directories are created only accessible by the current user
+func WriteCacheFile(file string, data []byte) error {
+	err := os.MkdirAll(filepath.Dir(file), 0700)
 	if err != nil {
 		return fmt.Errorf("failed to create data directory: %w", err)
 	}
 
-	err = os.WriteFile(filepath.Join(dataDir, fmt.Sprintf("recon-%s.json", providerName)), jsonData, 0644)
+	err = os.WriteFile(file, data, 0600)
 	if err != nil {
-		return fmt.Errorf("failed to write options: %w", err)
+		return err
 	}
 
-	return nil
+	return os.Chmod(file, 0600)
 }
 
 func LoadOptions(providerName string, maxAge float64) ([]Option, error) {
 	var optionsCache OptionsCache
 
-	jsonData, err := os.ReadFile(filepath.Join(dataDir, fmt.Sprintf("recon-%s.json", providerName)))
+	file := filepath.Join(dataDir, fmt.Sprintf("recon-%s.json", providerName))
+	jsonData, err := os.ReadFile(file)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read options: %w", err)
 	}
@@ -55,6 +77,13 @@ func LoadOptions(providerName string, maxAge float64) ([]Option, error) {
 		return nil, fmt.Errorf("failed to unmarshal options: %w", err)
 	}
 
+	if optionsCache.Version != cacheVersion {
+		// outdated caches may still contain credentials
+		if err = os.Remove(file); err != nil {
+			log.Warn().Err(err).Str("file", file).Msg("failed to remove outdated cache")
+		}
+		return nil, fmt.Errorf("cache version %d is outdated", optionsCache.Version)
+	}
 	if time.Since(optionsCache.CreatedAt).Seconds() > maxAge {
 		return nil, fmt.Errorf("cache is too old")
 	}
@@ -62,6 +91,42 @@ func LoadOptions(providerName string, maxAge float64) ([]Option, error) {
 	return optionsCache.Options, nil
 }
 
+// UpdateCachedOption replaces the option with the same id in the cache, the cache age is not modified
+func UpdateCachedOption(providerName string, option Option) error {
+	var optionsCache OptionsCache
+	file := filepath.Join(dataDir, fmt.Sprintf("recon-%s.json", providerName))
+
+	jsonData, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to read options: %w", err)
+	}
+
+	err = json.Unmarshal(jsonData, &optionsCache)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal options: %w", err)
+	}
+
+	for i, o := range optionsCache.Options {
+		if o.Id == option.Id {
+			optionsCache.Options[i] = option
+		}
+	}
+
+	jsonData, err = json.MarshalIndent(optionsCache, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal options: %w", err)
+	}
+
+	err = WriteCacheFile(file, jsonData)
+	if err != nil {
+		return fmt.Errorf("failed to write options: %w", err)
+	}
+
+	return nil
+}
+
 func OptionById(options []Option, id string) (*Option, error) {
 	for _, o := range options {
 		if o.Id == id {
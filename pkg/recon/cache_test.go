@@ -0,0 +1,98 @@
+package recon
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type secretModule struct {
+	Module
+}
+
+func (m secretModule) Name() string {
+	return "test"
+}
+
+func (m secretModule) ResolveSecret(option *Option, name string) (string, error) {
+	if name != "TEST_TOKEN" {
+		return "", fmt.Errorf("unknown secret %s", name)
+	}
+	return "token-" + option.Id, nil
+}
+
+func TestSaveOptionsPermissions(t *testing.T) {
+	dataDir = filepath.Join(t.TempDir(), "fuzzmux")
+	file := filepath.Join(dataDir, "recon-test.json")
+
+	// existing files are restricted as well
+	require.NoError(t, os.MkdirAll(dataDir, 0755))
+	require.NoError(t, os.WriteFile(file, []byte("{}"), 0644))
+
+	require.NoError(t, SaveOptions("test", []Option{{Id: "a", Secrets: []string{"TEST_TOKEN"}}}))
+	info, err := os.Stat(file)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	options, err := LoadOptions("test", 60)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"TEST_TOKEN"}, options[0].Secrets)
+}
+
+func TestLoadOptionsOutdatedVersion(t *testing.T) {
+	dataDir = t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dataDir, "recon-test.json"), []byte(`{"ProviderName":"test","Options":[],"CreatedAt":"2999-01-01T00:00:00Z"}`), 0600))
+
+	_, err := LoadOptions("test", 60)
+	assert.Error(t, err)
+
+	// outdated caches are removed, as they may contain credentials
+	_, err = os.Stat(filepath.Join(dataDir, "recon-test.json"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+type moduleSecretModule struct {
+	secretModule
+}
+
+func (m moduleSecretModule) Type() string {
+	return "test"
+}
+
+func (m moduleSecretModule) ModuleSecrets() []string {
+	return []string{"TEST_TOKEN"}
+}
+
+func TestSecretOptionById(t *testing.T) {
+	options := []Option{{Id: "a", Secrets: []string{"TEST_TOKEN"}}}
+
+	option, err := SecretOptionById(secretModule{}, options, "a")
+	require.NoError(t, err)
+	assert.Equal(t, "a", option.Id)
+	_, err = SecretOptionById(secretModule{}, options, "b")
+	assert.Error(t, err)
+
+	// modules with shared secrets resolve options that are not cached
+	option, err = SecretOptionById(moduleSecretModule{}, options, "b")
+	require.NoError(t, err)
+	env, err := SecretEnv(moduleSecretModule{}, option)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"TEST_TOKEN=token-b"}, env)
+}
+
+func TestSecretEnv(t *testing.T) {
+	env, err := SecretEnv(secretModule{}, &Option{Id: "a", Secrets: []string{"TEST_TOKEN"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"TEST_TOKEN=token-a"}, env)
+
+	env, err = SecretEnv(secretModule{}, &Option{Id: "a"})
+	require.NoError(t, err)
+	assert.Empty(t, env)
+
+	_, err = SecretEnv(secretModule{}, &Option{Id: "a", Secrets: []string{"OTHER"}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,151 @@
+package confluence
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+type Page struct {
+	ID        string `json:"id"`
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Space     Space  `json:"space"`
+	Ancestors []struct {
+		ID    string `json:"id"`
+		Title string `json:"title"`
+	} `json:"ancestors"`
+	Metadata struct {
+		Labels struct {
+			Results []struct {
+				Name string `json:"name"`
+			} `json:"results"`
+		} `json:"labels"`
+	} `json:"metadata"`
+	Version struct {
+		Number int    `json:"number"`
+		When   string `json:"when"`
+		By     struct {
+			DisplayName string `json:"displayName"`
+		} `json:"by"`
+	} `json:"version"`
+	Body struct {
+		Storage struct {
+			Value string `json:"value"`
+		} `json:"storage"`
+	} `json:"body"`
+	Links struct {
+		WebUI string `json:"webui"`
+	} `json:"_links"`
+}
+
+type Space struct {
+	Key  string `json:"key"`
+	Name string `json:"name"`
+}
+
+type searchResponse struct {
+	Results []Page `json:"results"`
+	Start   int    `json:"start"`
+	Limit   int    `json:"limit"`
+	Size    int    `json:"size"`
+	Links   struct {
+		Next string `json:"next"`
+	} `json:"_links"`
+}
+
+// Client is a simple HTTP client to interact with the confluence REST API
+type Client struct {
+	BaseURL    string
+	HTTPClient *http.Client
+}
+
+// NewClient initializes a new API client, basic authentication is used if a username is set
+func NewClient(baseURL string, bearerToken string, username string, apiToken string) *Client {
+	return &Client{
+		BaseURL: baseURL,
+		HTTPClient: &http.Client{
+			Transport: &authTransport{
+				BearerToken: bearerToken,
+				Username:    username,
+				APIToken:    apiToken,
+			},
+		},
+	}
+}
+
+// SearchPages queries content by CQL, the results are paginated by following the next link until maxResults is reached
+func (c *Client) SearchPages(cql string, maxResults int) ([]Page, error) {
+	var result []Page
+
+	query := url.Values{}
+	query.Set("cql", cql)
+	query.Set("limit", strconv.Itoa(min(maxResults, 100)))
+	query.Set("expand", "space,ancestors,metadata.labels,version")
+	next := "/rest/api/content/search?" + query.Encode()
+
+	for next != "" && len(result) < maxResults {
+		var response searchResponse
+		if err := c.get(next, &response); err != nil {
+			return nil, err
+		}
+
+		result = append(result, response.Results...)
+		next = response.Links.Next
+	}
+
+	if len(result) > maxResults {
+		result = result[:maxResults]
+	}
+	return result, nil
+}
+
+// GetPage fetches a page including the body in storage format
+func (c *Client) GetPage(id string) (Page, error) {
+	var page Page
+	err := c.get(fmt.Sprintf("/rest/api/content/%s?expand=body.storage,space", url.PathEscape(id)), &page)
+	return page, err
+}
+
+func (c *Client) get(path string, v interface{}) error {
+	req, err := http.NewRequest("GET", c.BaseURL+path, nil)
+	if err != nil {
+		return fmt.Errorf("could not create request: %v", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	if err = json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode response: %v", err)
+	}
+
+	return nil
+}
+
+// authTransport adds the bearer token (Confluence Server / Data Center) or basic authentication (Confluence Cloud)
+type authTransport struct {
+	BearerToken string
+	Username    string
+	APIToken    string
+}
+
+func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.Username != "" {
+		req.SetBasicAuth(t.Username, t.APIToken)
+	} else if t.BearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+t.BearerToken)
+	}
+
+	return http.DefaultTransport.RoundTrip(req)
+}
@@ -0,0 +1,228 @@
+package confluence
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/PhilippHeuer/fuzzmux/pkg/recon"
+	"github.com/PhilippHeuer/fuzzmux/pkg/types"
+	"github.com/PhilippHeuer/fuzzmux/pkg/util"
+)
+
+const moduleType = "confluence"
+
+// defaultCql is used if no spaces, labels or cql are configured, it queries the pages recently modified by the user
+const defaultCql = "contributor = currentUser()"
+
+// excerptLength is the maximum length of the page excerpt shown in the preview
+const excerptLength = 2000
+
+type Module struct {
+	Config ModuleConfig
+}
+
+type ModuleConfig struct {
+	// Name is used to override the default module name
+	Name string `yaml:"name,omitempty"`
+
+	// DisplayName is a template string to render a custom display name
+	DisplayName string `yaml:"display-name"`
+
+	// StartDirectory is a template string that defines the start directory
+	StartDirectory string `yaml:"start-directory"`
+
+	// Host is the Confluence url, e.g. https://example.atlassian.net/wiki
+	Host string `yaml:"host"`
+
+	// BearerToken is the personal access token used to authenticate against Confluence Server / Data Center
+	BearerToken string `yaml:"bearer-token,omitempty"`
+
+	// Username is the email (Confluence Cloud) or username used for basic authentication
+	Username string `yaml:"username"`
+
+	// APIToken is the api token (Confluence Cloud) or password used for basic authentication
+	APIToken string `yaml:"api-token,omitempty"`
+
+	// AttributeMapping is a list of field mappings used to map additional attributes to context fields
+	AttributeMapping []types.FieldMapping `yaml:"attribute-mapping"`
+
+	// Spaces limits the search to the given space keys
+	Spaces []string `yaml:"spaces"`
+
+	// Labels limits the search to pages with at least one of the labels
+	Labels []string `yaml:"labels"`
+
+	// Cql is the Confluence Query Language query to filter pages, defaults to the pages modified by the user if no spaces or labels are set
+	Cql string `yaml:"cql"`
+
+	// MaxResults is the maximum number of pages, defaults to 500
+	MaxResults int `yaml:"max-results"`
+}
+
+func (c *ModuleConfig) DecodeConfig() error {
+	err := util.ResolveCredentialValues(&c.Host, &c.BearerToken, &c.Username, &c.APIToken)
+	c.Host = strings.TrimSuffix(c.Host, "/")
+	return err
+}
+
+func (p Module) Name() string {
+	if p.Config.Name != "" {
+		return p.Config.Name
+	}
+	return moduleType
+}
+
+func (p Module) Type() string {
+	return moduleType
+}
+
+func (p Module) Options() ([]recon.Option, error) {
+	if err := p.Config.DecodeConfig(); err != nil {
+		return nil, fmt.Errorf("failed to resolve credentials: %w", err)
+	}
+	var result []recon.Option
+
+	client := NewClient(p.Config.Host, p.Config.BearerToken, p.Config.Username, p.Config.APIToken)
+	pages, err := client.SearchPages(p.cql(), p.Config.MaxResults)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query confluence: %w", err)
+	}
+
+	for _, page := range pages {
+		var ancestors []string
+		for _, a := range page.Ancestors {
+			ancestors = append(ancestors, a.Title)
+		}
+		var labels []string
+		for _, l := range page.Metadata.Labels.Results {
+			labels = append(labels, l.Name)
+		}
+
+		data := map[string]interface{}{
+			"id":            page.ID,
+			"type":          page.Type,
+			"title":         page.Title,
+			"space":         page.Space.Key,
+			"spaceName":     page.Space.Name,
+			"ancestors":     strings.Join(ancestors, " / "),
+			"labels":        labels,
+			"version":       page.Version.Number,
+			"lastUpdated":   page.Version.When,
+			"lastUpdatedBy": page.Version.By.DisplayName,
+		}
+		attributes := recon.AttributeMapping(data, p.Config.AttributeMapping)
+
+		opt := recon.Option{
+			ProviderName:   p.Name(),
+			ProviderType:   p.Type(),
+			Id:             fmt.Sprintf("%s/%s", page.Space.Key, page.ID),
+			DisplayName:    fmt.Sprintf("[%s] %s", page.Space.Key, page.Title),
+			Name:           page.Title,
+			Description:    strings.Join(append(ancestors, page.Title), " / "),
+			Web:            p.Config.Host + page.Links.WebUI,
+			StartDirectory: "~",
+			Tags:           []string{"confluence", page.Type},
+			Context:        attributes,
+			ModuleContext: map[string]string{
+				"confluencePageId": page.ID,
+			},
+		}
+		opt.ProcessUserTemplateStrings(p.Config.DisplayName, p.Config.StartDirectory)
+		result = append(result, opt)
+	}
+
+	return result, nil
+}
+
+func (p Module) OptionsOrCache(maxAge float64) ([]recon.Option, error) {
+	return recon.OptionsOrCache(p, maxAge)
+}
+
+// SelectOption fetches the page body, the excerpt is shown in the preview
+func (p Module) SelectOption(option *recon.Option) error {
+	err := option.CreateStartDirectoryIfMissing()
+	if err != nil {
+		return err
+	}
+
+	if err := p.Config.DecodeConfig(); err != nil {
+		return fmt.Errorf("failed to resolve credentials: %w", err)
+	}
+	client := NewClient(p.Config.Host, p.Config.BearerToken, p.Config.Username, p.Config.APIToken)
+	page, err := client.GetPage(option.ModuleContext["confluencePageId"])
+	if err != nil {
+		return fmt.Errorf("failed to query page %s: %w", option.ModuleContext["confluencePageId"], err)
+	}
+	if excerpt := StorageToText(page.Body.Storage.Value, excerptLength); excerpt != "" {
+		if option.Context == nil {
+			option.Context = make(map[string]string)
+		}
+		option.Context["excerpt"] = excerpt
+	}
+
+	return nil
+}
+
+// Preview shows the space, labels and the excerpt of the page
+func (p Module) Preview(option *recon.Option) string {
+	var builder strings.Builder
+	recon.PreviewField(&builder, "Space", option.Context["spaceName"])
+	recon.PreviewField(&builder, "Path", option.Description)
+	recon.PreviewField(&builder, "Labels", option.Context["labels"])
+	if option.Context["lastUpdated"] != "" {
+		builder.WriteString(fmt.Sprintf("Last Updated: %s by %s\n", option.Context["lastUpdated"], option.Context["lastUpdatedBy"]))
+	}
+	if option.Context["excerpt"] != "" {
+		builder.WriteString("\n" + option.Context["excerpt"] + "\n")
+	}
+	return builder.String()
+}
+
+func (p Module) Columns() []recon.Column {
+	return append(recon.DefaultColumns(),
+		recon.Column{Key: "space", Name: "Space"},
+		recon.Column{Key: "lastUpdated", Name: "Last Updated"},
+	)
+}
+
+// cql combines the spaces, labels and the user query
+func (p Module) cql() string {
+	conditions := []string{"type = page"}
+	if len(p.Config.Spaces) > 0 {
+		conditions = append(conditions, fmt.Sprintf("space in (%s)", quoteList(p.Config.Spaces)))
+	}
+	if len(p.Config.Labels) > 0 {
+		conditions = append(conditions, fmt.Sprintf("label in (%s)", quoteList(p.Config.Labels)))
+	}
+
+	// the order by clause must be at the end of the query
+	query, order := p.Config.Cql, "order by lastmodified desc"
+	if idx := strings.Index(strings.ToLower(query), "order by"); idx >= 0 {
+		query, order = strings.TrimSpace(query[:idx]), strings.TrimSpace(query[idx:])
+	}
+	if query != "" {
+		conditions = append(conditions, "("+query+")")
+	} else if len(conditions) == 1 {
+		conditions = append(conditions, defaultCql)
+	}
+
+	return strings.Join(conditions, " AND ") + " " + order
+}
+
+func quoteList(values []string) string {
+	var quoted []string
+	for _, v := range values {
+		quoted = append(quoted, fmt.Sprintf("%q", v))
+	}
+	return strings.Join(quoted, ", ")
+}
+
+func NewModule(config ModuleConfig) Module {
+	if config.MaxResults <= 0 {
+		config.MaxResults = 500
+	}
+
+	return Module{
+		Config: config,
+	}
+}
@@ -0,0 +1,98 @@
+package confluence
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/PhilippHeuer/fuzzmux/pkg/recon"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testPage(id string, title string) map[string]interface{} {
+	return map[string]interface{}{
+		"id":        id,
+		"type":      "page",
+		"title":     title,
+		"space":     map[string]string{"key": "DEV", "name": "Development"},
+		"ancestors": []map[string]string{{"id": "1", "title": "Home"}, {"id": "2", "title": "Guides"}},
+		"metadata":  map[string]interface{}{"labels": map[string]interface{}{"results": []map[string]string{{"name": "howto"}, {"name": "backend"}}}},
+		"version":   map[string]interface{}{"number": 3, "when": "2026-10-01T10:00:00.000Z", "by": map[string]string{"displayName": "Jane Doe"}},
+		"_links":    map[string]string{"webui": "/spaces/DEV/pages/" + id},
+	}
+}
+
+func TestOptions(t *testing.T) {
+	var queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, ok := r.BasicAuth()
+		if !ok || username != "jane@example.com" || password != "token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/wiki/rest/api/content/search":
+			queries = append(queries, r.URL.Query().Get("cql"))
+			if r.URL.Query().Get("cursor") == "" {
+				_ = json.NewEncoder(w).Encode(map[string]interface{}{
+					"results": []interface{}{testPage("10", "Setup")},
+					"_links":  map[string]string{"next": "/rest/api/content/search?cql=next&cursor=abc"},
+				})
+			} else {
+				_ = json.NewEncoder(w).Encode(map[string]interface{}{"results": []interface{}{testPage("11", "Deploy")}})
+			}
+		case "/wiki/rest/api/content/10":
+			page := testPage("10", "Setup")
+			page["body"] = map[string]interface{}{"storage": map[string]string{"value": "<h1>Setup</h1><p>Install the <strong>cli</strong>.</p>"}}
+			_ = json.NewEncoder(w).Encode(page)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	module := NewModule(ModuleConfig{
+		Host:     server.URL + "/wiki/",
+		Username: "jane@example.com",
+		APIToken: "token",
+		Spaces:   []string{"DEV"},
+		Cql:      "label = howto order by title",
+	})
+	options, err := module.Options()
+	require.NoError(t, err)
+	require.Len(t, options, 2)
+	assert.Equal(t, `type = page AND space in ("DEV") AND (label = howto) order by title`, queries[0])
+
+	opt := options[0]
+	assert.Equal(t, "DEV/10", opt.Id)
+	assert.Equal(t, "[DEV] Setup", opt.DisplayName)
+	assert.Equal(t, "Home / Guides / Setup", opt.Description)
+	assert.Equal(t, server.URL+"/wiki/spaces/DEV/pages/10", opt.Web)
+	assert.Equal(t, "Home / Guides", opt.Context["ancestors"])
+	assert.Equal(t, "howto, backend", opt.Context["labels"])
+	assert.Equal(t, "Jane Doe", opt.Context["lastUpdatedBy"])
+
+	// the preview contains the page excerpt
+	require.NoError(t, module.SelectOption(&opt))
+	assert.Equal(t, "# Setup\n\nInstall the cli.", opt.Context["excerpt"])
+	assert.Contains(t, recon.RenderPreview(module, &opt), "\nInstall the cli.\n")
+}
+
+func TestCql(t *testing.T) {
+	assert.Equal(t, "type = page AND contributor = currentUser() order by lastmodified desc", NewModule(ModuleConfig{}).cql())
+	assert.Equal(t, `type = page AND label in ("a", "b") order by lastmodified desc`, NewModule(ModuleConfig{Labels: []string{"a", "b"}}).cql())
+}
+
+func TestStorageToText(t *testing.T) {
+	storage := `<h2>Usage</h2><p>Run the   following:</p>
+<ac:structured-macro ac:name="code"><ac:parameter ac:name="language">bash</ac:parameter><ac:plain-text-body><![CDATA[make build]]></ac:plain-text-body></ac:structured-macro>
+<ul><li>first</li><li>second &amp; third</li></ul>
+<table><tr><th>Key</th><th>Value</th></tr><tr><td>a</td><td>1</td></tr></table>
+<p><ac:image><ri:attachment ri:filename="diagram.png" /></ac:image></p>`
+
+	assert.Equal(t, "## Usage\n\nRun the following:\n\nmake build\n\n- first\n- second & third\n\n| Key | Value\n| a | 1", StorageToText(storage, 0))
+	assert.Equal(t, "## Usa…", StorageToText(storage, 6))
+}
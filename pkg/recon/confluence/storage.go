@@ -0,0 +1,116 @@
+package confluence
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+)
+
+var (
+	whitespaceRegex = regexp.MustCompile(`[ \t\r\n]+`)
+	newlinesRegex   = regexp.MustCompile(`\n{3,}`)
+)
+
+// blockElements start on a new line
+var blockElements = map[string]bool{
+	"p": true, "div": true, "br": true, "hr": true, "table": true, "tr": true, "ul": true, "ol": true,
+	"pre": true, "blockquote": true, "ac:structured-macro": true, "ac:task": true,
+}
+
+// skippedElements contain metadata, e.g. macro parameters or image references
+var skippedElements = map[string]bool{
+	"ac:parameter": true, "ac:image": true, "ri:attachment": true, "ri:user": true, "style": true, "script": true,
+}
+
+var headingPrefix = map[string]string{
+	"h1": "# ", "h2": "## ", "h3": "### ", "h4": "#### ", "h5": "##### ", "h6": "###### ",
+}
+
+// StorageToText converts the confluence storage format (xhtml with macros) to markdown-like text, truncated to maxLength runes
+func StorageToText(storage string, maxLength int) string {
+	var builder strings.Builder
+	tokenizer := html.NewTokenizer(strings.NewReader(storage))
+	skipDepth := 0
+
+	newline := func() {
+		if builder.Len() > 0 {
+			builder.WriteString("\n")
+		}
+	}
+
+loop:
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			break loop
+		case html.StartTagToken, html.SelfClosingTagToken:
+			token := tokenizer.Token()
+			name := token.Data
+			if skippedElements[name] {
+				if token.Type == html.StartTagToken {
+					skipDepth++
+				}
+				continue
+			}
+			if skipDepth > 0 {
+				continue
+			}
+
+			if prefix, ok := headingPrefix[name]; ok {
+				newline()
+				builder.WriteString("\n" + prefix)
+			} else if name == "li" {
+				newline()
+				builder.WriteString("- ")
+			} else if name == "td" || name == "th" {
+				builder.WriteString(" | ")
+			} else if blockElements[name] {
+				newline()
+			}
+		case html.EndTagToken:
+			token := tokenizer.Token()
+			name := token.Data
+			if skippedElements[name] {
+				if skipDepth > 0 {
+					skipDepth--
+				}
+				continue
+			}
+			if skipDepth > 0 {
+				continue
+			}
+			if _, ok := headingPrefix[name]; ok || name == "p" || name == "table" {
+				newline()
+			}
+		case html.TextToken:
+			if skipDepth > 0 {
+				continue
+			}
+			builder.WriteString(whitespaceRegex.ReplaceAllString(string(tokenizer.Text()), " "))
+		case html.CommentToken:
+			// code macros contain their content as CDATA, which is tokenized as comment
+			data := string(tokenizer.Text())
+			if skipDepth == 0 && strings.HasPrefix(data, "[CDATA[") {
+				newline()
+				builder.WriteString(strings.TrimSuffix(strings.TrimPrefix(data, "[CDATA["), "]]"))
+				newline()
+			}
+		}
+	}
+
+	// trim lines and remove repeated empty lines
+	lines := strings.Split(builder.String(), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+	text := strings.TrimSpace(newlinesRegex.ReplaceAllString(strings.Join(lines, "\n"), "\n\n"))
+
+	if maxLength > 0 && utf8.RuneCountInString(text) > maxLength {
+		runes := []rune(text)
+		text = strings.TrimSpace(string(runes[:maxLength])) + "…"
+	}
+
+	return text
+}
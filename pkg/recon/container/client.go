@@ -0,0 +1,116 @@
+package container
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+type Container struct {
+	ID      string            `json:"Id"`
+	Names   []string          `json:"Names"`
+	Image   string            `json:"Image"`
+	ImageID string            `json:"ImageID"`
+	Command string            `json:"Command"`
+	Created int64             `json:"Created"`
+	State   string            `json:"State"`
+	Status  string            `json:"Status"`
+	Ports   []Port            `json:"Ports"`
+	Labels  map[string]string `json:"Labels"`
+}
+
+type Port struct {
+	IP          string `json:"IP"`
+	PrivatePort int    `json:"PrivatePort"`
+	PublicPort  int    `json:"PublicPort"`
+	Type        string `json:"Type"`
+}
+
+// Client is a minimal HTTP client for the Docker-compatible engine API, which is also served by Podman
+type Client struct {
+	BaseURL    string
+	HTTPClient *http.Client
+}
+
+// NewClient initializes a new API client for the given socket path
+func NewClient(socketPath string) *Client {
+	return &Client{
+		BaseURL: "http://localhost",
+		HTTPClient: &http.Client{
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					var d net.Dialer
+					return d.DialContext(ctx, "unix", socketPath)
+				},
+			},
+		},
+	}
+}
+
+// ListContainers fetches the containers, including stopped ones if all is set
+func (c *Client) ListContainers(all bool) ([]Container, error) {
+	reqURL, err := url.Parse(c.BaseURL + "/containers/json")
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL: %v", err)
+	}
+	if all {
+		query := reqURL.Query()
+		query.Set("all", "true")
+		reqURL.RawQuery = query.Encode()
+	}
+
+	req, err := http.NewRequest("GET", reqURL.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not create request: %v", err)
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	var containers []Container
+	if err = json.NewDecoder(resp.Body).Decode(&containers); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %v", err)
+	}
+
+	return containers, nil
+}
+
+// Name returns the primary container name without the leading slash
+func (c Container) Name() string {
+	if len(c.Names) == 0 {
+		return c.ID
+	}
+	return strings.TrimPrefix(c.Names[0], "/")
+}
+
+// ShortID returns the abbreviated container id, as shown by the docker cli
+func (c Container) ShortID() string {
+	if len(c.ID) > 12 {
+		return c.ID[:12]
+	}
+	return c.ID
+}
+
+// PortsToString renders the published ports, e.g. "0.0.0.0:8080->80/tcp"
+func (c Container) PortsToString() string {
+	var ports []string
+	for _, p := range c.Ports {
+		if p.PublicPort > 0 {
+			ports = append(ports, fmt.Sprintf("%s:%d->%d/%s", p.IP, p.PublicPort, p.PrivatePort, p.Type))
+		} else {
+			ports = append(ports, fmt.Sprintf("%d/%s", p.PrivatePort, p.Type))
+		}
+	}
+	return strings.Join(ports, ", ")
+}
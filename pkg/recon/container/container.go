@@ -0,0 +1,169 @@
+package container
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/PhilippHeuer/fuzzmux/pkg/recon"
+	"github.com/rs/zerolog/log"
+)
+
+const moduleType = "container"
+
+const (
+	composeProjectLabel = "com.docker.compose.project"
+	composeServiceLabel = "com.docker.compose.service"
+)
+
+type Module struct {
+	Config ModuleConfig
+}
+
+type ModuleConfig struct {
+	// Name is used to override the default module name
+	Name string `yaml:"name,omitempty"`
+
+	// DisplayName is a template string to render a custom display name
+	DisplayName string `yaml:"display-name"`
+
+	// StartDirectory is a template string that defines the start directory
+	StartDirectory string `yaml:"start-directory"`
+
+	// Runtime is the container runtime cli used in the layout, e.g. "docker" or "podman" (default: docker)
+	Runtime string `yaml:"runtime"`
+
+	// Socket is the path to the api socket, auto-detected based on the runtime if not set
+	Socket string `yaml:"socket"`
+
+	// All includes stopped containers (default: false)
+	All bool `yaml:"all"`
+
+	// Tags that apply to all containers
+	Tags []string `yaml:"tags"`
+}
+
+func (p Module) Name() string {
+	if p.Config.Name != "" {
+		return p.Config.Name
+	}
+	return moduleType
+}
+
+func (p Module) Type() string {
+	return moduleType
+}
+
+func (p Module) Options() ([]recon.Option, error) {
+	var result []recon.Option
+
+	// query
+	log.Debug().Str("socket", p.Config.Socket).Str("runtime", p.Config.Runtime).Msg("querying containers")
+	client := NewClient(p.Config.Socket)
+	containers, err := client.ListContainers(p.Config.All)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list containers: %w", err)
+	}
+
+	for _, c := range containers {
+		name := c.Name()
+		composeProject := c.Labels[composeProjectLabel]
+
+		displayName := fmt.Sprintf("%s [%s]", name, c.Image)
+		if composeProject != "" {
+			displayName = fmt.Sprintf("%s [%s] @ %s", name, c.Image, composeProject)
+		}
+
+		context := map[string]string{
+			"runtime":        p.Config.Runtime,
+			"containerId":    c.ID,
+			"containerName":  name,
+			"image":          c.Image,
+			"command":        c.Command,
+			"state":          c.State,
+			"status":         c.Status,
+			"ports":          c.PortsToString(),
+			"composeProject": composeProject,
+			"composeService": c.Labels[composeServiceLabel],
+			"createdAt":      time.Unix(c.Created, 0).UTC().Format(time.RFC3339),
+		}
+		for key, value := range c.Labels {
+			context["label."+key] = value
+		}
+
+		tags := append([]string{"container", p.Config.Runtime}, p.Config.Tags...)
+		if c.State != "" {
+			tags = append(tags, c.State)
+		}
+
+		opt := recon.Option{
+			ProviderName:   p.Name(),
+			ProviderType:   p.Type(),
+			Id:             c.ID,
+			DisplayName:    displayName,
+			Name:           name,
+			StartDirectory: "~",
+			Tags:           tags,
+			Context:        context,
+		}
+		opt.ProcessUserTemplateStrings(p.Config.DisplayName, p.Config.StartDirectory)
+		result = append(result, opt)
+	}
+
+	return result, nil
+}
+
+func (p Module) OptionsOrCache(maxAge float64) ([]recon.Option, error) {
+	return recon.OptionsOrCache(p, maxAge)
+}
+
+func (p Module) SelectOption(option *recon.Option) error {
+	err := option.CreateStartDirectoryIfMissing()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (p Module) Columns() []recon.Column {
+	return append(recon.DefaultColumns(),
+		recon.Column{Key: "image", Name: "Image"},
+		recon.Column{Key: "state", Name: "State"},
+		recon.Column{Key: "composeProject", Name: "Compose Project"},
+	)
+}
+
+func NewModule(config ModuleConfig) Module {
+	if config.Runtime == "" {
+		config.Runtime = "docker"
+	}
+	if config.Socket == "" {
+		config.Socket = defaultSocket(config.Runtime)
+	}
+	config.Socket = strings.TrimPrefix(config.Socket, "unix://")
+
+	return Module{
+		Config: config,
+	}
+}
+
+// defaultSocket returns the default api socket for the given runtime
+func defaultSocket(runtime string) string {
+	if runtime == "podman" {
+		if host := os.Getenv("CONTAINER_HOST"); strings.HasPrefix(host, "unix://") {
+			return host
+		}
+		if runtimeDir := os.Getenv("XDG_RUNTIME_DIR"); runtimeDir != "" {
+			return filepath.Join(runtimeDir, "podman", "podman.sock")
+		}
+		return "/run/podman/podman.sock"
+	}
+
+	if host := os.Getenv("DOCKER_HOST"); strings.HasPrefix(host, "unix://") {
+		return host
+	}
+	return "/var/run/docker.sock"
+}
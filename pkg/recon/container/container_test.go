@@ -0,0 +1,82 @@
+package container
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const containerListResponse = `[
+  {
+    "Id": "8dfafdbc3a40e1a5f1e9d2b3c4d5e6f708192a3b4c5d6e7f8091a2b3c4d5e6f7",
+    "Names": ["/example-web-1"],
+    "Image": "nginx:latest",
+    "Command": "nginx -g 'daemon off;'",
+    "Created": 1700000000,
+    "State": "running",
+    "Status": "Up 2 hours",
+    "Ports": [{"IP": "0.0.0.0", "PrivatePort": 80, "PublicPort": 8080, "Type": "tcp"}],
+    "Labels": {"com.docker.compose.project": "example", "com.docker.compose.service": "web"}
+  }
+]`
+
+func fakeSocketServer(t *testing.T, handler http.HandlerFunc) string {
+	socketPath := filepath.Join(t.TempDir(), "docker.sock")
+	listener, err := net.Listen("unix", socketPath)
+	require.NoError(t, err)
+
+	server := httptest.NewUnstartedServer(handler)
+	server.Listener = listener
+	server.Start()
+	t.Cleanup(server.Close)
+
+	return socketPath
+}
+
+func TestListContainers(t *testing.T) {
+	var allParam string
+	socketPath := fakeSocketServer(t, func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/containers/json", r.URL.Path)
+		allParam = r.URL.Query().Get("all")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(containerListResponse))
+	})
+
+	// query
+	containerModule := NewModule(ModuleConfig{
+		Runtime: "podman",
+		Socket:  "unix://" + socketPath,
+		All:     true,
+	})
+	options, err := containerModule.Options()
+	require.NoError(t, err)
+
+	// verify
+	require.Equal(t, "true", allParam)
+	require.Len(t, options, 1)
+	require.Equal(t, "example-web-1", options[0].Name)
+	require.Equal(t, "example-web-1 [nginx:latest] @ example", options[0].DisplayName)
+	require.Equal(t, "podman", options[0].Context["runtime"])
+	require.Equal(t, "nginx:latest", options[0].Context["image"])
+	require.Equal(t, "0.0.0.0:8080->80/tcp", options[0].Context["ports"])
+	require.Equal(t, "example", options[0].Context["composeProject"])
+	require.Equal(t, "web", options[0].Context["label.com.docker.compose.service"])
+	require.Contains(t, options[0].Tags, "running")
+}
+
+func TestListContainersError(t *testing.T) {
+	socketPath := fakeSocketServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	// query
+	containerModule := NewModule(ModuleConfig{
+		Socket: socketPath,
+	})
+	_, err := containerModule.Options()
+	require.Error(t, err)
+}
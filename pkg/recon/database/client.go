@@ -0,0 +1,110 @@
+package database
+
+import (
+	"fmt"
+	"net/url"
+)
+
+const (
+	ClientPSQL  = "psql"
+	ClientMySQL = "mysql"
+	ClientUSQL  = "usql"
+)
+
+// DefaultClient returns the native client of the driver, usql is used for all other drivers
+func (c Connection) DefaultClient() string {
+	switch c.Driver {
+	case driverPostgres:
+		return ClientPSQL
+	case driverMySQL:
+		return ClientMySQL
+	}
+	return ClientUSQL
+}
+
+// ClientCommand returns the arguments and environment variables to start the client, credentials are only passed using environment variables
+func (c Connection) ClientCommand(client string) ([]string, []string, error) {
+	var env []string
+	setEnv := func(key, value string) {
+		if value != "" {
+			env = append(env, key+"="+value)
+		}
+	}
+
+	switch client {
+	case ClientPSQL:
+		if c.Driver != driverPostgres {
+			return nil, nil, fmt.Errorf("psql does not support %s connections", c.Driver)
+		}
+
+		// psql reads the password from the service file
+		if c.Service != "" {
+			setEnv("PGSERVICE", c.Service)
+			return []string{"psql"}, env, nil
+		}
+		setEnv("PGHOST", c.Host)
+		setEnv("PGPORT", c.Port)
+		setEnv("PGDATABASE", c.Database)
+		setEnv("PGUSER", c.User)
+		setEnv("PGPASSWORD", c.Password)
+		return []string{"psql"}, env, nil
+	case ClientMySQL:
+		if c.Driver != driverMySQL {
+			return nil, nil, fmt.Errorf("mysql does not support %s connections", c.Driver)
+		}
+
+		// mysql reads the password from the client group of the my.cnf file
+		if c.Source == sourceMyCnf {
+			args := []string{"mysql"}
+			if c.Group != "" {
+				args = append(args, "--defaults-group-suffix="+c.Group)
+			}
+			return args, env, nil
+		}
+		args := []string{"mysql"}
+		if c.Host != "" {
+			args = append(args, "--host="+c.Host)
+		}
+		if c.Port != "" {
+			args = append(args, "--port="+c.Port)
+		}
+		if c.User != "" {
+			args = append(args, "--user="+c.User)
+		}
+		setEnv("MYSQL_PWD", c.Password)
+		if c.Database != "" {
+			args = append(args, c.Database)
+		}
+		return args, env, nil
+	case ClientUSQL:
+		// the postgres driver of usql reads the password from the environment, other drivers use ~/.usqlpass
+		if c.Driver == driverPostgres {
+			setEnv("PGPASSWORD", c.Password)
+		}
+		return []string{"usql", c.dsn()}, env, nil
+	}
+
+	return nil, nil, fmt.Errorf("unsupported client: %s", client)
+}
+
+// dsn returns the usql dsn without the password
+func (c Connection) dsn() string {
+	if c.Service != "" {
+		return fmt.Sprintf("%s://?service=%s", c.Driver, url.QueryEscape(c.Service))
+	}
+	if c.Host == "" && c.Path != "" {
+		return fmt.Sprintf("%s:%s", c.Driver, c.Path)
+	}
+
+	u := url.URL{Scheme: c.Driver, Host: c.Host}
+	if c.Port != "" {
+		u.Host = c.Host + ":" + c.Port
+	}
+	if c.User != "" {
+		u.User = url.User(c.User)
+	}
+	if c.Database != "" {
+		u.Path = "/" + c.Database
+	}
+	return u.String()
+}
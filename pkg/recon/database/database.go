@@ -0,0 +1,273 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/PhilippHeuer/fuzzmux/pkg/recon"
+	"github.com/rs/zerolog/log"
+)
+
+const moduleType = "database"
+
+const (
+	sourcePGPass    = "pgpass"
+	sourcePGService = "pg-service"
+	sourceMyCnf     = "my-cnf"
+	sourceDBeaver   = "dbeaver"
+	sourceDataGrip  = "datagrip"
+)
+
+const (
+	driverPostgres = "postgres"
+	driverMySQL    = "mysql"
+)
+
+var DefaultPGPassPath = filepath.Join(os.Getenv("HOME"), ".pgpass")
+var DefaultPGServicePath = filepath.Join(os.Getenv("HOME"), ".pg_service.conf")
+var DefaultMyCnfPath = filepath.Join(os.Getenv("HOME"), ".my.cnf")
+var DefaultDBeaverPaths = []string{
+	filepath.Join(os.Getenv("HOME"), ".local", "share", "DBeaverData", "workspace6", "General", ".dbeaver", "data-sources.json"),
+	filepath.Join(os.Getenv("HOME"), "Library", "DBeaverData", "workspace6", "General", ".dbeaver", "data-sources.json"),
+}
+
+type Module struct {
+	Config ModuleConfig
+}
+
+type ModuleConfig struct {
+	// Name is used to override the default module name
+	Name string `yaml:"name,omitempty"`
+
+	// DisplayName is a template string to render a custom display name
+	DisplayName string `yaml:"display-name"`
+
+	// StartDirectory is a template string that defines the start directory
+	StartDirectory string `yaml:"start-directory"`
+
+	// Sources limits the imported sources: pgpass, pg-service, my-cnf, dbeaver, datagrip (default: all)
+	Sources []string `yaml:"sources"`
+
+	// PGPassFile is used in case your pgpass file is not in the default location (default: PGPASSFILE or ~/.pgpass)
+	PGPassFile string `yaml:"pgpass-file"`
+
+	// PGServiceFile is used in case your pg_service.conf is not in the default location (default: PGSERVICEFILE or ~/.pg_service.conf)
+	PGServiceFile string `yaml:"pg-service-file"`
+
+	// MyCnfFile is used in case your my.cnf is not in the default location
+	MyCnfFile string `yaml:"my-cnf-file"`
+
+	// DBeaverFiles is a list of DBeaver data-sources.json files, defaults to the default workspace
+	DBeaverFiles []string `yaml:"dbeaver-files"`
+
+	// DataGripFiles is a list of dataSources.xml files of DataGrip or IntelliJ projects (e.g. ~/src/app/.idea/dataSources.xml)
+	DataGripFiles []string `yaml:"datagrip-files"`
+}
+
+// Connection is a database connection read from one of the sources
+type Connection struct {
+	Source      string // Source is the file type the connection was read from
+	Key         string // Key identifies the connection within the source
+	Name        string
+	Driver      string // Driver is the usql scheme, e.g. postgres or mysql
+	Host        string
+	Port        string
+	Database    string
+	User        string
+	Password    string // Password is never stored in the option, it is read from the source when connecting
+	Service     string // Service is the name of the pg_service.conf section
+	Group       string // Group is the suffix of the my.cnf client group
+	Path        string // Path of file based databases, e.g. sqlite
+	Environment string // Environment is the connection type of DBeaver, e.g. dev or prod
+}
+
+func (p Module) Name() string {
+	if p.Config.Name != "" {
+		return p.Config.Name
+	}
+	return moduleType
+}
+
+func (p Module) Type() string {
+	return moduleType
+}
+
+func (p Module) Options() ([]recon.Option, error) {
+	var result []recon.Option
+
+	for _, conn := range p.connections() {
+		opt := recon.Option{
+			ProviderName:   p.Name(),
+			ProviderType:   p.Type(),
+			Id:             fmt.Sprintf("%s:%s", conn.Source, conn.Key),
+			DisplayName:    fmt.Sprintf("%s [%s]", conn.target(), conn.Source),
+			Name:           conn.Name,
+			StartDirectory: p.Config.StartDirectory,
+			Tags:           []string{"database", conn.Driver, conn.Source},
+			Context: map[string]string{
+				"driver":      conn.Driver,
+				"source":      conn.Source,
+				"host":        conn.Host,
+				"port":        conn.Port,
+				"database":    conn.Database,
+				"user":        conn.User,
+				"service":     conn.Service,
+				"path":        conn.Path,
+				"environment": conn.Environment,
+			},
+		}
+		if opt.Name == "" {
+			opt.Name = conn.target()
+		}
+		if conn.Name != "" {
+			opt.DisplayName = fmt.Sprintf("[%s] %s", conn.Name, opt.DisplayName)
+		}
+
+		// remove empty values, placeholders without a value are not replaced
+		for k, v := range opt.Context {
+			if v == "" {
+				delete(opt.Context, k)
+			}
+		}
+
+		opt.ProcessUserTemplateStrings(p.Config.DisplayName, p.Config.StartDirectory)
+		result = append(result, opt)
+	}
+
+	slices.SortFunc(result, func(a, b recon.Option) int {
+		return strings.Compare(a.DisplayName, b.DisplayName)
+	})
+	return result, nil
+}
+
+// Connection reads the connection of the option from its source, including the password
+func (p Module) Connection(option *recon.Option) (*Connection, error) {
+	for _, conn := range p.connections() {
+		if fmt.Sprintf("%s:%s", conn.Source, conn.Key) == option.Id {
+			return &conn, nil
+		}
+	}
+
+	return nil, fmt.Errorf("connection %s not found", option.Id)
+}
+
+// connections reads all enabled sources, missing files are skipped
+func (p Module) connections() []Connection {
+	type source struct {
+		name  string
+		files []string
+		parse func(string) ([]Connection, error)
+	}
+	sources := []source{
+		{name: sourcePGPass, files: []string{p.Config.PGPassFile}, parse: parsePGPass},
+		{name: sourcePGService, files: []string{p.Config.PGServiceFile}, parse: parsePGService},
+		{name: sourceMyCnf, files: []string{p.Config.MyCnfFile}, parse: parseMyCnf},
+		{name: sourceDBeaver, files: p.Config.DBeaverFiles, parse: parseDBeaver},
+		{name: sourceDataGrip, files: p.Config.DataGripFiles, parse: parseDataGrip},
+	}
+
+	var result []Connection
+	for _, s := range sources {
+		if len(p.Config.Sources) > 0 && !slices.Contains(p.Config.Sources, s.name) {
+			continue
+		}
+
+		for _, file := range s.files {
+			connections, err := s.parse(expandHome(file))
+			if errors.Is(err, os.ErrNotExist) {
+				log.Debug().Str("file", file).Str("source", s.name).Msg("database source file does not exist")
+				continue
+			} else if err != nil {
+				log.Warn().Err(err).Str("file", file).Str("source", s.name).Msg("failed to read database connections")
+				continue
+			}
+			result = append(result, connections...)
+		}
+	}
+
+	return result
+}
+
+func (p Module) OptionsOrCache(maxAge float64) ([]recon.Option, error) {
+	return recon.OptionsOrCache(p, maxAge)
+}
+
+func (p Module) SelectOption(option *recon.Option) error {
+	err := option.CreateStartDirectoryIfMissing()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (p Module) Columns() []recon.Column {
+	return append(recon.DefaultColumns(),
+		recon.Column{Key: "host", Name: "Host"},
+		recon.Column{Key: "user", Name: "User"},
+	)
+}
+
+// target returns the connection target without credentials, e.g. user @ host:5432/app
+func (c Connection) target() string {
+	if c.Host == "" && c.Path != "" {
+		return fmt.Sprintf("%s:%s", c.Driver, c.Path)
+	}
+	if c.Host == "" && c.Service != "" {
+		return "service=" + c.Service
+	}
+
+	target := c.Host
+	if target == "" {
+		target = "localhost"
+	}
+	if c.Port != "" {
+		target += ":" + c.Port
+	}
+	if c.User != "" {
+		target = c.User + " @ " + target
+	}
+	if c.Database != "" {
+		target += "/" + c.Database
+	}
+	return target
+}
+
+func expandHome(path string) string {
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		return filepath.Join(os.Getenv("HOME"), rest)
+	}
+	return path
+}
+
+func NewModule(config ModuleConfig) Module {
+	if config.PGPassFile == "" {
+		config.PGPassFile = os.Getenv("PGPASSFILE")
+	}
+	if config.PGPassFile == "" {
+		config.PGPassFile = DefaultPGPassPath
+	}
+	if config.PGServiceFile == "" {
+		config.PGServiceFile = os.Getenv("PGSERVICEFILE")
+	}
+	if config.PGServiceFile == "" {
+		config.PGServiceFile = DefaultPGServicePath
+	}
+	if config.MyCnfFile == "" {
+		config.MyCnfFile = DefaultMyCnfPath
+	}
+	if len(config.DBeaverFiles) == 0 {
+		config.DBeaverFiles = DefaultDBeaverPaths
+	}
+	if config.StartDirectory == "" {
+		config.StartDirectory = "~"
+	}
+
+	return Module{
+		Config: config,
+	}
+}
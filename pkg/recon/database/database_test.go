@@ -0,0 +1,201 @@
+package database
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, path string, content string) string {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o700))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+// encryptDBeaverCredentials encrypts the credentials the same way DBeaver does
+func encryptDBeaverCredentials(t *testing.T, plain string) string {
+	key, _ := hex.DecodeString(dbeaverKey)
+	block, err := aes.NewCipher(key)
+	require.NoError(t, err)
+
+	padding := aes.BlockSize - len(plain)%aes.BlockSize
+	data := []byte(plain)
+	for i := 0; i < padding; i++ {
+		data = append(data, byte(padding))
+	}
+	iv := []byte("0123456789abcdef")
+	encrypted := make([]byte, len(data))
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(encrypted, data)
+	return string(append(iv, encrypted...))
+}
+
+func testModule(t *testing.T) Module {
+	dir := t.TempDir()
+	dbeaverDir := filepath.Join(dir, "dbeaver")
+	writeFile(t, filepath.Join(dbeaverDir, "credentials-config.json"), encryptDBeaverCredentials(t, `{"postgres-jdbc-1":{"#connection":{"user":"app","password":"dbeaver-secret"}}}`))
+
+	return NewModule(ModuleConfig{
+		PGPassFile: writeFile(t, filepath.Join(dir, ".pgpass"), `# comment
+db.example.com:5432:app:app:pg-secret
+*:*:*:postgres:ignored
+localhost:*:*:admin:pa\:ss
+`),
+		PGServiceFile: writeFile(t, filepath.Join(dir, ".pg_service.conf"), `[prod]
+host=prod.example.com
+port=5433
+dbname=app
+user=readonly
+password=service-secret
+`),
+		MyCnfFile: writeFile(t, filepath.Join(dir, ".my.cnf"), `[client]
+user=root
+password="mysql-secret"
+
+[clientstaging]
+host=staging.example.com
+user=app
+database=shop
+
+[mysqldump]
+quick
+`),
+		DBeaverFiles: []string{writeFile(t, filepath.Join(dbeaverDir, "data-sources.json"), `{"connections":{
+"postgres-jdbc-1":{"provider":"postgresql","driver":"postgres-jdbc","name":"Local","configuration":{"host":"localhost","port":"5432","database":"postgres","type":"dev"}},
+"sqlite-1":{"provider":"sqlite","driver":"sqlite_jdbc","name":"Cache","configuration":{"database":"/tmp/cache.db","url":"jdbc:sqlite:/tmp/cache.db"}}
+}}`)},
+		DataGripFiles: []string{writeFile(t, filepath.Join(dir, "project", ".idea", "dataSources.xml"), `<?xml version="1.0" encoding="UTF-8"?>
+<project version="4">
+  <component name="DataSourceManagerImpl" format="xml" multifile-model="true">
+    <data-source source="LOCAL" name="orders@db" uuid="0a1b">
+      <driver-ref>mariadb</driver-ref>
+      <jdbc-url>jdbc:mariadb://db.example.com:3306/orders</jdbc-url>
+      <user-name>orders</user-name>
+    </data-source>
+  </component>
+</project>`)},
+	})
+}
+
+func TestOptions(t *testing.T) {
+	module := testModule(t)
+	options, err := module.Options()
+	require.NoError(t, err)
+
+	byId := make(map[string]int)
+	for i, o := range options {
+		byId[o.Id] = i
+		// passwords are never part of the option
+		assert.NotContains(t, o.DisplayName, "secret")
+		for _, v := range o.Context {
+			assert.NotContains(t, v, "secret")
+		}
+	}
+	assert.Len(t, options, 8)
+
+	pgpass := options[byId["pgpass:db.example.com:5432/app@app"]]
+	assert.Equal(t, map[string]string{"driver": "postgres", "source": "pgpass", "host": "db.example.com", "port": "5432", "database": "app", "user": "app"}, pgpass.Context)
+	assert.Contains(t, pgpass.Tags, "postgres")
+
+	service := options[byId["pg-service:prod"]]
+	assert.Equal(t, "[prod] readonly @ prod.example.com:5433/app [pg-service]", service.DisplayName)
+
+	mycnf := options[byId["my-cnf:clientstaging"]]
+	assert.Equal(t, "shop", mycnf.Context["database"])
+	assert.Equal(t, "mysql", mycnf.Context["driver"])
+
+	dbeaver := options[byId["dbeaver:postgres-jdbc-1"]]
+	assert.Equal(t, "app", dbeaver.Context["user"])
+	assert.Equal(t, "dev", dbeaver.Context["environment"])
+	assert.Equal(t, "/tmp/cache.db", options[byId["dbeaver:sqlite-1"]].Context["path"])
+
+	datagrip := options[byId["datagrip:0a1b"]]
+	assert.Equal(t, "mysql", datagrip.Context["driver"])
+	assert.Equal(t, "3306", datagrip.Context["port"])
+	assert.Equal(t, "orders", datagrip.Context["database"])
+}
+
+func TestConnectionCredentials(t *testing.T) {
+	module := testModule(t)
+	options, err := module.Options()
+	require.NoError(t, err)
+
+	passwords := map[string]string{}
+	for _, o := range options {
+		conn, err := module.Connection(&o)
+		require.NoError(t, err)
+		passwords[o.Id] = conn.Password
+	}
+	assert.Equal(t, "pg-secret", passwords["pgpass:db.example.com:5432/app@app"])
+	assert.Equal(t, "pa:ss", passwords["pgpass:localhost:/@admin"])
+	assert.Equal(t, "mysql-secret", passwords["my-cnf:client"])
+	assert.Equal(t, "dbeaver-secret", passwords["dbeaver:postgres-jdbc-1"])
+}
+
+func TestClientCommand(t *testing.T) {
+	tests := []struct {
+		name   string
+		conn   Connection
+		client string
+		args   []string
+		env    []string
+	}{
+		{
+			name:   "psql",
+			conn:   Connection{Driver: driverPostgres, Host: "localhost", Port: "5432", Database: "app", User: "app", Password: "secret"},
+			client: ClientPSQL,
+			args:   []string{"psql"},
+			env:    []string{"PGHOST=localhost", "PGPORT=5432", "PGDATABASE=app", "PGUSER=app", "PGPASSWORD=secret"},
+		},
+		{
+			name:   "psql-service",
+			conn:   Connection{Driver: driverPostgres, Service: "prod", Password: "secret"},
+			client: ClientPSQL,
+			args:   []string{"psql"},
+			env:    []string{"PGSERVICE=prod"},
+		},
+		{
+			name:   "mysql",
+			conn:   Connection{Driver: driverMySQL, Host: "db", Port: "3306", Database: "shop", User: "app", Password: "secret"},
+			client: ClientMySQL,
+			args:   []string{"mysql", "--host=db", "--port=3306", "--user=app", "shop"},
+			env:    []string{"MYSQL_PWD=secret"},
+		},
+		{
+			name:   "mysql-my-cnf",
+			conn:   Connection{Source: sourceMyCnf, Driver: driverMySQL, Group: "staging", Password: "secret"},
+			client: ClientMySQL,
+			args:   []string{"mysql", "--defaults-group-suffix=staging"},
+		},
+		{
+			name:   "usql",
+			conn:   Connection{Driver: "sqlserver", Host: "db", Port: "1433", Database: "master", User: "sa", Password: "secret"},
+			client: ClientUSQL,
+			args:   []string{"usql", "sqlserver://sa@db:1433/master"},
+		},
+		{
+			name:   "usql-file",
+			conn:   Connection{Driver: "sqlite3", Path: "/tmp/cache.db"},
+			client: ClientUSQL,
+			args:   []string{"usql", "sqlite3:/tmp/cache.db"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args, env, err := tt.conn.ClientCommand(tt.client)
+			require.NoError(t, err)
+			assert.Equal(t, tt.args, args)
+			assert.Equal(t, tt.env, env)
+		})
+	}
+
+	_, _, err := Connection{Driver: driverMySQL}.ClientCommand(ClientPSQL)
+	assert.Error(t, err)
+}
@@ -0,0 +1,213 @@
+package database
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// dbeaverKey is the static key DBeaver uses to encrypt credentials-config.json
+const dbeaverKey = "babb4a9f774ab853c96c2d653dfe544a"
+
+type dbeaverDataSources struct {
+	Connections map[string]struct {
+		Provider      string `json:"provider"`
+		Driver        string `json:"driver"`
+		Name          string `json:"name"`
+		Folder        string `json:"folder"`
+		Configuration struct {
+			Host     string `json:"host"`
+			Port     string `json:"port"`
+			Database string `json:"database"`
+			URL      string `json:"url"`
+			User     string `json:"user"`
+			Type     string `json:"type"`
+		} `json:"configuration"`
+	} `json:"connections"`
+}
+
+type dbeaverCredentials map[string]struct {
+	Connection struct {
+		User     string `json:"user"`
+		Password string `json:"password"`
+	} `json:"#connection"`
+}
+
+// parseDBeaver parses a DBeaver data-sources.json file, the credentials are read from the credentials-config.json next to it
+func parseDBeaver(path string) ([]Connection, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var dataSources dbeaverDataSources
+	if err = json.Unmarshal(data, &dataSources); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	credentials, err := readDBeaverCredentials(filepath.Join(filepath.Dir(path), "credentials-config.json"))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	var result []Connection
+	for id, ds := range dataSources.Connections {
+		conn := Connection{
+			Source:      sourceDBeaver,
+			Key:         id,
+			Name:        ds.Name,
+			Driver:      normalizeDriver(ds.Provider),
+			Host:        ds.Configuration.Host,
+			Port:        ds.Configuration.Port,
+			Database:    ds.Configuration.Database,
+			User:        ds.Configuration.User,
+			Environment: ds.Configuration.Type,
+		}
+		if conn.Host == "" && conn.Database == "" && ds.Configuration.URL != "" {
+			conn.applyJDBCURL(ds.Configuration.URL)
+		}
+		if conn.Host == "" && conn.Database != "" {
+			conn.Path = conn.Database
+		}
+		if c, ok := credentials[id]; ok {
+			if c.Connection.User != "" {
+				conn.User = c.Connection.User
+			}
+			conn.Password = c.Connection.Password
+		}
+		result = append(result, conn)
+	}
+
+	return result, nil
+}
+
+// readDBeaverCredentials decrypts the credentials-config.json file (AES-128-CBC, the iv is prepended)
+func readDBeaverCredentials(path string) (dbeaverCredentials, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	key, _ := hex.DecodeString(dbeaverKey)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < 2*aes.BlockSize || len(data)%aes.BlockSize != 0 {
+		return nil, fmt.Errorf("invalid credentials file: %s", path)
+	}
+
+	plain := make([]byte, len(data)-aes.BlockSize)
+	cipher.NewCBCDecrypter(block, data[:aes.BlockSize]).CryptBlocks(plain, data[aes.BlockSize:])
+
+	// remove the PKCS#7 padding
+	padding := int(plain[len(plain)-1])
+	if padding == 0 || padding > aes.BlockSize {
+		return nil, fmt.Errorf("invalid padding in credentials file: %s", path)
+	}
+	plain = plain[:len(plain)-padding]
+
+	var credentials dbeaverCredentials
+	if err = json.Unmarshal(plain, &credentials); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return credentials, nil
+}
+
+type dataGripDataSources struct {
+	Components []struct {
+		DataSources []struct {
+			Name      string `xml:"name,attr"`
+			UUID      string `xml:"uuid,attr"`
+			DriverRef string `xml:"driver-ref"`
+			JDBCURL   string `xml:"jdbc-url"`
+			UserName  string `xml:"user-name"`
+		} `xml:"data-source"`
+	} `xml:"component"`
+}
+
+// parseDataGrip parses a .idea/dataSources.xml file of a DataGrip or IntelliJ project, passwords are stored in the system keychain and are not available
+func parseDataGrip(path string) ([]Connection, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var project dataGripDataSources
+	if err = xml.Unmarshal(data, &project); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	var result []Connection
+	for _, component := range project.Components {
+		for _, ds := range component.DataSources {
+			conn := Connection{
+				Source: sourceDataGrip,
+				Key:    ds.UUID,
+				Name:   ds.Name,
+				Driver: normalizeDriver(ds.DriverRef),
+				User:   ds.UserName,
+			}
+			conn.applyJDBCURL(ds.JDBCURL)
+			result = append(result, conn)
+		}
+	}
+
+	return result, nil
+}
+
+// applyJDBCURL reads host, port, database and file path of a jdbc url, e.g. jdbc:postgresql://localhost:5432/app or jdbc:sqlite:/path/to/app.db
+func (c *Connection) applyJDBCURL(jdbcURL string) {
+	rest, ok := strings.CutPrefix(jdbcURL, "jdbc:")
+	if !ok {
+		return
+	}
+
+	u, err := url.Parse(rest)
+	if err != nil {
+		return
+	}
+	if u.Opaque != "" {
+		c.Path, _, _ = strings.Cut(u.Opaque, "?")
+		return
+	}
+
+	c.Host = u.Hostname()
+	c.Port = u.Port()
+	c.Database = strings.Trim(u.Path, "/")
+	if c.Host == "" && u.Path != "" {
+		c.Path = u.Path
+		c.Database = ""
+	}
+	if c.User == "" {
+		c.User = u.Query().Get("user")
+	}
+}
+
+// normalizeDriver maps the driver names of DBeaver and DataGrip to usql schemes
+func normalizeDriver(driver string) string {
+	driver = strings.ToLower(driver)
+	switch {
+	case strings.HasPrefix(driver, "postgres"):
+		return driverPostgres
+	case strings.HasPrefix(driver, "mysql"), strings.HasPrefix(driver, "mariadb"):
+		return driverMySQL
+	case strings.HasPrefix(driver, "sqlserver"), strings.HasPrefix(driver, "mssql"):
+		return "sqlserver"
+	case strings.HasPrefix(driver, "oracle"):
+		return "oracle"
+	case strings.HasPrefix(driver, "sqlite"):
+		return "sqlite3"
+	case strings.HasPrefix(driver, "clickhouse"):
+		return "clickhouse"
+	}
+	return driver
+}
@@ -0,0 +1,188 @@
+package database
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// parsePGPass parses a ~/.pgpass file (hostname:port:database:username:password), entries with a wildcard host are skipped
+func parsePGPass(path string) ([]Connection, error) {
+	lines, err := readLines(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []Connection
+	for _, line := range lines {
+		fields := splitPGPass(line)
+		if len(fields) != 5 {
+			continue
+		}
+		if fields[0] == "*" || fields[0] == "" {
+			continue
+		}
+
+		conn := Connection{
+			Source:   sourcePGPass,
+			Driver:   driverPostgres,
+			Host:     fields[0],
+			Port:     wildcardValue(fields[1]),
+			Database: wildcardValue(fields[2]),
+			User:     wildcardValue(fields[3]),
+			Password: fields[4],
+		}
+		conn.Key = fmt.Sprintf("%s:%s/%s@%s", conn.Host, conn.Port, conn.Database, conn.User)
+		result = append(result, conn)
+	}
+
+	return result, nil
+}
+
+// splitPGPass splits a pgpass line on unescaped colons, \: and \\ are unescaped
+func splitPGPass(line string) []string {
+	var fields []string
+	var current strings.Builder
+	escaped := false
+	for _, r := range line {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == ':':
+			fields = append(fields, current.String())
+			current.Reset()
+		default:
+			current.WriteRune(r)
+		}
+	}
+	return append(fields, current.String())
+}
+
+func wildcardValue(value string) string {
+	if value == "*" {
+		return ""
+	}
+	return value
+}
+
+// parsePGService parses a pg_service.conf file, every section is a service
+func parsePGService(path string) ([]Connection, error) {
+	sections, err := parseINI(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []Connection
+	for _, s := range sections {
+		result = append(result, Connection{
+			Source:   sourcePGService,
+			Key:      s.name,
+			Name:     s.name,
+			Driver:   driverPostgres,
+			Host:     s.values["host"],
+			Port:     s.values["port"],
+			Database: s.values["dbname"],
+			User:     s.values["user"],
+			Password: s.values["password"],
+			Service:  s.name,
+		})
+	}
+
+	return result, nil
+}
+
+// parseMyCnf parses a my.cnf file, every client group (e.g. [client] or [clientprod]) is a connection
+func parseMyCnf(path string) ([]Connection, error) {
+	sections, err := parseINI(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []Connection
+	for _, s := range sections {
+		suffix, ok := strings.CutPrefix(s.name, "client")
+		if !ok {
+			continue
+		}
+
+		database := s.values["database"]
+		if database == "" {
+			database = s.values["db"]
+		}
+		result = append(result, Connection{
+			Source:   sourceMyCnf,
+			Key:      s.name,
+			Name:     s.name,
+			Driver:   driverMySQL,
+			Host:     s.values["host"],
+			Port:     s.values["port"],
+			Database: database,
+			User:     s.values["user"],
+			Password: s.values["password"],
+			Group:    suffix,
+		})
+	}
+
+	return result, nil
+}
+
+type iniSection struct {
+	name   string
+	values map[string]string
+}
+
+// parseINI parses a simple ini file, keys are lower-case and quotes around values are removed
+func parseINI(path string) ([]iniSection, error) {
+	lines, err := readLines(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []iniSection
+	for _, line := range lines {
+		if strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			result = append(result, iniSection{name: strings.TrimSpace(line[1 : len(line)-1]), values: map[string]string{}})
+			continue
+		}
+		if len(result) == 0 {
+			continue
+		}
+
+		key, value, _ := strings.Cut(line, "=")
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		result[len(result)-1].values[strings.ToLower(strings.TrimSpace(key))] = value
+	}
+
+	return result, nil
+}
+
+// readLines returns all lines of the file, empty lines and comments are skipped
+func readLines(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var result []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		result = append(result, line)
+	}
+
+	return result, scanner.Err()
+}
@@ -0,0 +1,67 @@
+package githost
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// maxPages limits the number of requested pages per query
+const maxPages = 20
+
+// apiClient is a minimal json client for the git hosting apis
+type apiClient struct {
+	BaseURL    string
+	Token      string
+	HTTPClient *http.Client
+}
+
+func newAPIClient(baseURL string, token string) *apiClient {
+	return &apiClient{
+		BaseURL:    strings.TrimSuffix(baseURL, "/"),
+		Token:      token,
+		HTTPClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// get requests the path and decodes the json response into result, the response headers are returned for pagination
+func (c *apiClient) get(path string, query url.Values, result interface{}) (http.Header, error) {
+	requestURL := c.BaseURL + path
+	if len(query) > 0 {
+		requestURL += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequest(http.MethodGet, requestURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "fuzzmux")
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+
+	log.Debug().Str("url", requestURL).Msg("querying git hosting api")
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to request %s: %w", path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("unexpected status code %d for %s: %s", resp.StatusCode, path, strings.TrimSpace(string(body)))
+	}
+
+	if err = json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return nil, fmt.Errorf("failed to decode response of %s: %w", path, err)
+	}
+
+	return resp.Header, nil
+}
@@ -0,0 +1,131 @@
+package githost
+
+import (
+	"net/url"
+	"strconv"
+)
+
+type giteaRepositoryRef struct {
+	Name     string `json:"name"`
+	Owner    string `json:"owner"`
+	FullName string `json:"full_name"`
+}
+
+type giteaIssue struct {
+	ID          int                `json:"id"`
+	Number      int                `json:"number"`
+	Title       string             `json:"title"`
+	HTMLURL     string             `json:"html_url"`
+	User        githubUser         `json:"user"`
+	Labels      []githubLabel      `json:"labels"`
+	Repository  giteaRepositoryRef `json:"repository"`
+	PullRequest *struct {
+		Draft bool `json:"draft"`
+	} `json:"pull_request"`
+}
+
+// giteaLimit is the default maximum page size of gitea
+const giteaLimit = 50
+
+func listGitea(client *apiClient, query []Content) ([]entry, error) {
+	var result []entry
+
+	// gitea uses the same repository format as github
+	if hasContent(query, ContentRepository) {
+		for page := 1; page <= maxPages; page++ {
+			var repos []githubRepository
+			_, err := client.get("/user/repos", url.Values{"limit": {strconv.Itoa(giteaLimit)}, "page": {strconv.Itoa(page)}}, &repos)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, repo := range repos {
+				result = append(result, entry{
+					Kind:          ContentRepository,
+					Owner:         repo.Owner.Login,
+					Repo:          repo.Name,
+					Title:         repo.FullName,
+					Description:   repo.Description,
+					Web:           repo.HTMLURL,
+					CloneURL:      repo.CloneURL,
+					SSHURL:        repo.SSHURL,
+					DefaultBranch: repo.DefaultBranch,
+					Language:      repo.Language,
+					Private:       repo.Private,
+					Fork:          repo.Fork,
+					Archived:      repo.Archived,
+				})
+			}
+			if len(repos) < giteaLimit {
+				break
+			}
+		}
+	}
+
+	// pull requests created by the user or with a review request, assigned issues
+	searches := []struct {
+		kind    Content
+		filters []string
+	}{
+		{kind: ContentPullRequest, filters: []string{"created", "review_requested"}},
+		{kind: ContentIssue, filters: []string{"assigned"}},
+	}
+	for _, search := range searches {
+		if !hasContent(query, search.kind) {
+			continue
+		}
+
+		issueType := "issues"
+		if search.kind == ContentPullRequest {
+			issueType = "pulls"
+		}
+
+		seen := make(map[int]bool)
+		for _, filter := range search.filters {
+			for page := 1; page <= maxPages; page++ {
+				var issues []giteaIssue
+				_, err := client.get("/repos/issues/search", url.Values{
+					"state": {"open"},
+					"type":  {issueType},
+					filter:  {"true"},
+					"limit": {strconv.Itoa(giteaLimit)},
+					"page":  {strconv.Itoa(page)},
+				}, &issues)
+				if err != nil {
+					return nil, err
+				}
+
+				for _, issue := range issues {
+					if seen[issue.ID] {
+						continue
+					}
+					seen[issue.ID] = true
+
+					var labels []string
+					for _, l := range issue.Labels {
+						labels = append(labels, l.Name)
+					}
+					e := entry{
+						Kind:   search.kind,
+						Owner:  issue.Repository.Owner,
+						Repo:   issue.Repository.Name,
+						Number: issue.Number,
+						Title:  issue.Title,
+						Web:    issue.HTMLURL,
+						Author: issue.User.Login,
+						Labels: labels,
+					}
+					if issue.PullRequest != nil {
+						e.Draft = issue.PullRequest.Draft
+					}
+					result = append(result, e)
+				}
+				if len(issues) < giteaLimit {
+					break
+				}
+			}
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,321 @@
+package githost
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/PhilippHeuer/fuzzmux/pkg/recon"
+	"github.com/PhilippHeuer/fuzzmux/pkg/recon/project"
+	"github.com/PhilippHeuer/fuzzmux/pkg/util"
+	"github.com/rs/zerolog/log"
+)
+
+const moduleType = "githost"
+
+type Module struct {
+	Config ModuleConfig
+}
+
+type ModuleConfig struct {
+	// Name is used to override the default module name
+	Name string `yaml:"name,omitempty"`
+
+	// DisplayName is a template string to render a custom display name
+	DisplayName string `yaml:"display-name"`
+
+	// StartDirectory is a template string that defines the start directory
+	StartDirectory string `yaml:"start-directory"`
+
+	// Provider is the type of the git hosting service
+	Provider Provider `yaml:"provider"`
+
+	// Host is the url of the git hosting service, defaults to https://github.com or https://gitlab.com
+	Host string `yaml:"host"`
+
+	// BearerToken is the personal access token used to authenticate against the api
+	BearerToken string `yaml:"bearer-token,omitempty"`
+
+	// Query is a list of content types that should be queried
+	Query []Content `yaml:"query"`
+
+	// CloneDirectory enables cloning repositories on selection into this directory
+	CloneDirectory string `yaml:"clone-directory"`
+
+	// CloneTemplate is the path of the clone inside the CloneDirectory, defaults to {{host}}/{{owner}}/{{repo}} (same as the project module)
+	CloneTemplate string `yaml:"clone-template"`
+
+	// CloneProtocol is https (default) or ssh
+	CloneProtocol string `yaml:"clone-protocol"`
+}
+
+type Provider string
+
+const (
+	ProviderGitHub Provider = "github"
+	ProviderGitLab Provider = "gitlab"
+	ProviderGitea  Provider = "gitea"
+)
+
+type Content string
+
+const (
+	ContentRepository  Content = "repository"
+	ContentPullRequest Content = "pull-request"
+	ContentIssue       Content = "issue"
+)
+
+// entry is a repository, pull request or issue independent of the provider
+type entry struct {
+	Kind          Content
+	Owner         string // Owner is the user, organization or group (including subgroups)
+	Repo          string
+	Number        int
+	Title         string
+	Description   string
+	Web           string
+	Author        string
+	Labels        []string
+	Branch        string
+	Draft         bool
+	CloneURL      string
+	SSHURL        string
+	DefaultBranch string
+	Language      string
+	Private       bool
+	Fork          bool
+	Archived      bool
+}
+
+func (c *ModuleConfig) DecodeConfig() error {
+	err := util.ResolveCredentialValues(&c.Host, &c.BearerToken)
+	c.Host = strings.TrimSuffix(c.Host, "/")
+	return err
+}
+
+func (p Module) Name() string {
+	if p.Config.Name != "" {
+		return p.Config.Name
+	}
+	return moduleType
+}
+
+func (p Module) Type() string {
+	return moduleType
+}
+
+func (p Module) Options() ([]recon.Option, error) {
+	if err := p.Config.DecodeConfig(); err != nil {
+		return nil, fmt.Errorf("failed to resolve credentials: %w", err)
+	}
+	var result []recon.Option
+	if p.Config.Host == "" {
+		return nil, fmt.Errorf("host is required for provider %s", p.Config.Provider)
+	}
+
+	entries, err := p.list()
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s: %w", p.Config.Provider, err)
+	}
+
+	hostname := p.hostname()
+	for _, e := range entries {
+		repository := e.Owner + "/" + e.Repo
+		opt := recon.Option{
+			ProviderName:   p.Name(),
+			ProviderType:   p.Type(),
+			Name:           e.Repo,
+			Description:    e.Description,
+			Web:            e.Web,
+			StartDirectory: "~",
+			Tags:           []string{"githost", string(p.Config.Provider), string(e.Kind)},
+			Context: map[string]string{
+				"kind":       string(e.Kind),
+				"host":       hostname,
+				"owner":      e.Owner,
+				"repo":       e.Repo,
+				"repository": repository,
+			},
+		}
+
+		switch e.Kind {
+		case ContentRepository:
+			opt.Id = fmt.Sprintf("%s/%s", hostname, repository)
+			opt.DisplayName = repository
+			opt.Context["cloneUrl"] = e.CloneURL
+			opt.Context["sshUrl"] = e.SSHURL
+			opt.Context["defaultBranch"] = e.DefaultBranch
+			opt.Context["language"] = e.Language
+			if e.Private {
+				opt.Tags = append(opt.Tags, "private")
+			}
+			if e.Fork {
+				opt.Tags = append(opt.Tags, "fork")
+			}
+			if e.Archived {
+				opt.Tags = append(opt.Tags, "archived")
+			}
+		default:
+			opt.Id = fmt.Sprintf("%s/%s/%s/%d", hostname, repository, e.Kind, e.Number)
+			opt.Name = fmt.Sprintf("%s#%d", e.Repo, e.Number)
+			opt.DisplayName = fmt.Sprintf("%s#%d: %s", repository, e.Number, e.Title)
+			opt.Description = e.Title
+			opt.Context["number"] = strconv.Itoa(e.Number)
+			opt.Context["title"] = e.Title
+			opt.Context["author"] = e.Author
+			opt.Context["branch"] = e.Branch
+			opt.Context["labels"] = strings.Join(e.Labels, ",")
+			if e.Draft {
+				opt.Tags = append(opt.Tags, "draft")
+			}
+		}
+
+		// repositories and pull requests are cloned on selection
+		if p.Config.CloneDirectory != "" && e.Kind != ContentIssue {
+			ref := p.repositoryRef(hostname, e)
+			if path, err := project.ClonePath(p.cloneSource(), ref); err == nil {
+				opt.StartDirectory = path
+				opt.Context["cloneUrl"] = ref.URL
+				opt.Context["layout"] = "project"
+			} else {
+				log.Debug().Err(err).Str("repository", e.Owner+"/"+e.Repo).Msg("skipping clone of repository")
+			}
+		}
+
+		// remove empty values, placeholders without a value are not replaced
+		for k, v := range opt.Context {
+			if v == "" {
+				delete(opt.Context, k)
+			}
+		}
+
+		opt.ProcessUserTemplateStrings(p.Config.DisplayName, p.Config.StartDirectory)
+		result = append(result, opt)
+	}
+
+	return result, nil
+}
+
+func (p Module) OptionsOrCache(maxAge float64) ([]recon.Option, error) {
+	return recon.OptionsOrCache(p, maxAge)
+}
+
+func (p Module) SelectOption(option *recon.Option) error {
+	return nil
+}
+
+// PrepareOption clones the repository of the option, if cloning is enabled and it's not cloned yet
+func (p Module) PrepareOption(option *recon.Option) error {
+	if p.Config.CloneDirectory == "" || option.Context["cloneUrl"] == "" || option.Context["kind"] == string(ContentIssue) {
+		return option.CreateStartDirectoryIfMissing()
+	}
+
+	ref := project.RepositoryRef{
+		URL:   option.Context["cloneUrl"],
+		Host:  option.Context["host"],
+		Owner: option.Context["owner"],
+		Repo:  option.Context["repo"],
+	}
+	path, err := project.CloneRepository(p.cloneSource(), ref)
+	if err != nil {
+		return err
+	}
+	option.StartDirectory = path
+
+	return nil
+}
+
+func (p Module) Columns() []recon.Column {
+	return append(recon.DefaultColumns(),
+		recon.Column{Key: "kind", Name: "Kind"},
+		recon.Column{Key: "repository", Name: "Repository"},
+		recon.Column{Key: "author", Name: "Author"},
+	)
+}
+
+func (p Module) list() ([]entry, error) {
+	client := newAPIClient(p.apiURL(), p.Config.BearerToken)
+
+	switch p.Config.Provider {
+	case ProviderGitHub:
+		return listGitHub(client, p.Config.Query)
+	case ProviderGitLab:
+		return listGitLab(client, p.Config.Query)
+	case ProviderGitea:
+		return listGitea(client, p.Config.Query)
+	}
+
+	return nil, fmt.Errorf("unsupported provider %q", p.Config.Provider)
+}
+
+func (p Module) apiURL() string {
+	switch p.Config.Provider {
+	case ProviderGitHub:
+		return githubAPIURL(p.Config.Host)
+	case ProviderGitLab:
+		return p.Config.Host + "/api/v4"
+	case ProviderGitea:
+		return p.Config.Host + "/api/v1"
+	}
+	return p.Config.Host
+}
+
+// hostname returns the hostname of the web interface, e.g. github.com
+func (p Module) hostname() string {
+	u, err := url.Parse(p.Config.Host)
+	if err != nil || u.Host == "" {
+		return p.Config.Host
+	}
+	return u.Host
+}
+
+func (p Module) cloneSource() project.SourceDirectory {
+	return project.SourceDirectory{
+		Directory:     p.Config.CloneDirectory,
+		CloneTemplate: p.Config.CloneTemplate,
+	}
+}
+
+// repositoryRef returns the clone reference, pull requests and issues don't contain the clone urls
+func (p Module) repositoryRef(hostname string, e entry) project.RepositoryRef {
+	cloneURL := e.CloneURL
+	if p.Config.CloneProtocol == "ssh" {
+		cloneURL = e.SSHURL
+	}
+	if cloneURL == "" {
+		if p.Config.CloneProtocol == "ssh" {
+			cloneURL = fmt.Sprintf("git@%s:%s/%s.git", hostname, e.Owner, e.Repo)
+		} else {
+			cloneURL = fmt.Sprintf("%s/%s/%s.git", p.Config.Host, e.Owner, e.Repo)
+		}
+	}
+
+	return project.RepositoryRef{URL: cloneURL, Host: hostname, Owner: e.Owner, Repo: e.Repo}
+}
+
+func hasContent(query []Content, content Content) bool {
+	return slices.Contains(query, content)
+}
+
+func NewModule(config ModuleConfig) Module {
+	if config.Provider == "" {
+		config.Provider = ProviderGitHub
+	}
+	if config.Host == "" {
+		switch config.Provider {
+		case ProviderGitHub:
+			config.Host = "https://github.com"
+		case ProviderGitLab:
+			config.Host = "https://gitlab.com"
+		}
+	}
+	if len(config.Query) == 0 {
+		config.Query = []Content{ContentRepository, ContentPullRequest, ContentIssue}
+	}
+
+	return Module{
+		Config: config,
+	}
+}
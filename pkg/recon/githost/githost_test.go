@@ -0,0 +1,195 @@
+package githost
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PhilippHeuer/fuzzmux/pkg/recon"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestServer serves json responses by path (and optionally the raw query), the bearer token is validated
+func newTestServer(t *testing.T, responses map[string]interface{}, headers map[string]map[string]string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		key := r.URL.Path
+		if _, ok := responses[key+"?"+r.URL.RawQuery]; ok {
+			key = key + "?" + r.URL.RawQuery
+		}
+		response, ok := responses[key]
+		if !ok {
+			t.Logf("unexpected request: %s", r.URL.String())
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		for k, v := range headers[key] {
+			w.Header().Set(k, v)
+		}
+		_ = json.NewEncoder(w).Encode(response)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func optionById(t *testing.T, options []recon.Option, id string) recon.Option {
+	t.Helper()
+	for _, o := range options {
+		if o.Id == id {
+			return o
+		}
+	}
+	var ids []string
+	for _, o := range options {
+		ids = append(ids, o.Id)
+	}
+	require.Failf(t, "option not found", "%s not in %s", id, strings.Join(ids, ", "))
+	return recon.Option{}
+}
+
+func TestGitHub(t *testing.T) {
+	server := newTestServer(t, map[string]interface{}{
+		"/api/v3/user/repos": []map[string]interface{}{
+			{"full_name": "octo/hello", "name": "hello", "owner": map[string]string{"login": "octo"}, "html_url": "https://github.example.com/octo/hello", "clone_url": "https://github.example.com/octo/hello.git", "ssh_url": "git@github.example.com:octo/hello.git", "default_branch": "main", "private": true},
+		},
+		"/api/v3/user": map[string]string{"login": "octo"},
+		"/api/v3/search/issues?page=1&per_page=100&q=is%3Aopen+is%3Apr+involves%3Aocto": map[string]interface{}{
+			"items": []map[string]interface{}{
+				{"number": 7, "title": "Add feature", "html_url": "https://github.example.com/octo/hello/pull/7", "repository_url": "https://github.example.com/api/v3/repos/octo/hello", "user": map[string]string{"login": "hubot"}, "labels": []map[string]string{{"name": "enhancement"}}, "draft": true, "pull_request": map[string]string{}},
+			},
+		},
+		"/api/v3/search/issues?page=1&per_page=100&q=is%3Aopen+is%3Aissue+assignee%3Aocto": map[string]interface{}{
+			"items": []map[string]interface{}{
+				{"number": 3, "title": "Bug", "html_url": "https://github.example.com/octo/hello/issues/3", "repository_url": "https://github.example.com/api/v3/repos/octo/hello", "user": map[string]string{"login": "hubot"}},
+			},
+		},
+	}, nil)
+
+	module := NewModule(ModuleConfig{Provider: ProviderGitHub, Host: server.URL, BearerToken: "secret"})
+	options, err := module.Options()
+	require.NoError(t, err)
+	require.Len(t, options, 3)
+
+	host := strings.TrimPrefix(server.URL, "http://")
+	repo := optionById(t, options, host+"/octo/hello")
+	assert.Equal(t, "octo/hello", repo.DisplayName)
+	assert.Equal(t, "https://github.example.com/octo/hello", repo.Web)
+	assert.Equal(t, "https://github.example.com/octo/hello.git", repo.Context["cloneUrl"])
+	assert.Contains(t, repo.Tags, "private")
+
+	pr := optionById(t, options, host+"/octo/hello/pull-request/7")
+	assert.Equal(t, "octo/hello#7: Add feature", pr.DisplayName)
+	assert.Equal(t, "hubot", pr.Context["author"])
+	assert.Equal(t, "enhancement", pr.Context["labels"])
+	assert.Contains(t, pr.Tags, "draft")
+
+	issue := optionById(t, options, host+"/octo/hello/issue/3")
+	assert.Equal(t, "https://github.example.com/octo/hello/issues/3", issue.Web)
+}
+
+func TestGitHubSearchLimit(t *testing.T) {
+	searches := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/user":
+			_ = json.NewEncoder(w).Encode(map[string]string{"login": "octo"})
+		case "/search/issues":
+			// the search api only returns the first 1000 results
+			searches++
+			if r.URL.Query().Get("page") == "11" {
+				w.WriteHeader(http.StatusUnprocessableEntity)
+				return
+			}
+			items := make([]map[string]interface{}, 100)
+			for i := range items {
+				items[i] = map[string]interface{}{"number": i, "repository_url": "https://api.github.com/repos/octo/hello"}
+			}
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"total_count": 5000, "items": items})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	entries, err := listGitHub(newAPIClient(server.URL, "secret"), []Content{ContentIssue})
+	require.NoError(t, err)
+	assert.Len(t, entries, 1000)
+	assert.Equal(t, 10, searches)
+}
+
+func TestGitLab(t *testing.T) {
+	server := newTestServer(t, map[string]interface{}{
+		"/api/v4/projects?membership=true&page=1&per_page=100": []map[string]interface{}{
+			{"id": 1, "path": "api", "path_with_namespace": "group/sub/api", "namespace": map[string]string{"full_path": "group/sub"}, "web_url": "https://gitlab.example.com/group/sub/api", "http_url_to_repo": "https://gitlab.example.com/group/sub/api.git", "visibility": "private"},
+		},
+		"/api/v4/projects?membership=true&page=2&per_page=100": []map[string]interface{}{
+			{"id": 2, "path": "web", "path_with_namespace": "group/web", "namespace": map[string]string{"full_path": "group"}, "web_url": "https://gitlab.example.com/group/web", "visibility": "public"},
+		},
+		"/api/v4/merge_requests?page=1&per_page=100&scope=created_by_me&state=opened": []map[string]interface{}{
+			{"id": 100, "iid": 12, "title": "Fix login", "web_url": "https://gitlab.example.com/group/sub/api/-/merge_requests/12", "references": map[string]string{"full": "group/sub/api!12"}, "author": map[string]string{"username": "jane"}, "source_branch": "fix-login"},
+		},
+		"/api/v4/merge_requests?page=1&per_page=100&scope=assigned_to_me&state=opened": []map[string]interface{}{
+			{"id": 100, "iid": 12, "title": "Fix login", "web_url": "https://gitlab.example.com/group/sub/api/-/merge_requests/12", "references": map[string]string{"full": "group/sub/api!12"}, "author": map[string]string{"username": "jane"}, "source_branch": "fix-login"},
+		},
+		"/api/v4/issues?page=1&per_page=100&scope=assigned_to_me&state=opened": []map[string]interface{}{},
+	}, map[string]map[string]string{
+		"/api/v4/projects?membership=true&page=1&per_page=100": {"X-Next-Page": "2"},
+	})
+
+	cloneDir := t.TempDir()
+	module := NewModule(ModuleConfig{Provider: ProviderGitLab, Host: server.URL + "/", BearerToken: "secret", CloneDirectory: cloneDir})
+	options, err := module.Options()
+	require.NoError(t, err)
+	require.Len(t, options, 3)
+
+	host := strings.TrimPrefix(server.URL, "http://")
+	repo := optionById(t, options, host+"/group/sub/api")
+	assert.Equal(t, filepath.Join(cloneDir, host, "group", "sub", "api"), repo.StartDirectory)
+	assert.Equal(t, "project", repo.Context["layout"])
+	assert.Contains(t, repo.Tags, "private")
+
+	// the clone url is derived from the host if missing
+	web := optionById(t, options, host+"/group/web")
+	assert.Equal(t, server.URL+"/group/web.git", web.Context["cloneUrl"])
+
+	mr := optionById(t, options, host+"/group/sub/api/pull-request/12")
+	assert.Equal(t, "fix-login", mr.Context["branch"])
+	assert.Equal(t, "group/sub", mr.Context["owner"])
+	assert.Equal(t, repo.StartDirectory, mr.StartDirectory)
+}
+
+func TestGitea(t *testing.T) {
+	server := newTestServer(t, map[string]interface{}{
+		"/api/v1/user/repos": []map[string]interface{}{
+			{"full_name": "team/tool", "name": "tool", "owner": map[string]string{"login": "team"}, "html_url": "https://gitea.example.com/team/tool", "fork": true},
+		},
+		"/api/v1/repos/issues/search?created=true&limit=50&page=1&state=open&type=pulls": []map[string]interface{}{
+			{"id": 5, "number": 2, "title": "Refactor", "html_url": "https://gitea.example.com/team/tool/pulls/2", "repository": map[string]string{"name": "tool", "owner": "team", "full_name": "team/tool"}, "pull_request": map[string]bool{"draft": false}},
+		},
+		"/api/v1/repos/issues/search?limit=50&page=1&review_requested=true&state=open&type=pulls": []map[string]interface{}{},
+		"/api/v1/repos/issues/search?assigned=true&limit=50&page=1&state=open&type=issues":        []map[string]interface{}{},
+	}, nil)
+
+	module := NewModule(ModuleConfig{Provider: ProviderGitea, Host: server.URL, BearerToken: "secret"})
+	options, err := module.Options()
+	require.NoError(t, err)
+	require.Len(t, options, 2)
+
+	host := strings.TrimPrefix(server.URL, "http://")
+	assert.Contains(t, optionById(t, options, host+"/team/tool").Tags, "fork")
+	assert.Equal(t, "team/tool#2: Refactor", optionById(t, options, host+"/team/tool/pull-request/2").DisplayName)
+
+	// invalid token
+	module.Config.BearerToken = "invalid"
+	_, err = module.Options()
+	assert.ErrorContains(t, err, "401")
+}
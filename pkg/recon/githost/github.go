@@ -0,0 +1,152 @@
+package githost
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+// githubSearchLimit is the maximum number of results returned by the github search api, later pages fail with 422
+const githubSearchLimit = 1000
+
+type githubUser struct {
+	Login string `json:"login"`
+}
+
+type githubRepository struct {
+	FullName      string     `json:"full_name"`
+	Name          string     `json:"name"`
+	Owner         githubUser `json:"owner"`
+	Description   string     `json:"description"`
+	HTMLURL       string     `json:"html_url"`
+	CloneURL      string     `json:"clone_url"`
+	SSHURL        string     `json:"ssh_url"`
+	DefaultBranch string     `json:"default_branch"`
+	Language      string     `json:"language"`
+	Private       bool       `json:"private"`
+	Fork          bool       `json:"fork"`
+	Archived      bool       `json:"archived"`
+}
+
+type githubLabel struct {
+	Name string `json:"name"`
+}
+
+type githubIssue struct {
+	Number        int           `json:"number"`
+	Title         string        `json:"title"`
+	HTMLURL       string        `json:"html_url"`
+	RepositoryURL string        `json:"repository_url"`
+	User          githubUser    `json:"user"`
+	Labels        []githubLabel `json:"labels"`
+	Draft         bool          `json:"draft"`
+	PullRequest   *struct{}     `json:"pull_request"`
+}
+
+type githubSearchResult struct {
+	TotalCount int           `json:"total_count"`
+	Items      []githubIssue `json:"items"`
+}
+
+// githubAPIURL returns the api url for github.com or github enterprise
+func githubAPIURL(host string) string {
+	if host == "https://github.com" {
+		return "https://api.github.com"
+	}
+	return host + "/api/v3"
+}
+
+func listGitHub(client *apiClient, query []Content) ([]entry, error) {
+	var result []entry
+
+	if hasContent(query, ContentRepository) {
+		for page := 1; page <= maxPages; page++ {
+			var repos []githubRepository
+			_, err := client.get("/user/repos", url.Values{"per_page": {"100"}, "page": {strconv.Itoa(page)}}, &repos)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, repo := range repos {
+				result = append(result, entry{
+					Kind:          ContentRepository,
+					Owner:         repo.Owner.Login,
+					Repo:          repo.Name,
+					Title:         repo.FullName,
+					Description:   repo.Description,
+					Web:           repo.HTMLURL,
+					CloneURL:      repo.CloneURL,
+					SSHURL:        repo.SSHURL,
+					DefaultBranch: repo.DefaultBranch,
+					Language:      repo.Language,
+					Private:       repo.Private,
+					Fork:          repo.Fork,
+					Archived:      repo.Archived,
+				})
+			}
+			if len(repos) < 100 {
+				break
+			}
+		}
+	}
+
+	if !hasContent(query, ContentPullRequest) && !hasContent(query, ContentIssue) {
+		return result, nil
+	}
+
+	var user githubUser
+	if _, err := client.get("/user", nil, &user); err != nil {
+		return nil, err
+	}
+
+	searches := map[Content]string{
+		ContentPullRequest: "is:open is:pr involves:" + user.Login,
+		ContentIssue:       "is:open is:issue assignee:" + user.Login,
+	}
+	for _, kind := range []Content{ContentPullRequest, ContentIssue} {
+		if !hasContent(query, kind) {
+			continue
+		}
+
+		for page := 1; page <= githubSearchLimit/100; page++ {
+			var search githubSearchResult
+			_, err := client.get("/search/issues", url.Values{"q": {searches[kind]}, "per_page": {"100"}, "page": {strconv.Itoa(page)}}, &search)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, issue := range search.Items {
+				// repository_url: https://api.github.com/repos/<owner>/<repo>
+				_, path, _ := strings.Cut(issue.RepositoryURL, "/repos/")
+				owner, repo, _ := strings.Cut(path, "/")
+
+				var labels []string
+				for _, l := range issue.Labels {
+					labels = append(labels, l.Name)
+				}
+
+				result = append(result, entry{
+					Kind:   kind,
+					Owner:  owner,
+					Repo:   repo,
+					Number: issue.Number,
+					Title:  issue.Title,
+					Web:    issue.HTMLURL,
+					Author: issue.User.Login,
+					Labels: labels,
+					Draft:  issue.Draft,
+				})
+			}
+			if len(search.Items) < 100 || page*100 >= search.TotalCount {
+				break
+			}
+			if page*100 >= githubSearchLimit {
+				log.Warn().Str("query", searches[kind]).Int("total", search.TotalCount).Int("limit", githubSearchLimit).Msg("github search exceeded the result limit, the result is truncated")
+			}
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,146 @@
+package githost
+
+import (
+	"net/url"
+	"strings"
+)
+
+type gitlabNamespace struct {
+	FullPath string `json:"full_path"`
+}
+
+type gitlabProject struct {
+	ID                int             `json:"id"`
+	Path              string          `json:"path"`
+	PathWithNamespace string          `json:"path_with_namespace"`
+	Namespace         gitlabNamespace `json:"namespace"`
+	Description       string          `json:"description"`
+	WebURL            string          `json:"web_url"`
+	HTTPURLToRepo     string          `json:"http_url_to_repo"`
+	SSHURLToRepo      string          `json:"ssh_url_to_repo"`
+	DefaultBranch     string          `json:"default_branch"`
+	Visibility        string          `json:"visibility"`
+	Archived          bool            `json:"archived"`
+	ForkedFromProject *struct{}       `json:"forked_from_project"`
+}
+
+type gitlabUser struct {
+	Username string `json:"username"`
+}
+
+type gitlabReferences struct {
+	Full string `json:"full"`
+}
+
+type gitlabIssue struct {
+	ID           int              `json:"id"`
+	IID          int              `json:"iid"`
+	Title        string           `json:"title"`
+	WebURL       string           `json:"web_url"`
+	Author       gitlabUser       `json:"author"`
+	Labels       []string         `json:"labels"`
+	References   gitlabReferences `json:"references"`
+	SourceBranch string           `json:"source_branch"`
+	Draft        bool             `json:"draft"`
+}
+
+// gitlabList requests all pages of a list endpoint, using the X-Next-Page header
+func gitlabList[T any](client *apiClient, path string, query url.Values) ([]T, error) {
+	var result []T
+
+	query.Set("per_page", "100")
+	page := "1"
+	for i := 0; i < maxPages && page != ""; i++ {
+		query.Set("page", page)
+
+		var items []T
+		header, err := client.get(path, query, &items)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, items...)
+		page = header.Get("X-Next-Page")
+	}
+
+	return result, nil
+}
+
+func listGitLab(client *apiClient, query []Content) ([]entry, error) {
+	var result []entry
+
+	if hasContent(query, ContentRepository) {
+		projects, err := gitlabList[gitlabProject](client, "/projects", url.Values{"membership": {"true"}})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, p := range projects {
+			result = append(result, entry{
+				Kind:          ContentRepository,
+				Owner:         p.Namespace.FullPath,
+				Repo:          p.Path,
+				Title:         p.PathWithNamespace,
+				Description:   p.Description,
+				Web:           p.WebURL,
+				CloneURL:      p.HTTPURLToRepo,
+				SSHURL:        p.SSHURLToRepo,
+				DefaultBranch: p.DefaultBranch,
+				Private:       p.Visibility != "public",
+				Fork:          p.ForkedFromProject != nil,
+				Archived:      p.Archived,
+			})
+		}
+	}
+
+	if hasContent(query, ContentPullRequest) {
+		// merge requests created by or assigned to the current user
+		seen := make(map[int]bool)
+		for _, scope := range []string{"created_by_me", "assigned_to_me"} {
+			mrs, err := gitlabList[gitlabIssue](client, "/merge_requests", url.Values{"state": {"opened"}, "scope": {scope}})
+			if err != nil {
+				return nil, err
+			}
+			for _, mr := range mrs {
+				if seen[mr.ID] {
+					continue
+				}
+				seen[mr.ID] = true
+				result = append(result, gitlabEntry(ContentPullRequest, mr, "!"))
+			}
+		}
+	}
+
+	if hasContent(query, ContentIssue) {
+		issues, err := gitlabList[gitlabIssue](client, "/issues", url.Values{"state": {"opened"}, "scope": {"assigned_to_me"}})
+		if err != nil {
+			return nil, err
+		}
+		for _, issue := range issues {
+			result = append(result, gitlabEntry(ContentIssue, issue, "#"))
+		}
+	}
+
+	return result, nil
+}
+
+func gitlabEntry(kind Content, issue gitlabIssue, separator string) entry {
+	// references.full: group/subgroup/project!12
+	path, _, _ := strings.Cut(issue.References.Full, separator)
+	owner, repo := path, ""
+	if idx := strings.LastIndex(path, "/"); idx != -1 {
+		owner, repo = path[:idx], path[idx+1:]
+	}
+
+	return entry{
+		Kind:   kind,
+		Owner:  owner,
+		Repo:   repo,
+		Number: issue.IID,
+		Title:  issue.Title,
+		Web:    issue.WebURL,
+		Author: issue.Author.Username,
+		Labels: issue.Labels,
+		Branch: issue.SourceBranch,
+		Draft:  issue.Draft,
+	}
+}
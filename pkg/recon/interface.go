@@ -3,6 +3,7 @@ package recon
 import (
 	"errors"
 	"fmt"
+	"github.com/PhilippHeuer/fuzzmux/pkg/secret"
 	"github.com/PhilippHeuer/fuzzmux/pkg/util"
 	"os"
 	"strings"
@@ -11,17 +12,19 @@ import (
 )
 
 type Option struct {
-	ProviderName   string            `json:"provider_name"`   // module name
-	ProviderType   string            `json:"provider_type"`   // module type
-	Id             string            `json:"id"`              // unique id
-	DisplayName    string            `json:"display_name"`    // display name for the fuzzy finder
-	Name           string            `json:"name"`            // name
-	Description    string            `json:"description"`     // description
-	Web            string            `json:"web"`             // web url
-	StartDirectory string            `json:"start_directory"` // sets the initial working directory
-	Tags           []string          `json:"tags"`            // tags
-	Context        map[string]string `json:"context"`         // additional context information
-	ModuleContext  map[string]string `json:"module_context"`  // internal context information, not exposed to the user
+	ProviderName   string            `json:"provider_name"`     // module name
+	ProviderType   string            `json:"provider_type"`     // module type
+	Id             string            `json:"id"`                // unique id
+	DisplayName    string            `json:"display_name"`      // display name for the fuzzy finder
+	Name           string            `json:"name"`              // name
+	Description    string            `json:"description"`       // description
+	Web            string            `json:"web"`               // web url
+	StartDirectory string            `json:"start_directory"`   // sets the initial working directory
+	Tags           []string          `json:"tags"`              // tags
+	Context        map[string]string `json:"context"`           // additional context information
+	ModuleContext  map[string]string `json:"module_context"`    // internal context information, not exposed to the user
+	Secrets        []string          `json:"secrets,omitempty"` // names of the environment variables with credentials, resolved by the module at launch
+	Preview        bool              `json:"-"`                 // set while SelectOption runs to render a preview, modules should skip expensive lookups
 }
 
 func (o *Option) ResolveStartDirectory(full bool) string {
@@ -84,79 +87,6 @@ func (o *Option) ResolvePlaceholders(input string) string {
 	return input
 }
 
-func (o *Option) RenderPreview() string {
-	var builder strings.Builder
-	builder.WriteString("# " + o.DisplayName + "\n\n")
-	builder.WriteString(fmt.Sprintf("Provider: %s [TYPE: %s]\n", o.ProviderName, o.ProviderType))
-	builder.WriteString("Directory: " + o.ResolveStartDirectory(true) + " [" + o.StartDirectory + "]\n")
-	if len(o.Tags) > 0 {
-		builder.WriteString("\nTags:\n")
-		for _, t := range o.Tags {
-			builder.WriteString("- " + t + "\n")
-		}
-	}
-
-	// TODO: custom option render logic should move into the option recon
-	switch o.ProviderName {
-	case "kubernetes":
-		builder.WriteString("\n")
-		if o.Context["clusterName"] != "" {
-			builder.WriteString(fmt.Sprintf("K8S Cluster Name: %s\n", o.Context["clusterName"]))
-		}
-		if o.Context["clusterHost"] != "" {
-			builder.WriteString(fmt.Sprintf("K8S Cluster API: %s\n", o.Context["clusterHost"]))
-		}
-		if o.Context["clusterUser"] != "" {
-			builder.WriteString(fmt.Sprintf("K8S Cluster User: %s\n", o.Context["clusterUser"]))
-		}
-		if o.Context["clusterType"] != "" {
-			builder.WriteString(fmt.Sprintf("K8S Cluster Type: %s\n", o.Context["clusterType"]))
-		}
-	case "usql":
-		builder.WriteString("\n")
-		if o.Context["name"] != "" {
-			builder.WriteString(fmt.Sprintf("Name: %s\n", o.Context["name"]))
-		}
-		if o.Context["hostname"] != "" {
-			builder.WriteString(fmt.Sprintf("DB Host: %s\n", o.Context["hostname"]))
-		}
-		if o.Context["port"] != "" {
-			builder.WriteString(fmt.Sprintf("DB Port: %s\n", o.Context["port"]))
-		}
-		if o.Context["username"] != "" {
-			builder.WriteString(fmt.Sprintf("DB Username: %s\n", o.Context["username"]))
-		}
-		if o.Context["instance"] != "" {
-			builder.WriteString(fmt.Sprintf("DB Instance/SID: %s\n", o.Context["instance"]))
-		}
-		if o.Context["database"] != "" {
-			builder.WriteString(fmt.Sprintf("DB Database: %s\n", o.Context["database"]))
-		}
-	default:
-		builder.WriteString("\n")
-		if len(o.Context) > 0 {
-			builder.WriteString("Context:\n")
-			for k, v := range o.Context {
-				builder.WriteString(fmt.Sprintf("  %s: %s\n", k, v))
-			}
-		}
-	}
-
-	// web url
-	if o.Web != "" {
-		builder.WriteString("\nURL: " + o.Web + "\n")
-	}
-
-	// free-text description (with fallback to context)
-	if o.Description != "" {
-		builder.WriteString("\n\n" + o.Description + "\n")
-	} else if o.Context["description"] != "" {
-		builder.WriteString("\n\n" + o.Context["description"] + "\n")
-	}
-
-	return builder.String()
-}
-
 func (o *Option) ProcessUserTemplateStrings(displayNameTemplate string, startDirectoryTemplate string) {
 	if startDirectoryTemplate != "" {
 		o.StartDirectory = o.ResolvePlaceholders(startDirectoryTemplate)
@@ -181,6 +111,59 @@ type Module interface {
 	Columns() []Column                               // Columns returns the columns for a tabular view
 }
 
+// PrepareModule is implemented by modules that need to run side effects before the option is opened, e.g. cloning a repository
+// SelectOption is also used to render previews, so it should only enrich the option
+type PrepareModule interface {
+	PrepareOption(option *Option) error // PrepareOption is called once the option has been selected to be opened
+}
+
+// Action is an operation that can be run on an option, e.g. assigning a ticket
+type Action struct {
+	Id     string // Id identifies the action
+	Name   string // Name is shown in the finder
+	Prompt string // Prompt is shown to ask for user input, empty if the action doesn't require input
+}
+
+// ActionModule is implemented by modules that provide actions for their options
+type ActionModule interface {
+	Actions(option *Option) ([]Action, error)                      // Actions returns the available actions for the option
+	RunAction(option *Option, actionId string, input string) error // RunAction runs the action and updates the option
+}
+
+// Drilldown is a list of related options, e.g. the members of a group
+type Drilldown struct {
+	Id   string // Id identifies the drilldown
+	Name string // Name is shown in the finder
+}
+
+// DrilldownModule is implemented by modules with options that reference other options, which can be chosen in a follow-up finder
+type DrilldownModule interface {
+	Drilldowns(option *Option) []Drilldown                                 // Drilldowns returns the available drilldowns for the option, requires SelectOption to be called first
+	DrilldownOptions(option *Option, drilldownId string) ([]Option, error) // DrilldownOptions returns the related options
+}
+
+// SecretModule is implemented by modules with options that need credentials at launch, options only reference the secrets by name so they are never cached or rendered into commands
+type SecretModule interface {
+	ResolveSecret(option *Option, name string) (string, error) // ResolveSecret returns the value of a secret listed in Option.Secrets
+}
+
+// ModuleSecretModule is implemented by secret modules that use the same secrets for all options, e.g. the ldap bind password
+// the secrets can be resolved for options that are not cached, e.g. options returned by a drilldown
+type ModuleSecretModule interface {
+	ModuleSecrets() []string // ModuleSecrets returns the names of the secrets shared by all options
+}
+
+// PreviewModule is implemented by modules that render module specific details in the preview, e.g. the cluster of a kubernetes context
+type PreviewModule interface {
+	Preview(option *Option) string // Preview returns the details shown between the tags and the url, replaces the context listing
+}
+
+// InputModule is implemented by modules with options that require user input after the selection, e.g. the url of a repository to clone
+type InputModule interface {
+	InputPrompt(option *Option) string             // InputPrompt returns the prompt for the user, empty if the option does not require input
+	ApplyInput(option *Option, input string) error // ApplyInput runs the action of the option and updates it with the result
+}
+
 func DefaultColumns() []Column {
 	return []Column{
 		{Key: "module", Name: "Module"},
@@ -189,3 +172,46 @@ func DefaultColumns() []Column {
 		{Key: "display_name", Name: "Display Name"},
 	}
 }
+
+// SecretOptionById returns the option with the given id, modules with shared secrets return an option with only the id and secrets if it's not cached
+func SecretOptionById(module Module, options []Option, id string) (*Option, error) {
+	option, err := OptionById(options, id)
+	if err == nil {
+		return option, nil
+	}
+
+	sm, ok := module.(ModuleSecretModule)
+	if !ok {
+		return nil, err
+	}
+	return &Option{
+		ProviderName: module.Name(),
+		ProviderType: module.Type(),
+		Id:           id,
+		Secrets:      sm.ModuleSecrets(),
+	}, nil
+}
+
+// SecretEnv resolves the secrets of the option and returns them as environment variables (NAME=value)
+func SecretEnv(module Module, option *Option) ([]string, error) {
+	if len(option.Secrets) == 0 {
+		return nil, nil
+	}
+
+	sm, ok := module.(SecretModule)
+	if !ok {
+		return nil, fmt.Errorf("module %s does not support secrets", module.Name())
+	}
+
+	var env []string
+	for _, name := range option.Secrets {
+		value, err := sm.ResolveSecret(option, name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve secret %s: %w", name, err)
+		}
+		secret.MarkSensitive(value)
+		env = append(env, name+"="+value)
+	}
+
+	return env, nil
+}
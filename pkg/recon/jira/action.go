@@ -0,0 +1,154 @@
+package jira
+
+import (
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	"github.com/PhilippHeuer/fuzzmux/pkg/recon"
+	"github.com/PhilippHeuer/fuzzmux/pkg/util"
+	"github.com/andygrunwald/go-jira"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	actionAssign     = "assign"
+	actionComment    = "comment"
+	actionBranch     = "branch"
+	actionTransition = "transition:"
+)
+
+const defaultBranchTemplate = "{{key}}-{{summary}}"
+
+var branchInvalidChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Actions returns the available actions, the transitions depend on the current status of the issue
+func (p Module) Actions(option *recon.Option) ([]recon.Action, error) {
+	conn, err := p.connection()
+	if err != nil {
+		return nil, err
+	}
+	client, err := conn.client()
+	if err != nil {
+		return nil, err
+	}
+
+	transitions, _, err := client.Issue.GetTransitions(option.Id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query transitions of %s: %w", option.Id, err)
+	}
+
+	var actions []recon.Action
+	for _, t := range transitions {
+		actions = append(actions, recon.Action{Id: actionTransition + t.ID, Name: fmt.Sprintf("Transition to %s (%s)", t.To.Name, t.Name)})
+	}
+	actions = append(actions,
+		recon.Action{Id: actionAssign, Name: "Assign to me"},
+		recon.Action{Id: actionComment, Name: "Add comment", Prompt: "Comment"},
+	)
+	if dir := p.branchProject(option); dir != "" {
+		actions = append(actions, recon.Action{Id: actionBranch, Name: fmt.Sprintf("Create branch %s in %s", p.branchName(option), dir)})
+	}
+
+	return actions, nil
+}
+
+// RunAction runs the action against the Jira REST API and refreshes the option
+func (p Module) RunAction(option *recon.Option, actionId string, input string) error {
+	conn, err := p.connection()
+	if err != nil {
+		return err
+	}
+	client, err := conn.client()
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case strings.HasPrefix(actionId, actionTransition):
+		_, err = client.Issue.DoTransition(option.Id, strings.TrimPrefix(actionId, actionTransition))
+	case actionId == actionAssign:
+		var self *jira.User
+		self, _, err = client.User.GetSelf()
+		if err != nil {
+			return fmt.Errorf("failed to query current user: %w", err)
+		}
+		// jira server uses the name, jira cloud the account id
+		_, err = client.Issue.UpdateAssignee(option.Id, &jira.User{Name: self.Name, AccountID: self.AccountID})
+	case actionId == actionComment:
+		if strings.TrimSpace(input) == "" {
+			return fmt.Errorf("comment must not be empty")
+		}
+		_, _, err = client.Issue.AddComment(option.Id, &jira.Comment{Body: input})
+	case actionId == actionBranch:
+		return p.createBranch(option)
+	default:
+		return fmt.Errorf("unknown action %q", actionId)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to run action %s on %s: %w", actionId, option.Id, err)
+	}
+
+	// refresh the option, e.g. the new status or assignee
+	i, names, err := getIssue(client, conn.APIVersion, option.Id)
+	if err != nil {
+		return fmt.Errorf("failed to refresh %s: %w", option.Id, err)
+	}
+	summary := fieldString(i.Fields, "summary")
+	option.DisplayName = fmt.Sprintf("%s: %s", i.Key, summary)
+	option.Description = summary
+	option.Context = recon.AttributeMapping(issueAttributes(i, names), p.Config.AttributeMapping)
+	option.ProcessUserTemplateStrings(p.Config.DisplayName, "")
+
+	return nil
+}
+
+// branchProject returns the local project directory of the issue's Jira project
+func (p Module) branchProject(option *recon.Option) string {
+	dir := p.Config.BranchProjects[option.ModuleContext["jiraProjectKey"]]
+	if dir == "" {
+		return ""
+	}
+	return util.ResolvePath(dir)
+}
+
+// branchName renders the branch template, the summary is converted to a slug
+func (p Module) branchName(option *recon.Option) string {
+	template := p.Config.BranchTemplate
+	if template == "" {
+		template = defaultBranchTemplate
+	}
+
+	summary := strings.Trim(branchInvalidChars.ReplaceAllString(strings.ToLower(option.Description), "-"), "-")
+	if len(summary) > 50 {
+		summary = strings.TrimRight(summary[:50], "-")
+	}
+
+	name := util.ExpandPlaceholders(template, "key", option.Id)
+	name = util.ExpandPlaceholders(name, "summary", summary)
+	return name
+}
+
+func (p Module) createBranch(option *recon.Option) error {
+	dir := p.branchProject(option)
+	if dir == "" {
+		return fmt.Errorf("no project configured for %s", option.ModuleContext["jiraProjectKey"])
+	}
+	branch := p.branchName(option)
+
+	// switch to the branch if it already exists
+	args := []string{"-C", dir, "switch", "-c", branch}
+	if exec.Command("git", "-C", dir, "rev-parse", "--verify", "--quiet", "refs/heads/"+branch).Run() == nil {
+		args = []string{"-C", dir, "switch", branch}
+	}
+
+	log.Info().Str("branch", branch).Str("directory", dir).Msg("switching to branch")
+	out, err := exec.Command("git", args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to switch to branch %s: %w: %s", branch, err, strings.TrimSpace(string(out)))
+	}
+
+	option.StartDirectory = dir
+	return nil
+}
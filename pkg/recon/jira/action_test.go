@@ -0,0 +1,95 @@
+package jira
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/PhilippHeuer/fuzzmux/pkg/recon"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestActions(t *testing.T) {
+	status := "Open"
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		requests = append(requests, r.Method+" "+r.URL.Path+" "+string(body))
+
+		switch r.Method + " " + r.URL.Path {
+		case "GET /rest/api/2/issue/DEV-1/transitions":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"transitions": []map[string]interface{}{{"id": "21", "name": "Start", "to": map[string]string{"name": "In Progress"}}},
+			})
+		case "POST /rest/api/2/issue/DEV-1/transitions":
+			status = "In Progress"
+			w.WriteHeader(http.StatusNoContent)
+		case "GET /rest/api/2/myself":
+			_ = json.NewEncoder(w).Encode(map[string]string{"name": "jane", "accountId": "abc"})
+		case "PUT /rest/api/2/issue/DEV-1/assignee":
+			w.WriteHeader(http.StatusNoContent)
+		case "POST /rest/api/2/issue/DEV-1/comment":
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"id":"1"}`))
+		case "GET /rest/api/2/issue/DEV-1":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"key": "DEV-1",
+				"fields": map[string]interface{}{
+					"summary": "Fix the login",
+					"project": map[string]string{"key": "DEV", "name": "Development"},
+					"status":  map[string]string{"name": status},
+				},
+			})
+		default:
+			t.Logf("unexpected request: %s %s", r.Method, r.URL.String())
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	module := NewModule(ModuleConfig{Host: server.URL, BearerToken: "secret", BranchProjects: map[string]string{"DEV": t.TempDir()}})
+	option := recon.Option{
+		ProviderName:  "jira",
+		Id:            "DEV-1",
+		Description:   "Fix the login",
+		Context:       map[string]string{"status": "Open"},
+		ModuleContext: map[string]string{"jiraProjectKey": "DEV"},
+	}
+
+	actions, err := module.Actions(&option)
+	require.NoError(t, err)
+	var ids []string
+	for _, a := range actions {
+		ids = append(ids, a.Id)
+	}
+	assert.Equal(t, []string{"transition:21", "assign", "comment", "branch"}, ids)
+
+	// transition refreshes the option
+	require.NoError(t, module.RunAction(&option, "transition:21", ""))
+	assert.Equal(t, "In Progress", option.Context["status"])
+	assert.Equal(t, "DEV-1: Fix the login", option.DisplayName)
+
+	// assign uses the current user
+	require.NoError(t, module.RunAction(&option, "assign", ""))
+	assert.Contains(t, requests, `PUT /rest/api/2/issue/DEV-1/assignee {"accountId":"abc","name":"jane","avatarUrls":{}}`+"\n")
+
+	// comment requires input
+	assert.Error(t, module.RunAction(&option, "comment", " "))
+	require.NoError(t, module.RunAction(&option, "comment", "done"))
+
+	assert.Error(t, module.RunAction(&option, "unknown", ""))
+}
+
+func TestBranchName(t *testing.T) {
+	option := recon.Option{Id: "DEV-1", Description: "Fix the login (SSO) flow!"}
+
+	assert.Equal(t, "DEV-1-fix-the-login-sso-flow", NewModule(ModuleConfig{}).branchName(&option))
+	assert.Equal(t, "feature/DEV-1", NewModule(ModuleConfig{BranchTemplate: "feature/{{key}}"}).branchName(&option))
+}
@@ -0,0 +1,50 @@
+package jira
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/andygrunwald/go-jira"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/oauth2"
+)
+
+const (
+	apiVersion2 = "2"
+	apiVersion3 = "3"
+)
+
+// connection holds the server and credentials, credentials are never stored in the option
+type connection struct {
+	Host        string
+	BearerToken string
+	Username    string
+	APIToken    string
+	APIVersion  string
+}
+
+// moduleContext returns the server information stored in the option, without credentials
+func (c connection) moduleContext() map[string]string {
+	return map[string]string{
+		"jiraServer":     c.Host,
+		"jiraApiVersion": c.APIVersion,
+	}
+}
+
+func (c connection) client() (*jira.Client, error) {
+	// httpClient
+	var httpClient *http.Client
+	if c.Username != "" && c.APIToken != "" {
+		log.Debug().Str("username", c.Username).Msg("using basic authentication for jira authentication")
+		httpClient = (&jira.BasicAuthTransport{Username: c.Username, Password: c.APIToken}).Client()
+	} else if c.BearerToken != "" {
+		log.Debug().Msg("using bearer token for jira authentication")
+		httpClient = oauth2.NewClient(context.Background(), oauth2.StaticTokenSource(&oauth2.Token{
+			AccessToken: c.BearerToken,
+			TokenType:   "Bearer",
+		}))
+	}
+
+	log.Debug().Str("host", c.Host).Str("api-version", c.APIVersion).Msg("connecting to jira")
+	return jira.NewClient(httpClient, c.Host)
+}
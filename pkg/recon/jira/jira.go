@@ -1,20 +1,20 @@
 package jira
 
 import (
-	"context"
 	"fmt"
-	"net/http"
+	"net/url"
+	"strings"
 
 	"github.com/PhilippHeuer/fuzzmux/pkg/recon"
 	"github.com/PhilippHeuer/fuzzmux/pkg/types"
 	"github.com/PhilippHeuer/fuzzmux/pkg/util"
-	"github.com/andygrunwald/go-jira"
-	"github.com/rs/zerolog/log"
-	"golang.org/x/oauth2"
 )
 
 const moduleType = "jira"
 
+// secretAPIToken is the environment variable of the token, it's read by jira-cli
+const secretAPIToken = "JIRA_API_TOKEN"
+
 type Module struct {
 	Config ModuleConfig
 }
@@ -29,22 +29,86 @@ type ModuleConfig struct {
 	// StartDirectory is a template string that defines the start directory
 	StartDirectory string `yaml:"start-directory"`
 
-	// Host is the Backstage hostname or IP address
+	// Host is the Jira url, e.g. https://example.atlassian.net
 	Host string `yaml:"host"`
 
-	// BearerToken is the token used to authenticate against the Backstage API (see https://backstage.io/docs/auth/service-to-service-auth/#static-tokens)
+	// BearerToken is the personal access token used to authenticate against Jira Server / Data Center
 	BearerToken string `yaml:"bearer-token,omitempty"`
 
+	// Username is the email (Jira Cloud) or username used for basic authentication
+	Username string `yaml:"username"`
+
+	// APIToken is the api token (Jira Cloud) or password used for basic authentication
+	APIToken string `yaml:"api-token,omitempty"`
+
+	// APIVersion is the version of the REST API used for the search, defaults to 3 for Jira Cloud (*.atlassian.net) and 2 otherwise
+	APIVersion string `yaml:"api-version"`
+
 	// AttributeMapping is a list of field mappings used to map additional attributes to context fields
 	AttributeMapping []types.FieldMapping `yaml:"attribute-mapping"`
 
 	// Jql is the Jira Query Language query to filter issues
 	Jql string `yaml:"jql"`
+
+	// BranchProjects maps Jira project keys to local project directories, used by the create branch action
+	BranchProjects map[string]string `yaml:"branch-projects"`
+
+	// BranchTemplate is the name of branches created by the create branch action, defaults to {{key}}-{{summary}}
+	BranchTemplate string `yaml:"branch-template"`
+}
+
+func (c *ModuleConfig) DecodeConfig() error {
+	return util.ResolveCredentialValues(&c.Host, &c.BearerToken, &c.Username, &c.APIToken)
+}
+
+func (c *ModuleConfig) connection() connection {
+	apiVersion := c.APIVersion
+	if apiVersion == "" {
+		apiVersion = apiVersion2
+		if u, err := url.Parse(c.Host); err == nil && strings.HasSuffix(u.Hostname(), ".atlassian.net") {
+			apiVersion = apiVersion3
+		}
+	}
+
+	return connection{
+		Host:        c.Host,
+		BearerToken: c.BearerToken,
+		Username:    c.Username,
+		APIToken:    c.APIToken,
+		APIVersion:  apiVersion,
+	}
 }
 
-func (c *ModuleConfig) DecodeConfig() {
-	c.Host = util.ResolveCredentialValue(c.Host)
-	c.BearerToken = util.ResolveCredentialValue(c.BearerToken)
+// connection resolves the credentials of the module config
+func (p Module) connection() (connection, error) {
+	if err := p.Config.DecodeConfig(); err != nil {
+		return connection{}, fmt.Errorf("failed to resolve credentials: %w", err)
+	}
+	return p.Config.connection(), nil
+}
+
+// secrets returns the secrets passed to the layout, the token is only available if configured
+func (p Module) secrets() []string {
+	if p.Config.BearerToken == "" && p.Config.APIToken == "" {
+		return nil
+	}
+	return []string{secretAPIToken}
+}
+
+// ResolveSecret resolves the token used by jira-cli when the option is launched, the personal access token takes precedence
+func (p Module) ResolveSecret(option *recon.Option, name string) (string, error) {
+	if name != secretAPIToken {
+		return "", fmt.Errorf("unknown secret %s", name)
+	}
+
+	conn, err := p.connection()
+	if err != nil {
+		return "", err
+	}
+	if conn.BearerToken != "" {
+		return conn.BearerToken, nil
+	}
+	return conn.APIToken, nil
 }
 
 func (p Module) Name() string {
@@ -59,87 +123,48 @@ func (p Module) Type() string {
 }
 
 func (p Module) Options() ([]recon.Option, error) {
-	p.Config.DecodeConfig()
 	var result []recon.Option
 
-	// httpClient
-	var httpClient *http.Client
-	if p.Config.BearerToken != "" {
-		log.Debug().Msg("using bearer token for jira authentication")
-		httpClient = oauth2.NewClient(context.Background(), oauth2.StaticTokenSource(&oauth2.Token{
-			AccessToken: p.Config.BearerToken,
-			TokenType:   "Bearer",
-		}))
-	}
-
 	// connect
-	log.Debug().Str("host", p.Config.Host).Msg("connecting to jira")
-	jiraClient, err := jira.NewClient(httpClient, p.Config.Host)
+	conn, err := p.connection()
+	if err != nil {
+		return nil, err
+	}
+	jiraClient, err := conn.client()
 	if err != nil {
 		return nil, err
 	}
 
 	// query tickets
-	startAt := 0
-	maxResults := 1000
-
-	for {
-		log.Debug().Str("jql", p.Config.Jql).Int("startAt", startAt).Msg("querying Jira issues")
-		issues, _, err := jiraClient.Issue.Search(p.Config.Jql, &jira.SearchOptions{StartAt: startAt, MaxResults: maxResults})
-		if err != nil {
-			return nil, err
-		}
-		if len(issues) == 0 {
-			break
-		}
+	issues, names, err := searchIssues(jiraClient, conn.APIVersion, p.Config.Jql)
+	if err != nil {
+		return nil, err
+	}
 
-		for _, issue := range issues {
-			entryAttributes := map[string]interface{}{
-				"project": issue.Fields.Project.Name,
-				"summary": issue.Fields.Summary,
-				"type":    issue.Fields.Type.Name,
-			}
-			if issue.Fields.Status != nil {
-				entryAttributes["status"] = issue.Fields.Status.Name
-			}
-			if issue.Fields.Priority != nil {
-				entryAttributes["priority"] = issue.Fields.Priority.Name
-			}
-			if issue.Fields.Assignee != nil {
-				entryAttributes["assignee"] = issue.Fields.Assignee.Name
-			}
-			if issue.Fields.Reporter != nil {
-				entryAttributes["reporter"] = issue.Fields.Reporter.Name
-			}
-			if issue.Fields.Sprint != nil {
-				entryAttributes["sprint.id"] = issue.Fields.Sprint.ID
-				entryAttributes["sprint.name"] = issue.Fields.Sprint.Name
-				entryAttributes["sprint.startedAt"] = issue.Fields.Sprint.StartDate
-				entryAttributes["sprint.endedAt"] = issue.Fields.Sprint.EndDate
-			}
-
-			attributes := recon.AttributeMapping(entryAttributes, p.Config.AttributeMapping)
-
-			opt := recon.Option{
-				ProviderName:   p.Name(),
-				ProviderType:   p.Type(),
-				Id:             issue.Key,
-				DisplayName:    fmt.Sprintf("%s: %s", issue.Key, issue.Fields.Summary),
-				Name:           issue.Key,
-				Description:    issue.Fields.Summary,
-				Web:            fmt.Sprintf("%s/browse/%s", p.Config.Host, issue.Key),
-				StartDirectory: "~",
-				Tags:           []string{"jira", "ticket"},
-				Context:        attributes,
-				ModuleContext: map[string]string{
-					"jiraServer":      p.Config.Host,
-					"jiraBearerToken": p.Config.BearerToken,
-				},
-			}
-			opt.ProcessUserTemplateStrings(p.Config.DisplayName, p.Config.StartDirectory)
-			result = append(result, opt)
+	for _, issue := range issues {
+		entryAttributes := issueAttributes(issue, names)
+		attributes := recon.AttributeMapping(entryAttributes, p.Config.AttributeMapping)
+
+		moduleContext := conn.moduleContext()
+		moduleContext["jiraProjectKey"] = fieldString(issue.Fields["project"], "key")
+
+		summary := fieldString(issue.Fields, "summary")
+		opt := recon.Option{
+			ProviderName:   p.Name(),
+			ProviderType:   p.Type(),
+			Id:             issue.Key,
+			DisplayName:    fmt.Sprintf("%s: %s", issue.Key, summary),
+			Name:           issue.Key,
+			Description:    summary,
+			Web:            fmt.Sprintf("%s/browse/%s", strings.TrimSuffix(conn.Host, "/"), issue.Key),
+			StartDirectory: "~",
+			Tags:           []string{"jira", "ticket"},
+			Context:        attributes,
+			ModuleContext:  moduleContext,
+			Secrets:        p.secrets(),
 		}
-		startAt += len(issues)
+		opt.ProcessUserTemplateStrings(p.Config.DisplayName, p.Config.StartDirectory)
+		result = append(result, opt)
 	}
 
 	return result, nil
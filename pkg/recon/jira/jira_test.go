@@ -0,0 +1,154 @@
+package jira
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/PhilippHeuer/fuzzmux/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testIssue(key string, summary string) map[string]interface{} {
+	return map[string]interface{}{
+		"key": key,
+		"fields": map[string]interface{}{
+			"summary":           summary,
+			"project":           map[string]string{"key": "DEV", "name": "Development"},
+			"issuetype":         map[string]string{"name": "Story"},
+			"status":            map[string]string{"name": "Open"},
+			"assignee":          map[string]string{"accountId": "abc", "displayName": "Jane Doe"},
+			"labels":            []string{"backend", "auth"},
+			"components":        []map[string]string{{"name": "api"}},
+			"customfield_10016": 5,
+			"customfield_10014": "DEV-100",
+			"customfield_10020": []map[string]interface{}{{"id": 1, "name": "Sprint 1"}},
+			"customfield_10030": map[string]string{"value": "High"},
+			"customfield_10040": nil,
+		},
+	}
+}
+
+func TestOptionsCloud(t *testing.T) {
+	var tokens []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, ok := r.BasicAuth()
+		if !ok || username != "jane@example.com" || password != "token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.Method != http.MethodPost || r.URL.Path != "/rest/api/3/search/jql" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		var request map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		assert.Equal(t, "project = DEV", request["jql"])
+		token, _ := request["nextPageToken"].(string)
+		tokens = append(tokens, token)
+
+		names := map[string]string{"customfield_10016": "Story Points", "customfield_10014": "Epic Link", "customfield_10020": "Sprint"}
+		if token == "" {
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"issues": []interface{}{testIssue("DEV-1", "Fix login")}, "names": names, "nextPageToken": "page2"})
+		} else {
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"issues": []interface{}{testIssue("DEV-2", "Add logout")}, "names": names, "isLast": true})
+		}
+	}))
+	defer server.Close()
+
+	module := NewModule(ModuleConfig{
+		Host:       server.URL,
+		Username:   "jane@example.com",
+		APIToken:   "token",
+		APIVersion: "3",
+		Jql:        "project = DEV",
+		AttributeMapping: []types.FieldMapping{
+			{Source: "key", Target: "key"},
+			{Source: "status", Target: "status"},
+			{Source: "assignee", Target: "assignee"},
+			{Source: "labels", Target: "labels"},
+			{Source: "components", Target: "components"},
+			{Source: "Story Points", Target: "storyPoints"},
+			{Source: "Epic Link", Target: "epic"},
+			{Source: "customfield_10020", Target: "sprint"},
+			{Source: "customfield_10030", Target: "severity"},
+			{Source: "customfield_10040", Target: "empty"},
+		},
+	})
+	options, err := module.Options()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"", "page2"}, tokens)
+	require.Len(t, options, 2)
+
+	opt := options[0]
+	assert.Equal(t, "DEV-1: Fix login", opt.DisplayName)
+	assert.Equal(t, server.URL+"/browse/DEV-1", opt.Web)
+	assert.Equal(t, map[string]string{
+		"key":         "DEV-1",
+		"status":      "Open",
+		"assignee":    "Jane Doe",
+		"labels":      "backend, auth",
+		"components":  "api",
+		"storyPoints": "5",
+		"epic":        "DEV-100",
+		"sprint":      "Sprint 1",
+		"severity":    "High",
+	}, opt.Context)
+	assert.Equal(t, "DEV", opt.ModuleContext["jiraProjectKey"])
+	assert.Equal(t, "3", opt.ModuleContext["jiraApiVersion"])
+	assert.NotContains(t, opt.ModuleContext, "jiraApiToken")
+	assert.Equal(t, []string{"JIRA_API_TOKEN"}, opt.Secrets)
+
+	token, err := module.ResolveSecret(&opt, "JIRA_API_TOKEN")
+	require.NoError(t, err)
+	assert.Equal(t, "token", token)
+}
+
+func TestOptionsServer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.Method != http.MethodPost || r.URL.Path != "/rest/api/2/search" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		var request map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		issues := []interface{}{testIssue("DEV-1", "Fix login")}
+		if request["startAt"].(float64) > 0 {
+			issues = []interface{}{testIssue("DEV-2", "Add logout")}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"issues": issues, "startAt": request["startAt"], "total": 2})
+	}))
+	defer server.Close()
+
+	// host and token are resolved from references
+	t.Setenv("FUZZMUX_TEST_JIRA_HOST", server.URL)
+	t.Setenv("FUZZMUX_TEST_JIRA_TOKEN", "secret")
+	module := NewModule(ModuleConfig{Host: "env:FUZZMUX_TEST_JIRA_HOST", BearerToken: "env:FUZZMUX_TEST_JIRA_TOKEN"})
+	options, err := module.Options()
+	require.NoError(t, err)
+	require.Len(t, options, 2)
+	assert.Equal(t, "DEV-2", options[1].Id)
+	assert.Equal(t, server.URL+"/browse/DEV-1", options[0].Web)
+	assert.Equal(t, server.URL, options[0].ModuleContext["jiraServer"])
+	assert.Equal(t, "Development", options[0].Context["project"])
+	assert.Equal(t, "5", options[0].Context["customfield_10016"])
+
+	// invalid credentials
+	module.Config.BearerToken = "invalid"
+	_, err = module.Options()
+	assert.ErrorContains(t, err, "401")
+}
+
+func TestConnectionAPIVersion(t *testing.T) {
+	assert.Equal(t, "3", (&ModuleConfig{Host: "https://example.atlassian.net"}).connection().APIVersion)
+	assert.Equal(t, "2", (&ModuleConfig{Host: "https://jira.example.com"}).connection().APIVersion)
+	assert.Equal(t, "2", (&ModuleConfig{Host: "https://example.atlassian.net", APIVersion: "2"}).connection().APIVersion)
+}
@@ -0,0 +1,236 @@
+package jira
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/andygrunwald/go-jira"
+	"github.com/rs/zerolog/log"
+)
+
+// defaultCloudJql is used for the v3 search if no jql is configured, Jira Cloud rejects unbounded queries
+const defaultCloudJql = "assignee = currentUser() AND resolution = Unresolved ORDER BY updated DESC"
+
+// issue is decoded without the typed fields of go-jira, to keep custom fields and the v3 format
+type issue struct {
+	Key    string                 `json:"key"`
+	Fields map[string]interface{} `json:"fields"`
+}
+
+type searchResponse struct {
+	Issues        []issue           `json:"issues"`
+	Names         map[string]string `json:"names"`
+	StartAt       int               `json:"startAt"`
+	Total         int               `json:"total"`
+	NextPageToken string            `json:"nextPageToken"`
+	IsLast        bool              `json:"isLast"`
+}
+
+// searchIssues queries all issues matching the jql, returns the issues and the names of all fields (e.g. customfield_10016 = Story Points)
+func searchIssues(client *jira.Client, apiVersion string, jql string) ([]issue, map[string]string, error) {
+	if apiVersion == apiVersion3 {
+		return searchIssuesV3(client, jql)
+	}
+	return searchIssuesV2(client, jql)
+}
+
+// searchIssuesV2 uses the Jira Server / Data Center search with startAt pagination
+func searchIssuesV2(client *jira.Client, jql string) ([]issue, map[string]string, error) {
+	var result []issue
+	names := make(map[string]string)
+
+	startAt := 0
+	for {
+		log.Debug().Str("jql", jql).Int("startAt", startAt).Msg("querying Jira issues")
+		var response searchResponse
+		err := doRequest(client, http.MethodPost, "rest/api/2/search", map[string]interface{}{
+			"jql":        jql,
+			"startAt":    startAt,
+			"maxResults": 1000,
+			"fields":     []string{"*all"},
+			"expand":     []string{"names"},
+		}, &response)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(response.Issues) == 0 {
+			break
+		}
+
+		result = append(result, response.Issues...)
+		for k, v := range response.Names {
+			names[k] = v
+		}
+		startAt += len(response.Issues)
+		if response.Total > 0 && startAt >= response.Total {
+			break
+		}
+	}
+
+	return result, names, nil
+}
+
+// searchIssuesV3 uses the Jira Cloud search, which replaced startAt with a nextPageToken
+func searchIssuesV3(client *jira.Client, jql string) ([]issue, map[string]string, error) {
+	var result []issue
+	names := make(map[string]string)
+	if jql == "" {
+		jql = defaultCloudJql
+	}
+
+	nextPageToken := ""
+	for {
+		log.Debug().Str("jql", jql).Str("nextPageToken", nextPageToken).Msg("querying Jira issues")
+		request := map[string]interface{}{
+			"jql":        jql,
+			"maxResults": 100,
+			"fields":     []string{"*all"},
+			"expand":     "names",
+		}
+		if nextPageToken != "" {
+			request["nextPageToken"] = nextPageToken
+		}
+
+		var response searchResponse
+		err := doRequest(client, http.MethodPost, "rest/api/3/search/jql", request, &response)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		result = append(result, response.Issues...)
+		for k, v := range response.Names {
+			names[k] = v
+		}
+		if response.IsLast || response.NextPageToken == "" {
+			break
+		}
+		nextPageToken = response.NextPageToken
+	}
+
+	return result, names, nil
+}
+
+// getIssue queries a single issue
+func getIssue(client *jira.Client, apiVersion string, key string) (issue, map[string]string, error) {
+	var response struct {
+		issue
+		Names map[string]string `json:"names"`
+	}
+	err := doRequest(client, http.MethodGet, fmt.Sprintf("rest/api/%s/issue/%s?expand=names", apiVersion, key), nil, &response)
+	if err != nil {
+		return issue{}, nil, err
+	}
+
+	return response.issue, response.Names, nil
+}
+
+func doRequest(client *jira.Client, method string, path string, body interface{}, v interface{}) error {
+	req, err := client.NewRequest(method, path, body)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.Do(req, v)
+	if err != nil {
+		if resp != nil {
+			return fmt.Errorf("jira request %s %s failed: %w", method, path, jira.NewJiraError(resp, err))
+		}
+		return fmt.Errorf("jira request %s %s failed: %w", method, path, err)
+	}
+
+	return nil
+}
+
+// issueAttributes returns the well-known fields and all custom fields, custom fields are available by id and by name
+func issueAttributes(i issue, names map[string]string) map[string]interface{} {
+	entryAttributes := map[string]interface{}{
+		"key":        i.Key,
+		"project":    fieldString(i.Fields["project"], "name"),
+		"projectKey": fieldString(i.Fields["project"], "key"),
+		"summary":    fieldString(i.Fields, "summary"),
+		"type":       fieldString(i.Fields["issuetype"], "name"),
+		"status":     fieldString(i.Fields["status"], "name"),
+		"priority":   fieldString(i.Fields["priority"], "name"),
+		"resolution": fieldString(i.Fields["resolution"], "name"),
+		"assignee":   userName(i.Fields["assignee"]),
+		"reporter":   userName(i.Fields["reporter"]),
+		"parent":     fieldString(i.Fields["parent"], "key"),
+		"labels":     fieldValue(i.Fields["labels"]),
+		"components": fieldValue(i.Fields["components"]),
+	}
+	if sprint, ok := i.Fields["sprint"].(map[string]interface{}); ok {
+		entryAttributes["sprint.id"] = fieldValue(sprint["id"])
+		entryAttributes["sprint.name"] = fieldValue(sprint["name"])
+		entryAttributes["sprint.startedAt"] = fieldValue(sprint["startDate"])
+		entryAttributes["sprint.endedAt"] = fieldValue(sprint["endDate"])
+	}
+
+	for field, value := range i.Fields {
+		if !strings.HasPrefix(field, "customfield_") {
+			continue
+		}
+
+		v := fieldValue(value)
+		if v == "" {
+			continue
+		}
+		entryAttributes[field] = v
+		if name := names[field]; name != "" {
+			entryAttributes[name] = v
+		}
+	}
+
+	// remove empty values
+	for k, v := range entryAttributes {
+		if v == "" {
+			delete(entryAttributes, k)
+		}
+	}
+
+	return entryAttributes
+}
+
+// fieldString returns a string property of a json object
+func fieldString(object interface{}, key string) string {
+	if m, ok := object.(map[string]interface{}); ok {
+		return fieldValue(m[key])
+	}
+	return ""
+}
+
+// userName returns the username (Jira Server) or the display name (Jira Cloud doesn't expose usernames)
+func userName(user interface{}) string {
+	if name := fieldString(user, "name"); name != "" {
+		return name
+	}
+	return fieldString(user, "displayName")
+}
+
+// fieldValue converts a json field value to a string, objects are represented by their value or name (e.g. select fields, components, sprints)
+func fieldValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case map[string]interface{}:
+		for _, key := range []string{"value", "name", "displayName", "key"} {
+			if s := fieldValue(v[key]); s != "" {
+				return s
+			}
+		}
+	case []interface{}:
+		var values []string
+		for _, item := range v {
+			if s := fieldValue(item); s != "" {
+				values = append(values, s)
+			}
+		}
+		return strings.Join(values, ", ")
+	}
+	return ""
+}
@@ -40,21 +40,26 @@ type ModuleConfig struct {
 
 	// Query is a list of content types that should be queried
 	Query []KeycloakContent `yaml:"query"`
+
+	// Realms limits the queried realms, defaults to all realms the user has access to
+	Realms []string `yaml:"realms"`
+
+	// PageSize is the number of entries requested per page, defaults to 100
+	PageSize int `yaml:"page-size"`
 }
 
-func (c *ModuleConfig) DecodeConfig() {
-	c.Host = util.ResolveCredentialValue(c.Host)
-	c.RealmName = util.ResolveCredentialValue(c.RealmName)
-	c.Username = util.ResolveCredentialValue(c.Username)
-	c.Password = util.ResolveCredentialValue(c.Password)
+func (c *ModuleConfig) DecodeConfig() error {
+	return util.ResolveCredentialValues(&c.Host, &c.RealmName, &c.Username, &c.Password)
 }
 
 type KeycloakContent string
 
 const (
-	KeycloakUser   KeycloakContent = "user"
-	KeycloakClient KeycloakContent = "client"
-	KeycloakGroup  KeycloakContent = "group"
+	KeycloakUser             KeycloakContent = "user"
+	KeycloakClient           KeycloakContent = "client"
+	KeycloakGroup            KeycloakContent = "group"
+	KeycloakRole             KeycloakContent = "role"
+	KeycloakIdentityProvider KeycloakContent = "identity-provider"
 )
 
 func (p Module) Name() string {
@@ -69,7 +74,9 @@ func (p Module) Type() string {
 }
 
 func (p Module) Options() ([]recon.Option, error) {
-	p.Config.DecodeConfig()
+	if err := p.Config.DecodeConfig(); err != nil {
+		return nil, fmt.Errorf("failed to resolve credentials: %w", err)
+	}
 	var result []recon.Option
 	ctx := context.Background()
 
@@ -81,99 +88,31 @@ func (p Module) Options() ([]recon.Option, error) {
 		return nil, fmt.Errorf("failed to authenticate on keycloak: %w", err)
 	}
 
-	// query realms
-	realms, err := client.GetRealms(ctx, token.AccessToken)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get realms: %w", err)
+	// realms
+	realms := p.Config.Realms
+	if len(realms) == 0 {
+		allRealms, err := client.GetRealms(ctx, token.AccessToken)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get realms: %w", err)
+		}
+		for _, realm := range allRealms {
+			realms = append(realms, ptr.Value(realm.Realm))
+		}
 	}
 
 	// query content
+	q := query{module: p, client: client, token: token.AccessToken}
 	for _, realm := range realms {
-		// clients
-		if slices.Contains(p.Config.Query, KeycloakClient) {
-			allClients, err := client.GetClients(ctx, token.AccessToken, ptr.Value(realm.Realm), gocloak.GetClientsParams{})
-			if err != nil {
-				return nil, fmt.Errorf("failed to get clients: %w", err)
+		for _, content := range []KeycloakContent{KeycloakClient, KeycloakUser, KeycloakGroup, KeycloakRole, KeycloakIdentityProvider} {
+			if !slices.Contains(p.Config.Query, content) {
+				continue
 			}
-			for _, cl := range allClients {
-				entryAttributes := attributesToMap(cl.Attributes, map[string]interface{}{
-					"enabled":      ptr.Value(cl.Enabled),
-					"clientId":     ptr.Value(cl.ClientID),
-					"rootUrl":      ptr.Value(cl.RootURL),
-					"protocol":     ptr.Value(cl.Protocol),
-					"publicClient": ptr.Value(cl.PublicClient),
-				})
-				attributes := recon.AttributeMapping(entryAttributes, p.Config.AttributeMapping)
-				attributes["type"] = "client"
-
-				result = append(result, recon.Option{
-					ProviderName: p.Name(),
-					ProviderType: p.Type(),
-					Id:           ptr.Value(cl.ID),
-					DisplayName:  fmt.Sprintf("%s [%s] @ %s", ptr.Value(cl.ClientID), "client", ptr.Value(realm.Realm)),
-					Name:         ptr.Value(cl.ClientID),
-					Description:  ptr.Value(cl.Description),
-					Web:          fmt.Sprintf("%s/admin/%s/console/#/%s/clients/%s/settings", p.Config.Host, p.Config.RealmName, ptr.Value(realm.Realm), ptr.Value(cl.ID)),
-					Tags:         []string{"keycloak", "client"},
-					Context:      attributes,
-				})
-			}
-		}
-		// users
-		if slices.Contains(p.Config.Query, KeycloakUser) {
-			users, err := client.GetUsers(ctx, token.AccessToken, ptr.Value(realm.Realm), gocloak.GetUsersParams{BriefRepresentation: ptr.False()})
-			if err != nil {
-				return nil, fmt.Errorf("failed to get users: %w", err)
-			}
-			for _, user := range users {
-				entryAttributes := attributeSlicesToMap(user.Attributes, map[string]interface{}{
-					"enabled":     ptr.Value(user.Enabled),
-					"email":       ptr.Value(user.Email),
-					"firstname":   ptr.Value(user.FirstName),
-					"lastname":    ptr.Value(user.LastName),
-					"groups":      user.Groups,
-					"realmRoles":  user.RealmRoles,
-					"clientRoles": clientRolesToString(user.ClientRoles),
-					"createdAt":   util.ConvertMilliUnixTimestampToRFC3339(user.CreatedTimestamp),
-				})
-				attributes := recon.AttributeMapping(entryAttributes, p.Config.AttributeMapping)
-				attributes["type"] = "user"
-
-				result = append(result, recon.Option{
-					ProviderName: p.Name(),
-					ProviderType: p.Type(),
-					Id:           ptr.Value(user.ID),
-					DisplayName:  fmt.Sprintf("%s [%s] @ %s", ptr.Value(user.Username), "user", ptr.Value(realm.Realm)),
-					Name:         ptr.Value(user.Username),
-					Description:  ptr.Value(user.FirstName) + " " + ptr.Value(user.LastName),
-					Web:          fmt.Sprintf("%s/admin/%s/console/#/%s/users/%s", p.Config.Host, p.Config.RealmName, ptr.Value(realm.Realm), ptr.Value(user.ID)),
-					Tags:         []string{"keycloak", "user"},
-					Context:      attributes,
-				})
-			}
-		}
-		// groups
-		if slices.Contains(p.Config.Query, KeycloakGroup) {
-			groups, err := client.GetGroups(ctx, token.AccessToken, ptr.Value(realm.Realm), gocloak.GetGroupsParams{BriefRepresentation: ptr.False()})
+
+			options, err := q.options(ctx, realm, content)
 			if err != nil {
-				return nil, fmt.Errorf("failed to get groups: %w", err)
-			}
-			for _, group := range groups {
-				entryAttributes := attributeSlicesToMap(group.Attributes, map[string]interface{}{})
-				attributes := recon.AttributeMapping(entryAttributes, p.Config.AttributeMapping)
-				attributes["type"] = "group"
-
-				result = append(result, recon.Option{
-					ProviderName: p.Name(),
-					ProviderType: p.Type(),
-					Id:           ptr.Value(group.ID),
-					DisplayName:  fmt.Sprintf("%s [%s] @ %s", ptr.Value(group.Name), "group", ptr.Value(realm.Realm)),
-					Name:         ptr.Value(group.Name),
-					Web:          fmt.Sprintf("%s/admin/%s/console/#/%s/groups/%s/settings", p.Config.Host, p.Config.RealmName, ptr.Value(realm.Realm), ptr.Value(group.ID)),
-					Tags:         []string{"keycloak", "group"},
-					Context:      attributes,
-				})
+				return nil, fmt.Errorf("failed to get %ss of realm %s: %w", content, realm, err)
 			}
+			result = append(result, options...)
 		}
 	}
 
@@ -200,6 +139,10 @@ func (p Module) Columns() []recon.Column {
 }
 
 func NewModule(config ModuleConfig) Module {
+	if config.PageSize <= 0 {
+		config.PageSize = 100
+	}
+
 	return Module{
 		Config: config,
 	}
@@ -0,0 +1,243 @@
+package keycloak
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Nerzal/gocloak/v14"
+	"github.com/PhilippHeuer/fuzzmux/pkg/recon"
+	"github.com/PhilippHeuer/fuzzmux/pkg/util"
+	"github.com/cidverse/go-ptr"
+	"github.com/rs/zerolog/log"
+)
+
+// maxGroupDepth limits the depth of the subgroup traversal
+const maxGroupDepth = 20
+
+type query struct {
+	module Module
+	client *gocloak.GoCloak
+	token  string
+	// noChildGroups is set if the server doesn't support the children endpoint (Keycloak < 23), subgroups are then only taken from the group representation
+	noChildGroups bool
+}
+
+func (q *query) config() ModuleConfig {
+	return q.module.Config
+}
+
+// options returns all entries of the given content type in the realm
+func (q *query) options(ctx context.Context, realm string, content KeycloakContent) ([]recon.Option, error) {
+	switch content {
+	case KeycloakClient:
+		return q.clientOptions(ctx, realm)
+	case KeycloakUser:
+		return q.userOptions(ctx, realm)
+	case KeycloakGroup:
+		return q.groupOptions(ctx, realm)
+	case KeycloakRole:
+		return q.roleOptions(ctx, realm)
+	case KeycloakIdentityProvider:
+		return q.identityProviderOptions(ctx, realm)
+	}
+
+	return nil, fmt.Errorf("unsupported query type: %s", content)
+}
+
+func (q *query) clientOptions(ctx context.Context, realm string) ([]recon.Option, error) {
+	clients, err := paginate(q.config().PageSize, func(first, max int) ([]*gocloak.Client, error) {
+		return q.client.GetClients(ctx, q.token, realm, gocloak.GetClientsParams{First: &first, Max: &max})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var result []recon.Option
+	for _, cl := range clients {
+		entryAttributes := attributesToMap(cl.Attributes, map[string]interface{}{
+			"enabled":      ptr.Value(cl.Enabled),
+			"clientId":     ptr.Value(cl.ClientID),
+			"rootUrl":      ptr.Value(cl.RootURL),
+			"protocol":     ptr.Value(cl.Protocol),
+			"publicClient": ptr.Value(cl.PublicClient),
+		})
+		result = append(result, q.option(realm, KeycloakClient, ptr.Value(cl.ID), ptr.Value(cl.ClientID), ptr.Value(cl.Description), fmt.Sprintf("clients/%s/settings", ptr.Value(cl.ID)), entryAttributes))
+	}
+
+	return result, nil
+}
+
+func (q *query) userOptions(ctx context.Context, realm string) ([]recon.Option, error) {
+	users, err := paginate(q.config().PageSize, func(first, max int) ([]*gocloak.User, error) {
+		return q.client.GetUsers(ctx, q.token, realm, gocloak.GetUsersParams{BriefRepresentation: ptr.False(), First: &first, Max: &max})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var result []recon.Option
+	for _, user := range users {
+		entryAttributes := attributeSlicesToMap(user.Attributes, map[string]interface{}{
+			"enabled":     ptr.Value(user.Enabled),
+			"email":       ptr.Value(user.Email),
+			"firstname":   ptr.Value(user.FirstName),
+			"lastname":    ptr.Value(user.LastName),
+			"groups":      user.Groups,
+			"realmRoles":  user.RealmRoles,
+			"clientRoles": clientRolesToString(user.ClientRoles),
+			"createdAt":   util.ConvertMilliUnixTimestampToRFC3339(user.CreatedTimestamp),
+		})
+		description := strings.TrimSpace(ptr.Value(user.FirstName) + " " + ptr.Value(user.LastName))
+		result = append(result, q.option(realm, KeycloakUser, ptr.Value(user.ID), ptr.Value(user.Username), description, fmt.Sprintf("users/%s", ptr.Value(user.ID)), entryAttributes))
+	}
+
+	return result, nil
+}
+
+func (q *query) groupOptions(ctx context.Context, realm string) ([]recon.Option, error) {
+	groups, err := paginate(q.config().PageSize, func(first, max int) ([]*gocloak.Group, error) {
+		return q.client.GetGroups(ctx, q.token, realm, gocloak.GetGroupsParams{BriefRepresentation: ptr.False(), First: &first, Max: &max})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var result []recon.Option
+	for _, group := range groups {
+		options, err := q.groupTree(ctx, realm, *group, 0)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, options...)
+	}
+
+	return result, nil
+}
+
+// groupTree returns the group and all of its subgroups
+func (q *query) groupTree(ctx context.Context, realm string, group gocloak.Group, depth int) ([]recon.Option, error) {
+	path := ptr.Value(group.Path)
+	if path == "" {
+		path = "/" + ptr.Value(group.Name)
+	}
+	entryAttributes := attributeSlicesToMap(group.Attributes, map[string]interface{}{
+		"path":        path,
+		"realmRoles":  group.RealmRoles,
+		"clientRoles": clientRolesToString(group.ClientRoles),
+	})
+	result := []recon.Option{q.option(realm, KeycloakGroup, ptr.Value(group.ID), path, "", fmt.Sprintf("groups/%s/settings", ptr.Value(group.ID)), entryAttributes)}
+	result[0].Name = ptr.Value(group.Name)
+
+	if depth >= maxGroupDepth {
+		return result, nil
+	}
+
+	// subgroups are no longer part of the group representation since Keycloak 23
+	children := group.SubGroups
+	if len(children) == 0 && !q.noChildGroups {
+		fetched, err := paginate(q.config().PageSize, func(first, max int) ([]*gocloak.Group, error) {
+			return q.client.GetChildGroups(ctx, q.token, realm, ptr.Value(group.ID), gocloak.GetChildGroupsParams{BriefRepresentation: ptr.False(), First: &first, Max: &max})
+		})
+		if err != nil {
+			log.Debug().Err(err).Str("realm", realm).Msg("failed to query child groups, using the subgroups of the group representation")
+			q.noChildGroups = true
+		}
+		for _, child := range fetched {
+			children = append(children, *child)
+		}
+	}
+
+	for _, child := range children {
+		options, err := q.groupTree(ctx, realm, child, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, options...)
+	}
+
+	return result, nil
+}
+
+func (q *query) roleOptions(ctx context.Context, realm string) ([]recon.Option, error) {
+	roles, err := paginate(q.config().PageSize, func(first, max int) ([]*gocloak.Role, error) {
+		return q.client.GetRealmRoles(ctx, q.token, realm, gocloak.GetRoleParams{BriefRepresentation: ptr.False(), First: &first, Max: &max})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var result []recon.Option
+	for _, role := range roles {
+		entryAttributes := attributeSlicesToMap(role.Attributes, map[string]interface{}{
+			"composite": ptr.Value(role.Composite),
+		})
+		result = append(result, q.option(realm, KeycloakRole, ptr.Value(role.ID), ptr.Value(role.Name), ptr.Value(role.Description), fmt.Sprintf("roles/%s/details", ptr.Value(role.ID)), entryAttributes))
+	}
+
+	return result, nil
+}
+
+func (q *query) identityProviderOptions(ctx context.Context, realm string) ([]recon.Option, error) {
+	// the identity provider endpoint is not paginated
+	providers, err := q.client.GetIdentityProviders(ctx, q.token, realm)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []recon.Option
+	for _, idp := range providers {
+		entryAttributes := attributesToMap(nil, map[string]interface{}{
+			"enabled":     ptr.Value(idp.Enabled),
+			"alias":       ptr.Value(idp.Alias),
+			"providerId":  ptr.Value(idp.ProviderID),
+			"trustEmail":  ptr.Value(idp.TrustEmail),
+			"linkOnly":    ptr.Value(idp.LinkOnly),
+			"hideOnLogin": ptr.Value(idp.HideOnLogin),
+		})
+		for _, key := range []string{"issuer", "authorizationUrl", "clientId", "singleSignOnServiceUrl"} {
+			if value := idp.Config[key]; value != "" {
+				entryAttributes[key] = value
+			}
+		}
+		result = append(result, q.option(realm, KeycloakIdentityProvider, ptr.Value(idp.Alias), ptr.Value(idp.Alias), ptr.Value(idp.DisplayName), fmt.Sprintf("identity-providers/%s/%s/settings", ptr.Value(idp.ProviderID), ptr.Value(idp.Alias)), entryAttributes))
+	}
+
+	return result, nil
+}
+
+// option creates the option for an entry, the id is prefixed with the realm and content type because entity ids are only unique within a realm
+func (q *query) option(realm string, content KeycloakContent, id string, name string, description string, consolePath string, entryAttributes map[string]interface{}) recon.Option {
+	conf := q.config()
+	attributes := recon.AttributeMapping(entryAttributes, conf.AttributeMapping)
+	attributes["type"] = string(content)
+	attributes["realm"] = realm
+
+	return recon.Option{
+		ProviderName: q.module.Name(),
+		ProviderType: q.module.Type(),
+		Id:           fmt.Sprintf("%s/%s/%s", realm, content, id),
+		DisplayName:  fmt.Sprintf("%s [%s] @ %s", name, content, realm),
+		Name:         name,
+		Description:  description,
+		Web:          fmt.Sprintf("%s/admin/%s/console/#/%s/%s", conf.Host, conf.RealmName, realm, consolePath),
+		Tags:         []string{"keycloak", string(content)},
+		Context:      attributes,
+	}
+}
+
+// paginate requests pages using first and max until a page is not full
+func paginate[T any](pageSize int, fetch func(first, max int) ([]T, error)) ([]T, error) {
+	var result []T
+	for first := 0; ; first += pageSize {
+		page, err := fetch(first, pageSize)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, page...)
+		if len(page) < pageSize {
+			return result, nil
+		}
+	}
+}
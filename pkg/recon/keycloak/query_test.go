@@ -0,0 +1,90 @@
+package keycloak
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOptionsPaginationAndKinds(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/realms/master/protocol/openid-connect/token" {
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"access_token": "token", "token_type": "bearer"})
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		first, _ := strconv.Atoi(r.URL.Query().Get("first"))
+		switch r.URL.Path {
+		case "/admin/realms":
+			t.Error("realms must not be queried if an allow-list is configured")
+		case "/admin/realms/demo/users":
+			// two full pages of size 2, then a partial page
+			var users []map[string]interface{}
+			for i := first; i < first+2 && i < 5; i++ {
+				users = append(users, map[string]interface{}{"id": strconv.Itoa(i), "username": "user" + strconv.Itoa(i)})
+			}
+			assert.Equal(t, "2", r.URL.Query().Get("max"))
+			_ = json.NewEncoder(w).Encode(users)
+		case "/admin/realms/demo/groups":
+			_ = json.NewEncoder(w).Encode([]map[string]interface{}{{"id": "g1", "name": "dev", "path": "/dev"}})
+		case "/admin/realms/demo/groups/g1/children":
+			_ = json.NewEncoder(w).Encode([]map[string]interface{}{{"id": "g2", "name": "backend", "path": "/dev/backend"}})
+		case "/admin/realms/demo/groups/g2/children":
+			_ = json.NewEncoder(w).Encode([]map[string]interface{}{})
+		case "/admin/realms/demo/roles":
+			_ = json.NewEncoder(w).Encode([]map[string]interface{}{{"id": "r1", "name": "admin", "description": "administrator", "composite": true}})
+		case "/admin/realms/demo/identity-provider/instances":
+			_ = json.NewEncoder(w).Encode([]map[string]interface{}{{"alias": "github", "providerId": "github", "displayName": "GitHub", "enabled": true, "config": map[string]string{"clientId": "abc"}}})
+		default:
+			t.Logf("unexpected request: %s", r.URL.String())
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	module := NewModule(ModuleConfig{
+		Host:      server.URL,
+		RealmName: "master",
+		Username:  "admin",
+		Password:  "secret",
+		Realms:    []string{"demo"},
+		PageSize:  2,
+		Query:     []KeycloakContent{KeycloakUser, KeycloakGroup, KeycloakRole, KeycloakIdentityProvider},
+	})
+	options, err := module.Options()
+	require.NoError(t, err)
+
+	var ids []string
+	for _, o := range options {
+		ids = append(ids, o.Id)
+	}
+	assert.Equal(t, []string{
+		"demo/user/0", "demo/user/1", "demo/user/2", "demo/user/3", "demo/user/4",
+		"demo/group/g1", "demo/group/g2",
+		"demo/role/r1",
+		"demo/identity-provider/github",
+	}, ids)
+
+	// subgroups are listed with their path
+	assert.Equal(t, "/dev/backend [group] @ demo", options[6].DisplayName)
+	assert.Equal(t, "backend", options[6].Name)
+	assert.Equal(t, "/dev/backend", options[6].Context["path"])
+
+	assert.Equal(t, "administrator", options[7].Description)
+	assert.Equal(t, "true", options[7].Context["composite"])
+	assert.Equal(t, server.URL+"/admin/master/console/#/demo/roles/r1/details", options[7].Web)
+
+	assert.Equal(t, "abc", options[8].Context["clientId"])
+	assert.Equal(t, "demo", options[8].Context["realm"])
+	assert.Equal(t, server.URL+"/admin/master/console/#/demo/identity-providers/github/github/settings", options[8].Web)
+}
@@ -0,0 +1,95 @@
+package kubernetes
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/PhilippHeuer/fuzzmux/pkg/recon"
+	"github.com/PhilippHeuer/fuzzmux/pkg/util"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// namespaceFilter holds the compiled include / exclude patterns of a cluster
+type namespaceFilter struct {
+	include []*regexp.Regexp
+	exclude []*regexp.Regexp
+}
+
+func compileNamespaceFilter(cluster KubernetesCluster) (namespaceFilter, error) {
+	var filter namespaceFilter
+
+	for _, pattern := range cluster.Include {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return filter, fmt.Errorf("failed to compile include pattern '%s': %w", pattern, err)
+		}
+		filter.include = append(filter.include, re)
+	}
+	for _, pattern := range cluster.Exclude {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return filter, fmt.Errorf("failed to compile exclude pattern '%s': %w", pattern, err)
+		}
+		filter.exclude = append(filter.exclude, re)
+	}
+
+	return filter, nil
+}
+
+// Matches returns true if the namespace matches at least one include pattern (if any) and none of the exclude patterns
+func (f namespaceFilter) Matches(name string) bool {
+	for _, re := range f.exclude {
+		if re.MatchString(name) {
+			return false
+		}
+	}
+
+	if len(f.include) == 0 {
+		return true
+	}
+	for _, re := range f.include {
+		if re.MatchString(name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// namespaceAttributes returns the namespace metadata as attributes for the attribute mapping
+func namespaceAttributes(meta v1.ObjectMeta) map[string]interface{} {
+	data := map[string]interface{}{
+		"metadata.name": meta.Name,
+	}
+	for key, value := range meta.Labels {
+		data["metadata.labels."+key] = value
+	}
+	for key, value := range meta.Annotations {
+		data["metadata.annotations."+key] = value
+	}
+	return data
+}
+
+// applyNamespaceMapping copies the mapped namespace metadata into the option context and tags
+func applyNamespaceMapping(opt *recon.Option, meta v1.ObjectMeta, moduleConf ModuleConfig) {
+	if len(moduleConf.AttributeMapping) == 0 && len(moduleConf.TagMapping) == 0 {
+		return
+	}
+	attributes := namespaceAttributes(meta)
+
+	if len(moduleConf.AttributeMapping) > 0 {
+		for key, value := range recon.AttributeMapping(attributes, moduleConf.AttributeMapping) {
+			opt.Context[key] = value
+		}
+	}
+
+	if len(moduleConf.TagMapping) > 0 {
+		for key, value := range recon.AttributeMapping(attributes, moduleConf.TagMapping) {
+			if value == "" {
+				continue
+			}
+			opt.Tags = util.AddToSet(opt.Tags, key+"-"+strings.ToLower(value))
+		}
+	}
+}
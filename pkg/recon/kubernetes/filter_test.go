@@ -0,0 +1,78 @@
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/PhilippHeuer/fuzzmux/pkg/recon"
+	"github.com/PhilippHeuer/fuzzmux/pkg/types"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestNamespaceFilter(t *testing.T) {
+	tests := []struct {
+		name     string
+		include  []string
+		exclude  []string
+		expected map[string]bool
+	}{
+		{
+			name:     "No Patterns",
+			expected: map[string]bool{"default": true, "kube-system": true},
+		},
+		{
+			name:     "Exclude",
+			exclude:  []string{"^kube-", "^openshift"},
+			expected: map[string]bool{"default": true, "kube-system": false, "openshift-monitoring": false},
+		},
+		{
+			name:     "Include and Exclude",
+			include:  []string{"^team-"},
+			exclude:  []string{"-dev$"},
+			expected: map[string]bool{"team-payments": true, "team-payments-dev": false, "default": false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := compileNamespaceFilter(KubernetesCluster{Include: tt.include, Exclude: tt.exclude})
+			require.NoError(t, err)
+
+			for namespace, expected := range tt.expected {
+				require.Equal(t, expected, filter.Matches(namespace), namespace)
+			}
+		})
+	}
+}
+
+func TestNamespaceFilterInvalidPattern(t *testing.T) {
+	_, err := compileNamespaceFilter(KubernetesCluster{Include: []string{"("}})
+	require.Error(t, err)
+}
+
+func TestApplyNamespaceMapping(t *testing.T) {
+	opt := recon.Option{
+		Tags:    []string{"production"},
+		Context: map[string]string{"namespace": "payments"},
+	}
+	meta := v1.ObjectMeta{
+		Name:        "payments",
+		Labels:      map[string]string{"team": "Checkout"},
+		Annotations: map[string]string{"argocd.argoproj.io/instance": "payments-prod"},
+	}
+
+	applyNamespaceMapping(&opt, meta, ModuleConfig{
+		AttributeMapping: []types.FieldMapping{
+			{Source: "metadata.labels.team", Target: "team"},
+			{Source: "metadata.annotations.argocd.argoproj.io/instance", Target: "argocdApp"},
+		},
+		TagMapping: []types.FieldMapping{
+			{Source: "metadata.labels.team", Target: "team"},
+		},
+	})
+
+	require.Equal(t, "payments", opt.Context["namespace"])
+	require.Equal(t, "Checkout", opt.Context["team"])
+	require.Equal(t, "payments-prod", opt.Context["argocdApp"])
+	require.Equal(t, []string{"production", "team-checkout"}, opt.Tags)
+}
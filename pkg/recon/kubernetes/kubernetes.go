@@ -4,13 +4,18 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"slices"
+	"sort"
+	"strings"
 
 	"github.com/PhilippHeuer/fuzzmux/pkg/recon"
+	"github.com/PhilippHeuer/fuzzmux/pkg/types"
 	"github.com/PhilippHeuer/fuzzmux/pkg/util"
 	projectsv1 "github.com/openshift/client-go/project/clientset/versioned/typed/project/v1"
 	"github.com/rs/zerolog/log"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 )
 
@@ -33,6 +38,15 @@ type ModuleConfig struct {
 
 	// Clusters is a list of kubernetes clusters that should be scanned
 	Clusters []KubernetesCluster `yaml:"clusters"`
+
+	// Query is a list of content types that should be queried (default: namespace)
+	Query []KubernetesContent `yaml:"query"`
+
+	// AttributeMapping is a list of field mappings used to map namespace labels and annotations to context fields (e.g. metadata.labels.team)
+	AttributeMapping []types.FieldMapping `yaml:"attribute-mapping"`
+
+	// TagMapping is a list of field mappings used to add namespace labels and annotations as tags, rendered as <target>-<value>
+	TagMapping []types.FieldMapping `yaml:"tag-mapping"`
 }
 
 type KubernetesCluster struct {
@@ -47,6 +61,40 @@ type KubernetesCluster struct {
 
 	// KubeConfig is the absolute path to the kubeconfig file
 	KubeConfig string `yaml:"kubeconfig"`
+
+	// Contexts is an optional list of kubeconfig contexts that should be scanned, all contexts are scanned if empty
+	Contexts []string `yaml:"contexts"`
+
+	// LabelSelector is used to filter namespaces by label (e.g. "team=payments,env!=dev")
+	LabelSelector string `yaml:"label-selector"`
+
+	// Include is a list of regex patterns, only namespaces matching at least one pattern are included
+	Include []string `yaml:"include"`
+
+	// Exclude is a list of regex patterns, namespaces matching any pattern are excluded
+	Exclude []string `yaml:"exclude"`
+}
+
+type KubernetesContent string
+
+const (
+	KubernetesNamespace   KubernetesContent = "namespace"
+	KubernetesDeployment  KubernetesContent = "deployment"
+	KubernetesStatefulSet KubernetesContent = "statefulset"
+	KubernetesPod         KubernetesContent = "pod"
+)
+
+// clusterContext is a single kubeconfig context, resolved into a rest config
+type clusterContext struct {
+	Cluster     KubernetesCluster
+	Name        string // Name is the cluster name used in the option context
+	DisplayName string // DisplayName is the cluster name used in the option display name, empty for unnamed single-context clusters
+	Context     string // Context is the name of the kubeconfig context
+	KubeCluster string // KubeCluster is the name of the kubeconfig cluster entry referenced by the context
+	KubeUser    string // KubeUser is the name of the kubeconfig user entry referenced by the context
+	KubeConfig  string // KubeConfig is the resolved path to the kubeconfig file
+	RestConfig  *rest.Config
+	Filter      namespaceFilter
 }
 
 func (p Module) Name() string {
@@ -64,20 +112,32 @@ func (p Module) Options() ([]recon.Option, error) {
 	var options []recon.Option
 
 	for _, cluster := range p.Config.Clusters {
-		if cluster.OpenShift {
-			opts, err := processOpenShiftCluster(cluster, p.Name(), p.Config)
+		contexts, err := resolveClusterContexts(cluster)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, cc := range contexts {
+			var namespaces []recon.Option
+			if cluster.OpenShift {
+				namespaces, err = processOpenShiftCluster(cc, p.Name(), p.Config)
+			} else {
+				namespaces, err = processKubernetesCluster(cc, p.Name(), p.Config)
+			}
 			if err != nil {
 				return nil, err
 			}
-			options = append(options, opts...)
-			continue
-		}
 
-		opts, err := processKubernetesCluster(cluster, p.Name(), p.Config)
-		if err != nil {
-			return nil, err
+			if slices.Contains(p.Config.Query, KubernetesNamespace) {
+				options = append(options, namespaces...)
+			}
+
+			workloads, err := processWorkloads(cc, namespaces, p.Name(), p.Config)
+			if err != nil {
+				return nil, err
+			}
+			options = append(options, workloads...)
 		}
-		options = append(options, opts...)
 	}
 
 	return options, nil
@@ -111,71 +171,151 @@ func (p Module) SelectOption(option *recon.Option) error {
 	return nil
 }
 
+// Preview shows the cluster of the context
+func (p Module) Preview(option *recon.Option) string {
+	var builder strings.Builder
+	recon.PreviewField(&builder, "K8S Cluster Name", option.Context["clusterName"])
+	recon.PreviewField(&builder, "K8S Cluster API", option.Context["clusterHost"])
+	recon.PreviewField(&builder, "K8S Cluster User", option.Context["clusterUser"])
+	recon.PreviewField(&builder, "K8S Cluster Type", option.Context["clusterType"])
+	return builder.String()
+}
+
 func (p Module) Columns() []recon.Column {
-	return recon.DefaultColumns()
+	return append(recon.DefaultColumns(),
+		recon.Column{Key: "clusterName", Name: "Cluster"},
+		recon.Column{Key: "namespace", Name: "Namespace"},
+		recon.Column{Key: "kind", Name: "Kind"},
+	)
 }
 
 func NewModule(config ModuleConfig) Module {
+	if len(config.Query) == 0 {
+		config.Query = []KubernetesContent{KubernetesNamespace}
+	}
+
 	return Module{
 		Config: config,
 	}
 }
 
-func processKubernetesCluster(cluster KubernetesCluster, moduleName string, moduleConf ModuleConfig) (result []recon.Option, err error) {
-	clusterName := "default"
-	if cluster.Name != "" {
-		clusterName = cluster.Name
-	}
-
+// resolveClusterContexts expands every context of the cluster kubeconfig into a separate cluster
+func resolveClusterContexts(cluster KubernetesCluster) ([]clusterContext, error) {
 	// file exists?
 	configFile := util.ResolvePath(cluster.KubeConfig)
 	if _, err := os.Stat(configFile); os.IsNotExist(err) {
 		return nil, fmt.Errorf("kubeconfig file does not exist: %w", err)
 	}
 
+	// namespace filter
+	filter, err := compileNamespaceFilter(cluster)
+	if err != nil {
+		return nil, err
+	}
+
 	// read config
-	conf, err := clientcmd.BuildConfigFromFlags("", configFile)
+	kubeConfig, err := clientcmd.LoadFromFile(configFile)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse kubeconfig: %w", err)
 	}
 
+	var contextNames []string
+	for name := range kubeConfig.Contexts {
+		if len(cluster.Contexts) == 0 || slices.Contains(cluster.Contexts, name) {
+			contextNames = append(contextNames, name)
+		}
+	}
+	sort.Strings(contextNames)
+
+	if len(contextNames) == 0 {
+		return nil, fmt.Errorf("no matching contexts found in kubeconfig %s", configFile)
+	}
+
+	var result []clusterContext
+	for _, contextName := range contextNames {
+		conf, err := clientcmd.NewNonInteractiveClientConfig(*kubeConfig, contextName, &clientcmd.ConfigOverrides{}, nil).ClientConfig()
+		if err != nil {
+			return nil, fmt.Errorf("failed to build client config for context %q: %w", contextName, err)
+		}
+
+		cc := clusterContext{
+			Cluster:     cluster,
+			Name:        "default",
+			DisplayName: cluster.Name,
+			Context:     contextName,
+			KubeCluster: kubeConfig.Contexts[contextName].Cluster,
+			KubeUser:    kubeConfig.Contexts[contextName].AuthInfo,
+			KubeConfig:  configFile,
+			RestConfig:  conf,
+			Filter:      filter,
+		}
+		if cluster.Name != "" {
+			cc.Name = cluster.Name
+		}
+		if len(contextNames) > 1 {
+			cc.Name = contextName
+			if cluster.Name != "" {
+				cc.Name = cluster.Name + "/" + contextName
+			}
+			cc.DisplayName = cc.Name
+		}
+		result = append(result, cc)
+	}
+
+	return result, nil
+}
+
+// clusterContextMap returns the option context shared by all options of the cluster
+func clusterContextMap(cc clusterContext, clusterType string) map[string]string {
+	return map[string]string{
+		"clusterName": cc.Name,
+		"clusterHost": cc.RestConfig.Host,
+		"clusterUser": cc.RestConfig.Username,
+		"clusterType": clusterType,
+		"kubeConfig":  cc.KubeConfig,
+		"kubeContext": cc.Context,
+		"kubeCluster": cc.KubeCluster,
+		"kubeUser":    cc.KubeUser,
+	}
+}
+
+func processKubernetesCluster(cc clusterContext, moduleName string, moduleConf ModuleConfig) (result []recon.Option, err error) {
 	// create client
-	client, err := kubernetes.NewForConfig(conf)
+	client, err := kubernetes.NewForConfig(cc.RestConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create client from config: %w", err)
 	}
 
 	// list namespaces
-	list, err := client.CoreV1().Namespaces().List(context.Background(), v1.ListOptions{})
+	list, err := client.CoreV1().Namespaces().List(context.Background(), v1.ListOptions{LabelSelector: cc.Cluster.LabelSelector})
 	if err != nil {
 		return nil, err
 	}
 
 	// add namespaces
 	for _, item := range list.Items {
+		if !cc.Filter.Matches(item.GetName()) {
+			continue
+		}
+
 		displayName := item.GetName()
-		if cluster.Name != "" {
-			displayName = fmt.Sprintf("%s @ %s", displayName, cluster.Name)
+		if cc.DisplayName != "" {
+			displayName = fmt.Sprintf("%s @ %s", displayName, cc.DisplayName)
 		}
 
 		// add option
 		opt := recon.Option{
 			ProviderName:   moduleName,
 			ProviderType:   moduleType,
-			Id:             item.GetName(),
+			Id:             cc.Name + "/" + item.GetName(),
 			DisplayName:    displayName,
 			Name:           item.GetName(),
 			StartDirectory: defaultStartDirectory,
-			Tags:           cluster.Tags,
-			Context: map[string]string{
-				"clusterName": clusterName,
-				"clusterHost": conf.Host,
-				"clusterUser": conf.Username,
-				"clusterType": "kubernetes",
-				"kubeConfig":  configFile,
-				"namespace":   item.GetName(),
-			},
+			Tags:           slices.Clone(cc.Cluster.Tags),
+			Context:        clusterContextMap(cc, "kubernetes"),
 		}
+		opt.Context["namespace"] = item.GetName()
+		applyNamespaceMapping(&opt, item.ObjectMeta, moduleConf)
 		opt.ProcessUserTemplateStrings(moduleConf.DisplayName, moduleConf.StartDirectory)
 		result = append(result, opt)
 	}
@@ -183,42 +323,29 @@ func processKubernetesCluster(cluster KubernetesCluster, moduleName string, modu
 	return result, nil
 }
 
-func processOpenShiftCluster(cluster KubernetesCluster, moduleName string, moduleConf ModuleConfig) (result []recon.Option, err error) {
-	clusterName := "default"
-	if cluster.Name != "" {
-		clusterName = cluster.Name
-	}
-
-	// file exists?
-	configFile := util.ResolvePath(cluster.KubeConfig)
-	if _, err := os.Stat(configFile); os.IsNotExist(err) {
-		return nil, fmt.Errorf("kubeconfig file does not exist: %w", err)
-	}
-
-	// read config
-	conf, err := clientcmd.BuildConfigFromFlags("", configFile)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse kubeconfig: %w", err)
-	}
-
+func processOpenShiftCluster(cc clusterContext, moduleName string, moduleConf ModuleConfig) (result []recon.Option, err error) {
 	// create client
-	client, err := projectsv1.NewForConfig(conf)
+	client, err := projectsv1.NewForConfig(cc.RestConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create client from config: %w", err)
 	}
 
 	// list namespaces
-	list, err := client.Projects().List(context.Background(), v1.ListOptions{})
+	list, err := client.Projects().List(context.Background(), v1.ListOptions{LabelSelector: cc.Cluster.LabelSelector})
 	if err != nil {
 		return nil, err
 	}
 	for _, item := range list.Items {
+		if !cc.Filter.Matches(item.GetName()) {
+			continue
+		}
+
 		displayName := item.GetName()
 		if item.Annotations["openshift.io/display-name"] != "" {
 			displayName = fmt.Sprintf("[%s] %s", item.GetName(), item.Annotations["openshift.io/display-name"])
 		}
-		if cluster.Name != "" {
-			displayName = fmt.Sprintf("%s @ %s", displayName, cluster.Name)
+		if cc.DisplayName != "" {
+			displayName = fmt.Sprintf("%s @ %s", displayName, cc.DisplayName)
 		}
 
 		description := ""
@@ -230,21 +357,16 @@ func processOpenShiftCluster(cluster KubernetesCluster, moduleName string, modul
 		opt := recon.Option{
 			ProviderName:   moduleName,
 			ProviderType:   moduleType,
-			Id:             item.GetName(),
+			Id:             cc.Name + "/" + item.GetName(),
 			DisplayName:    displayName,
 			Name:           item.GetName(),
 			StartDirectory: defaultStartDirectory,
-			Tags:           cluster.Tags,
-			Context: map[string]string{
-				"clusterName": clusterName,
-				"clusterHost": conf.Host,
-				"clusterUser": conf.Username,
-				"clusterType": "openshift",
-				"kubeConfig":  configFile,
-				"namespace":   item.GetName(),
-				"description": description,
-			},
+			Tags:           slices.Clone(cc.Cluster.Tags),
+			Context:        clusterContextMap(cc, "openshift"),
 		}
+		opt.Context["namespace"] = item.GetName()
+		opt.Context["description"] = description
+		applyNamespaceMapping(&opt, item.ObjectMeta, moduleConf)
 		opt.ProcessUserTemplateStrings(moduleConf.DisplayName, moduleConf.StartDirectory)
 		result = append(result, opt)
 	}
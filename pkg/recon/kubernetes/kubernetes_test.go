@@ -0,0 +1,62 @@
+package kubernetes
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const multiContextKubeConfig = `apiVersion: v1
+kind: Config
+clusters:
+  - name: dev
+    cluster:
+      server: https://dev.example.com:6443
+  - name: prod
+    cluster:
+      server: https://prod.example.com:6443
+users:
+  - name: admin
+    user:
+      token: secret
+contexts:
+  - name: dev
+    context:
+      cluster: dev
+      user: admin
+  - name: prod
+    context:
+      cluster: prod
+      user: admin
+current-context: dev
+`
+
+func TestResolveClusterContexts(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config")
+	require.NoError(t, os.WriteFile(configFile, []byte(multiContextKubeConfig), 0600))
+
+	// all contexts
+	contexts, err := resolveClusterContexts(KubernetesCluster{Name: "company", KubeConfig: configFile})
+	require.NoError(t, err)
+	require.Len(t, contexts, 2)
+	require.Equal(t, "company/dev", contexts[0].Name)
+	require.Equal(t, "dev", contexts[0].Context)
+	require.Equal(t, "dev", contexts[0].KubeCluster)
+	require.Equal(t, "admin", contexts[0].KubeUser)
+	require.Equal(t, "https://dev.example.com:6443", contexts[0].RestConfig.Host)
+	require.Equal(t, "company/prod", contexts[1].Name)
+	require.Equal(t, "https://prod.example.com:6443", contexts[1].RestConfig.Host)
+
+	// single context keeps the cluster name
+	contexts, err = resolveClusterContexts(KubernetesCluster{Name: "company", KubeConfig: configFile, Contexts: []string{"prod"}})
+	require.NoError(t, err)
+	require.Len(t, contexts, 1)
+	require.Equal(t, "company", contexts[0].Name)
+	require.Equal(t, "prod", contexts[0].Context)
+
+	// no match
+	_, err = resolveClusterContexts(KubernetesCluster{KubeConfig: configFile, Contexts: []string{"missing"}})
+	require.Error(t, err)
+}
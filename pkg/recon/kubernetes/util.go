@@ -0,0 +1,24 @@
+package kubernetes
+
+import (
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func containerNames(containers []corev1.Container) []string {
+	var names []string
+	for _, c := range containers {
+		names = append(names, c.Name)
+	}
+	return names
+}
+
+func ownerReferencesToString(refs []v1.OwnerReference) string {
+	var owners []string
+	for _, ref := range refs {
+		owners = append(owners, ref.Kind+"/"+ref.Name)
+	}
+	return strings.Join(owners, ", ")
+}
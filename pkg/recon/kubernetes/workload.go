@@ -0,0 +1,134 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/PhilippHeuer/fuzzmux/pkg/recon"
+	"github.com/rs/zerolog/log"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const workloadLayout = "kubernetes-workload"
+
+// workload is the common representation of deployments, statefulsets and pods
+type workload struct {
+	Kind       KubernetesContent
+	Meta       v1.ObjectMeta
+	Status     string
+	Containers []string
+	Node       string
+}
+
+// processWorkloads lists the workloads in all namespaces of the cluster, based on the queried content types
+func processWorkloads(cc clusterContext, namespaces []recon.Option, moduleName string, moduleConf ModuleConfig) (result []recon.Option, err error) {
+	if !slices.Contains(moduleConf.Query, KubernetesDeployment) && !slices.Contains(moduleConf.Query, KubernetesStatefulSet) && !slices.Contains(moduleConf.Query, KubernetesPod) {
+		return nil, nil
+	}
+
+	// create client
+	client, err := kubernetes.NewForConfig(cc.RestConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client from config: %w", err)
+	}
+
+	for _, ns := range namespaces {
+		namespace := ns.Context["namespace"]
+		workloads, err := listWorkloads(client, namespace, moduleConf.Query)
+		if err != nil {
+			// missing permissions on single namespaces should not fail the whole cluster
+			log.Warn().Err(err).Str("cluster", cc.Name).Str("namespace", namespace).Msg("failed to list workloads")
+			continue
+		}
+
+		for _, w := range workloads {
+			displayName := fmt.Sprintf("%s/%s @ %s", w.Kind, w.Meta.Name, namespace)
+			if cc.DisplayName != "" {
+				displayName = fmt.Sprintf("%s @ %s", displayName, cc.DisplayName)
+			}
+
+			opt := recon.Option{
+				ProviderName:   moduleName,
+				ProviderType:   moduleType,
+				Id:             fmt.Sprintf("%s/%s/%s/%s", cc.Name, namespace, w.Kind, w.Meta.Name),
+				DisplayName:    displayName,
+				Name:           w.Meta.Name,
+				StartDirectory: defaultStartDirectory,
+				Tags:           append(slices.Clone(ns.Tags), string(w.Kind)),
+				Context:        maps.Clone(ns.Context),
+			}
+			opt.Context["kind"] = string(w.Kind)
+			opt.Context["workload"] = w.Meta.Name
+			opt.Context["status"] = w.Status
+			opt.Context["containers"] = strings.Join(w.Containers, ", ")
+			opt.Context["node"] = w.Node
+			opt.Context["owners"] = ownerReferencesToString(w.Meta.OwnerReferences)
+			opt.Context["layout"] = workloadLayout
+			for key, value := range w.Meta.Labels {
+				opt.Context["label."+key] = value
+			}
+			delete(opt.Context, "description")
+			opt.ProcessUserTemplateStrings(moduleConf.DisplayName, moduleConf.StartDirectory)
+			result = append(result, opt)
+		}
+	}
+
+	return result, nil
+}
+
+func listWorkloads(client *kubernetes.Clientset, namespace string, query []KubernetesContent) ([]workload, error) {
+	var result []workload
+	ctx := context.Background()
+
+	if slices.Contains(query, KubernetesDeployment) {
+		list, err := client.AppsV1().Deployments(namespace).List(ctx, v1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list deployments: %w", err)
+		}
+		for _, item := range list.Items {
+			result = append(result, workload{
+				Kind:       KubernetesDeployment,
+				Meta:       item.ObjectMeta,
+				Status:     fmt.Sprintf("%d/%d ready", item.Status.ReadyReplicas, item.Status.Replicas),
+				Containers: containerNames(item.Spec.Template.Spec.Containers),
+			})
+		}
+	}
+
+	if slices.Contains(query, KubernetesStatefulSet) {
+		list, err := client.AppsV1().StatefulSets(namespace).List(ctx, v1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list statefulsets: %w", err)
+		}
+		for _, item := range list.Items {
+			result = append(result, workload{
+				Kind:       KubernetesStatefulSet,
+				Meta:       item.ObjectMeta,
+				Status:     fmt.Sprintf("%d/%d ready", item.Status.ReadyReplicas, item.Status.Replicas),
+				Containers: containerNames(item.Spec.Template.Spec.Containers),
+			})
+		}
+	}
+
+	if slices.Contains(query, KubernetesPod) {
+		list, err := client.CoreV1().Pods(namespace).List(ctx, v1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list pods: %w", err)
+		}
+		for _, item := range list.Items {
+			result = append(result, workload{
+				Kind:       KubernetesPod,
+				Meta:       item.ObjectMeta,
+				Status:     string(item.Status.Phase),
+				Containers: containerNames(item.Spec.Containers),
+				Node:       item.Spec.NodeName,
+			})
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,88 @@
+package ldap
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/url"
+	"os"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/rs/zerolog/log"
+)
+
+// connect opens a connection to the configured server, upgrades it using StartTLS if enabled and binds if a bind dn is configured
+func (p Module) connect() (*ldap.Conn, error) {
+	tlsConfig, err := p.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	log.Debug().Str("host", p.Config.Host).Bool("startTLS", p.Config.StartTLS).Msg("connecting to ldap")
+	l, err := ldap.DialURL(p.Config.Host, ldap.DialWithTLSConfig(tlsConfig))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to ldap: %w", err)
+	}
+
+	if p.Config.StartTLS {
+		if err = l.StartTLS(tlsConfig); err != nil {
+			l.Close()
+			return nil, fmt.Errorf("failed to start tls: %w", err)
+		}
+	}
+
+	if p.Config.BindDistinguishedName != "" {
+		log.Debug().Str("bindDN", p.Config.BindDistinguishedName).Msg("binding to ldap")
+		if err = l.Bind(p.Config.BindDistinguishedName, p.Config.BindPassword); err != nil {
+			l.Close()
+			return nil, fmt.Errorf("failed to bind to ldap: %w", err)
+		}
+	}
+
+	return l, nil
+}
+
+// tlsConfig is used for ldaps:// and StartTLS connections
+func (p Module) tlsConfig() (*tls.Config, error) {
+	config := &tls.Config{
+		InsecureSkipVerify: p.Config.InsecureSkipVerify,
+	}
+
+	if u, err := url.Parse(p.Config.Host); err == nil {
+		config.ServerName = u.Hostname()
+	}
+
+	if p.Config.CACertificate != "" {
+		pem, err := os.ReadFile(p.Config.CACertificate)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read ca certificate: %w", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", p.Config.CACertificate)
+		}
+		config.RootCAs = pool
+	}
+
+	return config, nil
+}
+
+// search runs a paged subtree search (RFC 2696), which is required to get more entries than the server size limit (e.g. 1000 for Active Directory)
+func (p Module) search(l ldap.Client, baseDN string, filter string, attributes []string) ([]*ldap.Entry, error) {
+	log.Debug().Str("filter", filter).Str("base", baseDN).Int("pageSize", p.Config.PageSize).Msg("searching ldap")
+	searchRequest := ldap.NewSearchRequest(
+		baseDN,
+		ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 0, 0, false,
+		filter,
+		attributes,
+		nil,
+	)
+
+	sr, err := l.SearchWithPaging(searchRequest, uint32(p.Config.PageSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to search ldap: %w", err)
+	}
+
+	return sr.Entries, nil
+}
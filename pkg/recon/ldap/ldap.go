@@ -13,6 +13,9 @@ import (
 
 const moduleType = "ldap"
 
+// secretBindPassword is the environment variable of the bind password, see ResolveSecret
+const secretBindPassword = "LDAP_BIND_PASSWORD"
+
 type Module struct {
 	Config ModuleConfig
 }
@@ -44,13 +47,45 @@ type ModuleConfig struct {
 
 	// Filter is the LDAP search filter (e.g., "(&(objectClass=organizationalPerson))")
 	Filter string `yaml:"filter"`
+
+	// SearchBases is a list of search bases with their own filter, replaces BaseDistinguishedName and Filter if set
+	SearchBases []SearchBase `yaml:"search-bases"`
+
+	// StartTLS upgrades a plain ldap:// connection to TLS
+	StartTLS bool `yaml:"start-tls"`
+
+	// CACertificate is the path to a PEM file with the CA used to verify the server certificate, defaults to the system pool
+	CACertificate string `yaml:"ca-certificate"`
+
+	// InsecureSkipVerify disables the verification of the server certificate
+	InsecureSkipVerify bool `yaml:"insecure-skip-verify"`
+
+	// PageSize is the number of entries requested per page, defaults to 500
+	PageSize int `yaml:"page-size"`
+
+	// Membership is the strategy used to resolve group memberships: memberof (default), member, in-chain (Active Directory) or none
+	Membership string `yaml:"membership"`
+
+	// GroupBaseDistinguishedName is the base dn used to search for groups and members, defaults to the domain components of the entry
+	GroupBaseDistinguishedName string `yaml:"group-base-dn"`
 }
 
-func (c *ModuleConfig) DecodeConfig() {
-	c.Host = util.ResolveCredentialValue(c.Host)
-	c.BindDistinguishedName = util.ResolveCredentialValue(c.BindDistinguishedName)
-	c.BindPassword = util.ResolveCredentialValue(c.BindPassword)
-	c.BaseDistinguishedName = util.ResolveCredentialValue(c.BaseDistinguishedName)
+type SearchBase struct {
+	// BaseDistinguishedName (DN) for the search (e.g., "ou=people,dc=example,dc=com")
+	BaseDistinguishedName string `yaml:"base-dn"`
+
+	// Filter is the LDAP search filter, defaults to the filter of the module
+	Filter string `yaml:"filter"`
+
+	// Tags are added to all entries of this search base
+	Tags []string `yaml:"tags"`
+
+	// DisplayName is a template string to render a custom display name, defaults to the display name of the module
+	DisplayName string `yaml:"display-name"`
+}
+
+func (c *ModuleConfig) DecodeConfig() error {
+	return util.ResolveCredentialValues(&c.Host, &c.BindDistinguishedName, &c.BindPassword, &c.BaseDistinguishedName, &c.GroupBaseDistinguishedName)
 }
 
 func (p Module) Name() string {
@@ -65,63 +100,82 @@ func (p Module) Type() string {
 }
 
 func (p Module) Options() ([]recon.Option, error) {
-	p.Config.DecodeConfig()
+	if err := p.Config.DecodeConfig(); err != nil {
+		return nil, fmt.Errorf("failed to resolve credentials: %w", err)
+	}
 	var result []recon.Option
 
 	// connect
-	log.Debug().Str("host", p.Config.Host).Msg("connecting to ldap")
-	l, err := ldap.DialURL(p.Config.Host)
+	l, err := p.connect()
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to ldap: %w", err)
+		return nil, err
 	}
 	defer l.Close()
 
-	// bind
-	if p.Config.BindDistinguishedName != "" {
-		log.Debug().Str("bindDN", p.Config.BindDistinguishedName).Msg("binding to ldap")
-		err = l.Bind(p.Config.BindDistinguishedName, p.Config.BindPassword)
+	// search
+	bases, err := p.searchBases()
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve search bases: %w", err)
+	}
+	seen := make(map[string]bool)
+	for _, base := range bases {
+		entries, err := p.search(l, base.BaseDistinguishedName, base.Filter, []string{})
 		if err != nil {
-			return nil, fmt.Errorf("failed to bind to ldap: %w", err)
+			return nil, fmt.Errorf("search base %s: %w", base.BaseDistinguishedName, err)
 		}
-	}
 
-	// search
-	log.Debug().Str("filter", p.Config.Filter).Str("base", p.Config.BaseDistinguishedName).Msg("searching ldap")
-	searchRequest := ldap.NewSearchRequest(
-		p.Config.BaseDistinguishedName,
-		ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 0, 0, false,
-		p.Config.Filter,
-		[]string{},
-		nil,
-	)
-	sr, err := l.Search(searchRequest)
-	if err != nil {
-		return nil, fmt.Errorf("failed to search ldap: %w", err)
+		// add to result
+		for _, entry := range entries {
+			if seen[entry.DN] {
+				continue
+			}
+			seen[entry.DN] = true
+
+			log.Trace().Str("dn", entry.DN).Interface("attributes", entry.Attributes).Msg("found entry")
+			entryAttributes := attributesToMap(entry.Attributes)
+			context := recon.AttributeMapping(entryAttributes, p.Config.AttributeMapping)
+
+			opt := recon.Option{
+				ProviderName:   p.Name(),
+				ProviderType:   p.Type(),
+				Id:             entry.DN,
+				DisplayName:    fmt.Sprintf("%s [%s]", entry.GetAttributeValue("cn"), entry.DN),
+				Name:           entry.GetAttributeValue("cn"),
+				StartDirectory: "~",
+				Tags:           append([]string{"ldap"}, base.Tags...),
+				Context:        context,
+				ModuleContext: map[string]string{
+					"ldapHost":   p.Config.Host,
+					"ldapBindDN": p.Config.BindDistinguishedName,
+				},
+				Secrets: p.ModuleSecrets(),
+			}
+			opt.ProcessUserTemplateStrings(base.DisplayName, p.Config.StartDirectory)
+			result = append(result, opt)
+		}
 	}
 
-	// add to result
-	for _, entry := range sr.Entries {
-		log.Trace().Str("dn", entry.DN).Interface("attributes", entry.Attributes).Msg("found entry")
-		entryAttributes := attributesToMap(entry.Attributes)
-		context := recon.AttributeMapping(entryAttributes, p.Config.AttributeMapping)
+	return result, nil
+}
 
-		opt := recon.Option{
-			ProviderName:   p.Name(),
-			ProviderType:   p.Type(),
-			Id:             entry.DN,
-			DisplayName:    fmt.Sprintf("%s [%s]", entry.GetAttributeValue("cn"), entry.DN),
-			Name:           entry.GetAttributeValue("cn"),
-			StartDirectory: "~",
-			Tags:           []string{"ldap"},
-			Context:        context,
-			ModuleContext: map[string]string{
-				"ldapHost":         p.Config.Host,
-				"ldapBindDN":       p.Config.BindDistinguishedName,
-				"ldapBindPassword": p.Config.BindPassword,
-			},
+// searchBases returns the configured search bases, falling back to base-dn and filter of the module
+func (p Module) searchBases() ([]SearchBase, error) {
+	if len(p.Config.SearchBases) == 0 {
+		return []SearchBase{{BaseDistinguishedName: p.Config.BaseDistinguishedName, Filter: p.Config.Filter, DisplayName: p.Config.DisplayName}}, nil
+	}
+
+	var result []SearchBase
+	for _, base := range p.Config.SearchBases {
+		if err := util.ResolveCredentialValues(&base.BaseDistinguishedName); err != nil {
+			return nil, err
+		}
+		if base.Filter == "" {
+			base.Filter = p.Config.Filter
+		}
+		if base.DisplayName == "" {
+			base.DisplayName = p.Config.DisplayName
 		}
-		opt.ProcessUserTemplateStrings(p.Config.DisplayName, p.Config.StartDirectory)
-		result = append(result, opt)
+		result = append(result, base)
 	}
 
 	return result, nil
@@ -137,9 +191,139 @@ func (p Module) SelectOption(option *recon.Option) error {
 		return err
 	}
 
+	if p.Config.Membership == MembershipNone {
+		return nil
+	}
+	if err := p.Config.DecodeConfig(); err != nil {
+		return fmt.Errorf("failed to resolve credentials: %w", err)
+	}
+	l, err := p.connect()
+	if err != nil {
+		return err
+	}
+	defer l.Close()
+
+	return p.resolveMemberships(l, option)
+}
+
+// resolveMemberships adds the groups of the entry and the members of a group to the context
+func (p Module) resolveMemberships(l ldap.Client, option *recon.Option) error {
+	sr, err := l.Search(ldap.NewSearchRequest(option.Id, ldap.ScopeBaseObject, ldap.NeverDerefAliases, 0, 0, false, "(objectClass=*)", membershipAttributes, nil))
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", option.Id, err)
+	}
+	if len(sr.Entries) == 0 {
+		return nil
+	}
+	entry := sr.Entries[0]
+
+	baseDN := p.Config.GroupBaseDistinguishedName
+	if baseDN == "" {
+		baseDN = namingContext(entry.DN)
+	}
+	resolver := membershipResolver{client: l, module: p, mode: p.Config.Membership, baseDN: baseDN}
+
+	if option.Context == nil {
+		option.Context = make(map[string]string)
+	}
+	groups, err := resolver.groups(entry)
+	if err != nil {
+		return fmt.Errorf("failed to resolve groups of %s: %w", entry.DN, err)
+	}
+	if len(groups) > 0 {
+		option.Context["groups"] = strings.Join(groups, membershipSeparator)
+	}
+	if isGroup(entry) {
+		members, err := resolver.members(entry)
+		if err != nil {
+			return fmt.Errorf("failed to resolve members of %s: %w", entry.DN, err)
+		}
+		if len(members) > 0 {
+			option.Context["members"] = strings.Join(members, membershipSeparator)
+		}
+	}
+
 	return nil
 }
 
+func (p Module) Drilldowns(option *recon.Option) []recon.Drilldown {
+	var result []recon.Drilldown
+	if option.Context["groups"] != "" {
+		result = append(result, recon.Drilldown{Id: "groups", Name: fmt.Sprintf("Groups of %s", option.Name)})
+	}
+	if option.Context["members"] != "" {
+		result = append(result, recon.Drilldown{Id: "members", Name: fmt.Sprintf("Members of %s", option.Name)})
+	}
+	return result
+}
+
+func (p Module) DrilldownOptions(option *recon.Option, drilldownId string) ([]recon.Option, error) {
+	if drilldownId != "groups" && drilldownId != "members" {
+		return nil, fmt.Errorf("unsupported drilldown: %s", drilldownId)
+	}
+
+	// entries within the search bases are taken from the cache, others only contain the dn
+	cached, err := p.OptionsOrCache(3600)
+	if err != nil {
+		log.Debug().Err(err).Str("recon", p.Name()).Msg("failed to get options")
+	}
+
+	var result []recon.Option
+	for _, dn := range strings.Split(option.Context[drilldownId], membershipSeparator) {
+		if o, err := recon.OptionById(cached, dn); err == nil {
+			result = append(result, *o)
+			continue
+		}
+
+		result = append(result, recon.Option{
+			ProviderName:   p.Name(),
+			ProviderType:   p.Type(),
+			Id:             dn,
+			DisplayName:    fmt.Sprintf("%s [%s]", commonName(dn), dn),
+			Name:           commonName(dn),
+			StartDirectory: "~",
+			Tags:           []string{"ldap"},
+			Context:        map[string]string{},
+			ModuleContext:  option.ModuleContext,
+			Secrets:        option.Secrets,
+		})
+	}
+
+	return result, nil
+}
+
+// ModuleSecrets returns the secrets passed to the layout, the bind password is only available if a bind dn is configured
+// all options share the bind password, so drilldown entries outside of the search bases (not cached) can be launched as well
+func (p Module) ModuleSecrets() []string {
+	if p.Config.BindDistinguishedName == "" {
+		return nil
+	}
+	return []string{secretBindPassword}
+}
+
+// ResolveSecret resolves the bind password when the option is launched
+func (p Module) ResolveSecret(option *recon.Option, name string) (string, error) {
+	if name != secretBindPassword {
+		return "", fmt.Errorf("unknown secret %s", name)
+	}
+	if err := p.Config.DecodeConfig(); err != nil {
+		return "", err
+	}
+
+	return p.Config.BindPassword, nil
+}
+
+// Preview lists the groups and members resolved by SelectOption
+func (p Module) Preview(option *recon.Option) string {
+	var builder strings.Builder
+	for _, key := range []string{"groups", "members"} {
+		if option.Context[key] != "" {
+			recon.PreviewList(&builder, strings.ToUpper(key[:1])+key[1:], strings.Split(option.Context[key], membershipSeparator))
+		}
+	}
+	return builder.String()
+}
+
 func (p Module) Columns() []recon.Column {
 	return append(recon.DefaultColumns(),
 		recon.Column{Key: "user", Name: "User"},
@@ -150,6 +334,12 @@ func NewModule(config ModuleConfig) Module {
 	if config.Filter == "" {
 		config.Filter = "(|(objectClass=*))"
 	}
+	if config.PageSize <= 0 {
+		config.PageSize = 500
+	}
+	if config.Membership == "" {
+		config.Membership = MembershipMemberOf
+	}
 
 	return Module{
 		Config: config,
@@ -4,8 +4,11 @@ import (
 	"context"
 	"github.com/testcontainers/testcontainers-go"
 	"os"
+	"path/filepath"
+	"slices"
 	"testing"
 
+	"github.com/PhilippHeuer/fuzzmux/pkg/recon"
 	"github.com/stretchr/testify/require"
 
 	"github.com/testcontainers/testcontainers-go/wait"
@@ -93,3 +96,86 @@ func TestSearchGroups(t *testing.T) {
 	require.Equal(t, "developers", options[1].Name)
 	require.Equal(t, "cn=developers,ou=groups,dc=example,dc=com", options[1].Id)
 }
+
+func TestSearchBasesPaged(t *testing.T) {
+	if os.Getenv("DOCKER_HOST") == "" {
+		t.Skip("skipping test")
+	}
+
+	ctx := context.Background()
+	ldapServer, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: ldapMockRequest,
+		Started:          true,
+		Reuse:            false,
+	})
+	require.NoError(t, err)
+	defer testcontainers.CleanupContainer(t, ldapServer)
+	ldapEndpoint, err := ldapServer.Endpoint(ctx, "")
+	require.NoError(t, err)
+
+	// query, a page size of 1 requires multiple pages
+	ldapModule := NewModule(ModuleConfig{
+		Host:                  "ldap://" + ldapEndpoint,
+		BindDistinguishedName: "cn=admin,dc=example,dc=com",
+		BindPassword:          "secret",
+		PageSize:              1,
+		SearchBases: []SearchBase{
+			{BaseDistinguishedName: "ou=people,dc=example,dc=com", Filter: "(objectClass=person)", Tags: []string{"user"}},
+			{BaseDistinguishedName: "ou=groups,dc=example,dc=com", Filter: "(objectClass=groupOfNames)", Tags: []string{"group"}, DisplayName: "group {{name}}"},
+		},
+	})
+	options, err := ldapModule.Options()
+	require.NoError(t, err)
+
+	// verify
+	var users, groups int
+	for _, o := range options {
+		if slices.Contains(o.Tags, "user") {
+			users++
+		} else if slices.Contains(o.Tags, "group") {
+			groups++
+			require.Equal(t, "group "+o.Name, o.DisplayName)
+		}
+	}
+	require.Greater(t, users, 1)
+	require.Greater(t, groups, 1)
+}
+
+func TestSearchBaseDefaults(t *testing.T) {
+	module := NewModule(ModuleConfig{BaseDistinguishedName: "dc=example,dc=com", Filter: "(objectClass=person)", DisplayName: "{{name}}"})
+	bases, err := module.searchBases()
+	require.NoError(t, err)
+	require.Equal(t, []SearchBase{{BaseDistinguishedName: "dc=example,dc=com", Filter: "(objectClass=person)", DisplayName: "{{name}}"}}, bases)
+	require.Equal(t, 500, module.Config.PageSize)
+
+	module = NewModule(ModuleConfig{Filter: "(objectClass=person)", SearchBases: []SearchBase{{BaseDistinguishedName: "ou=groups,dc=example,dc=com", Filter: "(objectClass=group)"}, {BaseDistinguishedName: "ou=people,dc=example,dc=com"}}})
+	bases, err = module.searchBases()
+	require.NoError(t, err)
+	require.Equal(t, "(objectClass=group)", bases[0].Filter)
+	require.Equal(t, "(objectClass=person)", bases[1].Filter)
+}
+
+func TestSecretsOfUncachedOptions(t *testing.T) {
+	module := NewModule(ModuleConfig{BindDistinguishedName: "cn=admin,dc=example,dc=com", BindPassword: "secret"})
+
+	// drilldown entries outside of the search bases are not cached, the bind password is shared by all options
+	option, err := recon.SecretOptionById(module, nil, "cn=admins,ou=other,dc=example,dc=com")
+	require.NoError(t, err)
+	env, err := recon.SecretEnv(module, option)
+	require.NoError(t, err)
+	require.Equal(t, []string{secretBindPassword + "=secret"}, env)
+}
+
+func TestTLSConfig(t *testing.T) {
+	module := NewModule(ModuleConfig{Host: "ldaps://ldap.example.com:636"})
+	config, err := module.tlsConfig()
+	require.NoError(t, err)
+	require.Equal(t, "ldap.example.com", config.ServerName)
+	require.Nil(t, config.RootCAs)
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(caFile, []byte("invalid"), 0o600))
+	module = NewModule(ModuleConfig{Host: "ldap://ldap.example.com", CACertificate: caFile})
+	_, err = module.tlsConfig()
+	require.Error(t, err)
+}
@@ -0,0 +1,269 @@
+package ldap
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// MembershipMemberOf resolves the groups using the memberOf attribute of the entry and its groups
+	MembershipMemberOf = "memberof"
+	// MembershipMember resolves the groups by searching for groups with a member / uniqueMember attribute referencing the entry
+	MembershipMember = "member"
+	// MembershipInChain uses the Active Directory LDAP_MATCHING_RULE_IN_CHAIN to resolve nested groups with a single search
+	MembershipInChain = "in-chain"
+	// MembershipNone disables the membership resolution
+	MembershipNone = "none"
+)
+
+// matchingRuleInChain is the oid of LDAP_MATCHING_RULE_IN_CHAIN
+const matchingRuleInChain = "1.2.840.113556.1.4.1941"
+
+// membershipSeparator separates the dns in the groups and members context values
+const membershipSeparator = "; "
+
+// maxNestedGroups limits the number of groups that are read to resolve nested memberships
+const maxNestedGroups = 100
+
+// groupFilter matches entries with one of the groupObjectClasses
+const groupFilter = "(|(objectClass=group)(objectClass=groupOfNames)(objectClass=groupOfUniqueNames))"
+
+// membershipAttributes are requested for the selected entry
+var membershipAttributes = []string{"objectClass", "memberOf", "member", "uniqueMember"}
+
+var groupObjectClasses = []string{"group", "groupofnames", "groupofuniquenames"}
+
+// groupMemberAttributes are requested for nested groups
+var groupMemberAttributes = []string{"member", "uniqueMember"}
+
+type membershipResolver struct {
+	client ldap.Client
+	module Module
+	mode   string
+	baseDN string
+}
+
+// groups returns the dns of all groups the entry is a member of, including nested groups
+func (r membershipResolver) groups(entry *ldap.Entry) ([]string, error) {
+	switch r.mode {
+	case MembershipInChain:
+		return r.searchDNs(fmt.Sprintf("(member:%s:=%s)", matchingRuleInChain, ldap.EscapeFilter(entry.DN)))
+	case MembershipMember:
+		groupsOf := func(dn string) ([]string, error) {
+			return r.searchDNs(fmt.Sprintf("(|(member=%[1]s)(uniqueMember=%[1]s))", ldap.EscapeFilter(dn)))
+		}
+		direct, err := groupsOf(entry.DN)
+		if err != nil {
+			return nil, err
+		}
+		return r.expand(entry.DN, direct, groupsOf)
+	default:
+		return r.expand(entry.DN, entry.GetAttributeValues("memberOf"), func(dn string) ([]string, error) {
+			group, err := r.lookup(dn, "(objectClass=*)", []string{"memberOf"})
+			if err != nil || group == nil {
+				return nil, err
+			}
+			return group.GetAttributeValues("memberOf"), nil
+		})
+	}
+}
+
+// members returns the dns of all members of the group, members of nested groups are included
+// only members that are groups are read, up to maxNestedGroups
+func (r membershipResolver) members(entry *ldap.Entry) ([]string, error) {
+	if r.mode == MembershipInChain {
+		return r.searchDNs(fmt.Sprintf("(memberOf:%s:=%s)", matchingRuleInChain, ldap.EscapeFilter(entry.DN)))
+	}
+
+	nestedGroups, err := r.nestedGroups()
+	if err != nil {
+		return nil, err
+	}
+
+	visited := map[string]bool{strings.ToLower(entry.DN): true}
+	members := make(map[string]string)
+	queue := []*ldap.Entry{entry}
+	for expanded := 0; len(queue) > 0; expanded++ {
+		group := queue[0]
+		queue = queue[1:]
+		for _, dn := range memberDNs(group) {
+			if !strings.EqualFold(dn, entry.DN) {
+				members[strings.ToLower(dn)] = dn
+			}
+		}
+
+		if expanded == maxNestedGroups {
+			log.Warn().Str("dn", entry.DN).Int("limit", maxNestedGroups).Msg("too many nested ldap groups, members of deeper groups are not resolved")
+		}
+		if expanded >= maxNestedGroups {
+			continue
+		}
+		nested, err := nestedGroups(group)
+		if err != nil {
+			return nil, err
+		}
+		for _, n := range nested {
+			if !visited[strings.ToLower(n.DN)] {
+				visited[strings.ToLower(n.DN)] = true
+				queue = append(queue, n)
+			}
+		}
+	}
+
+	var result []string
+	for _, dn := range members {
+		result = append(result, dn)
+	}
+	slices.SortFunc(result, func(a, b string) int {
+		return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+	})
+	return result, nil
+}
+
+// nestedGroups returns a function to read the groups that are members of a group, with their member attributes
+// memberof uses a single search per group, member first lists all groups to only read the members that are groups
+func (r membershipResolver) nestedGroups() (func(group *ldap.Entry) ([]*ldap.Entry, error), error) {
+	if r.mode != MembershipMember {
+		return func(group *ldap.Entry) ([]*ldap.Entry, error) {
+			return r.module.search(r.client, r.baseDN, fmt.Sprintf("(&%s(memberOf=%s))", groupFilter, ldap.EscapeFilter(group.DN)), groupMemberAttributes)
+		}, nil
+	}
+
+	groupDNs, err := r.searchDNs(fmt.Sprintf("(&%s(|(member=*)(uniqueMember=*)))", groupFilter))
+	if err != nil {
+		return nil, err
+	}
+	groups := make(map[string]bool)
+	for _, dn := range groupDNs {
+		groups[strings.ToLower(dn)] = true
+	}
+
+	return func(group *ldap.Entry) ([]*ldap.Entry, error) {
+		var result []*ldap.Entry
+		for _, dn := range memberDNs(group) {
+			if !groups[strings.ToLower(dn)] {
+				continue
+			}
+			nested, err := r.lookup(dn, "(objectClass=*)", groupMemberAttributes)
+			if err != nil {
+				return nil, err
+			}
+			if nested != nil {
+				result = append(result, nested)
+			}
+		}
+		return result, nil
+	}, nil
+}
+
+// expand resolves the dns breadth-first using next, the dn of the entry itself is never part of the result
+// next is called for up to maxNestedGroups dns, the remaining dns are included without being expanded
+func (r membershipResolver) expand(entryDN string, start []string, next func(dn string) ([]string, error)) ([]string, error) {
+	visited := map[string]bool{strings.ToLower(entryDN): true}
+	var result []string
+
+	queue := start
+	for expanded := 0; len(queue) > 0; {
+		dn := queue[0]
+		queue = queue[1:]
+		if visited[strings.ToLower(dn)] {
+			continue
+		}
+		visited[strings.ToLower(dn)] = true
+		result = append(result, dn)
+
+		if expanded == maxNestedGroups {
+			log.Warn().Str("dn", entryDN).Int("limit", maxNestedGroups).Msg("too many nested ldap groups, memberships of deeper groups are not resolved")
+		}
+		expanded++
+		if expanded > maxNestedGroups {
+			continue
+		}
+		found, err := next(dn)
+		if err != nil {
+			return nil, err
+		}
+		queue = append(queue, found...)
+	}
+
+	slices.SortFunc(result, func(a, b string) int {
+		return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+	})
+	return result, nil
+}
+
+// searchDNs returns the dns of all entries below the base dn that match the filter
+func (r membershipResolver) searchDNs(filter string) ([]string, error) {
+	entries, err := r.module.search(r.client, r.baseDN, filter, []string{"1.1"})
+	if err != nil {
+		return nil, err
+	}
+
+	var result []string
+	for _, e := range entries {
+		result = append(result, e.DN)
+	}
+	return result, nil
+}
+
+// lookup reads a single entry, returns nil if the entry doesn't exist or doesn't match the filter
+func (r membershipResolver) lookup(dn string, filter string, attributes []string) (*ldap.Entry, error) {
+	sr, err := r.client.Search(ldap.NewSearchRequest(dn, ldap.ScopeBaseObject, ldap.NeverDerefAliases, 0, 0, false, filter, attributes, nil))
+	if ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) {
+		log.Debug().Str("dn", dn).Msg("referenced ldap entry does not exist")
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", dn, err)
+	}
+
+	if len(sr.Entries) == 0 {
+		return nil, nil
+	}
+	return sr.Entries[0], nil
+}
+
+// isGroup checks if the entry is a group based on its object class or member attributes
+func isGroup(entry *ldap.Entry) bool {
+	if len(memberDNs(entry)) > 0 {
+		return true
+	}
+	for _, objectClass := range entry.GetAttributeValues("objectClass") {
+		if slices.Contains(groupObjectClasses, strings.ToLower(objectClass)) {
+			return true
+		}
+	}
+	return false
+}
+
+func memberDNs(entry *ldap.Entry) []string {
+	return append(entry.GetAttributeValues("member"), entry.GetAttributeValues("uniqueMember")...)
+}
+
+// namingContext returns the domain components of the dn (e.g. dc=example,dc=com), used as search base for the membership resolution
+func namingContext(dn string) string {
+	parsed, err := ldap.ParseDN(dn)
+	if err != nil {
+		return ""
+	}
+
+	var components []string
+	for _, rdn := range parsed.RDNs {
+		if len(rdn.Attributes) == 1 && strings.EqualFold(rdn.Attributes[0].Type, "dc") {
+			components = append(components, "dc="+rdn.Attributes[0].Value)
+		}
+	}
+	return strings.Join(components, ",")
+}
+
+// commonName returns the value of the first rdn, e.g. admins for cn=admins,ou=groups,dc=example,dc=com
+func commonName(dn string) string {
+	parsed, err := ldap.ParseDN(dn)
+	if err != nil || len(parsed.RDNs) == 0 || len(parsed.RDNs[0].Attributes) == 0 {
+		return dn
+	}
+	return parsed.RDNs[0].Attributes[0].Value
+}
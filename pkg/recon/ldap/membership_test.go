@@ -0,0 +1,162 @@
+package ldap
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/PhilippHeuer/fuzzmux/pkg/recon"
+	"github.com/go-ldap/ldap/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClient serves searches from a static directory, only the filters used by the membership resolution are supported
+type fakeClient struct {
+	ldap.Client
+	entries []*ldap.Entry
+	filters []string
+}
+
+func (c *fakeClient) Search(req *ldap.SearchRequest) (*ldap.SearchResult, error) {
+	c.filters = append(c.filters, req.Filter)
+	result := &ldap.SearchResult{}
+	for _, e := range c.entries {
+		if req.Scope == ldap.ScopeBaseObject && !strings.EqualFold(e.DN, req.BaseDN) {
+			continue
+		}
+		if req.Scope != ldap.ScopeBaseObject && !strings.HasSuffix(e.DN, req.BaseDN) {
+			continue
+		}
+
+		if fakeMatch(req.Filter, e) {
+			result.Entries = append(result.Entries, e)
+		}
+	}
+
+	if req.Scope == ldap.ScopeBaseObject && len(result.Entries) == 0 && req.Filter == "(objectClass=*)" {
+		return nil, ldap.NewError(ldap.LDAPResultNoSuchObject, nil)
+	}
+	return result, nil
+}
+
+func (c *fakeClient) SearchWithPaging(req *ldap.SearchRequest, _ uint32) (*ldap.SearchResult, error) {
+	return c.Search(req)
+}
+
+func fakeMatch(filter string, e *ldap.Entry) bool {
+	switch {
+	case strings.HasPrefix(filter, "(&"+groupFilter):
+		return isGroup(e) && fakeMatch(strings.TrimSuffix(strings.TrimPrefix(filter, "(&"+groupFilter), ")"), e)
+	case strings.HasPrefix(filter, "(memberOf="):
+		return slices.Contains(e.GetAttributeValues("memberOf"), strings.TrimSuffix(strings.TrimPrefix(filter, "(memberOf="), ")"))
+	case filter == "(objectClass=*)":
+		return true
+	case filter == "(|(member=*)(uniqueMember=*))":
+		return len(memberDNs(e)) > 0
+	case strings.HasPrefix(filter, "(|(member="):
+		dn := strings.TrimPrefix(filter[:strings.Index(filter, ")(uniqueMember=")], "(|(member=")
+		return slices.Contains(memberDNs(e), dn)
+	}
+	return false
+}
+
+func testDirectory() *fakeClient {
+	return &fakeClient{entries: []*ldap.Entry{
+		ldap.NewEntry("uid=jane,ou=people,dc=example,dc=com", map[string][]string{"objectClass": {"person"}, "memberOf": {"cn=dev,ou=groups,dc=example,dc=com"}}),
+		ldap.NewEntry("uid=john,ou=people,dc=example,dc=com", map[string][]string{"objectClass": {"person"}, "memberOf": {"cn=all,ou=groups,dc=example,dc=com"}}),
+		ldap.NewEntry("cn=dev,ou=groups,dc=example,dc=com", map[string][]string{"objectClass": {"groupOfNames"}, "member": {"uid=jane,ou=people,dc=example,dc=com"}, "memberOf": {"cn=all,ou=groups,dc=example,dc=com"}}),
+		// nested groups may contain cycles
+		ldap.NewEntry("cn=all,ou=groups,dc=example,dc=com", map[string][]string{"objectClass": {"groupOfNames"}, "member": {"cn=dev,ou=groups,dc=example,dc=com", "uid=john,ou=people,dc=example,dc=com", "uid=deleted,ou=people,dc=example,dc=com"}, "memberOf": {"cn=dev,ou=groups,dc=example,dc=com"}}),
+	}}
+}
+
+func TestMembershipMemberOf(t *testing.T) {
+	client := testDirectory()
+	resolver := membershipResolver{client: client, module: NewModule(ModuleConfig{}), mode: MembershipMemberOf, baseDN: "dc=example,dc=com"}
+
+	groups, err := resolver.groups(client.entries[0])
+	require.NoError(t, err)
+	assert.Equal(t, []string{"cn=all,ou=groups,dc=example,dc=com", "cn=dev,ou=groups,dc=example,dc=com"}, groups)
+
+	members, err := resolver.members(client.entries[3])
+	require.NoError(t, err)
+	assert.Equal(t, []string{"cn=dev,ou=groups,dc=example,dc=com", "uid=deleted,ou=people,dc=example,dc=com", "uid=jane,ou=people,dc=example,dc=com", "uid=john,ou=people,dc=example,dc=com"}, members)
+	assert.True(t, isGroup(client.entries[3]))
+	assert.False(t, isGroup(client.entries[0]))
+}
+
+func TestMembershipMember(t *testing.T) {
+	client := testDirectory()
+	resolver := membershipResolver{client: client, module: NewModule(ModuleConfig{}), mode: MembershipMember, baseDN: "dc=example,dc=com"}
+
+	groups, err := resolver.groups(client.entries[0])
+	require.NoError(t, err)
+	assert.Equal(t, []string{"cn=all,ou=groups,dc=example,dc=com", "cn=dev,ou=groups,dc=example,dc=com"}, groups)
+
+	client.filters = nil
+	members, err := resolver.members(client.entries[3])
+	require.NoError(t, err)
+	assert.Equal(t, []string{"cn=dev,ou=groups,dc=example,dc=com", "uid=deleted,ou=people,dc=example,dc=com", "uid=jane,ou=people,dc=example,dc=com", "uid=john,ou=people,dc=example,dc=com"}, members)
+	// only the nested group is read, users are never looked up
+	assert.Equal(t, []string{groupFilterOf("(|(member=*)(uniqueMember=*))"), "(objectClass=*)"}, client.filters)
+}
+
+func TestMembershipNestedGroupLimit(t *testing.T) {
+	client := &fakeClient{}
+	for i := 0; i <= maxNestedGroups+1; i++ {
+		client.entries = append(client.entries, ldap.NewEntry(fmt.Sprintf("cn=g%d,dc=example,dc=com", i), map[string][]string{
+			"objectClass": {"group"},
+			"member":      {fmt.Sprintf("cn=g%d,dc=example,dc=com", i+1)},
+			"memberOf":    {fmt.Sprintf("cn=g%d,dc=example,dc=com", i-1)},
+		}))
+	}
+	resolver := membershipResolver{client: client, module: NewModule(ModuleConfig{}), mode: MembershipMemberOf, baseDN: "dc=example,dc=com"}
+
+	members, err := resolver.members(client.entries[0])
+	require.NoError(t, err)
+	assert.Len(t, client.filters, maxNestedGroups)
+	assert.Len(t, members, maxNestedGroups+1)
+}
+
+func groupFilterOf(filter string) string {
+	return "(&" + groupFilter + filter + ")"
+}
+
+func TestMembershipInChain(t *testing.T) {
+	client := testDirectory()
+	resolver := membershipResolver{client: client, module: NewModule(ModuleConfig{}), mode: MembershipInChain, baseDN: "dc=example,dc=com"}
+
+	_, err := resolver.groups(client.entries[0])
+	require.NoError(t, err)
+	_, err = resolver.members(client.entries[3])
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"(member:1.2.840.113556.1.4.1941:=uid=jane,ou=people,dc=example,dc=com)",
+		"(memberOf:1.2.840.113556.1.4.1941:=cn=all,ou=groups,dc=example,dc=com)",
+	}, client.filters)
+}
+
+func TestDrilldowns(t *testing.T) {
+	// the name avoids reading an existing cache
+	module := NewModule(ModuleConfig{Name: "ldap-drilldown-test", Host: "ldap://127.0.0.1:1"})
+	option := recon.Option{Id: "cn=all,ou=groups,dc=example,dc=com", Name: "all"}
+	require.NoError(t, module.resolveMemberships(testDirectory(), &option))
+	assert.Equal(t, "cn=dev,ou=groups,dc=example,dc=com", option.Context["groups"])
+
+	drilldowns := module.Drilldowns(&option)
+	require.Len(t, drilldowns, 2)
+	assert.Equal(t, "members", drilldowns[1].Id)
+
+	options, err := module.DrilldownOptions(&option, "members")
+	require.NoError(t, err)
+	require.Len(t, options, 4)
+	assert.Equal(t, "dev", options[0].Name)
+	assert.Equal(t, "cn=dev,ou=groups,dc=example,dc=com", options[0].Id)
+}
+
+func TestDNHelpers(t *testing.T) {
+	assert.Equal(t, "dc=example,dc=com", namingContext("uid=jane,ou=people,dc=example,dc=com"))
+	assert.Equal(t, "admins", commonName("cn=admins,ou=groups,dc=example,dc=com"))
+}
@@ -0,0 +1,123 @@
+package recon
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"text/template"
+)
+
+// RenderPreview renders the preview of the option, the details are rendered by the module if it implements PreviewModule
+func RenderPreview(module Module, option *Option) string {
+	if pm, ok := module.(PreviewModule); ok {
+		return option.renderPreview(pm.Preview(option))
+	}
+	return option.RenderPreview()
+}
+
+// RenderPreview renders the default preview, which lists all context values
+func (o *Option) RenderPreview() string {
+	var builder strings.Builder
+	if len(o.Context) > 0 {
+		builder.WriteString("Context:\n")
+		keys := make([]string, 0, len(o.Context))
+		for k := range o.Context {
+			keys = append(keys, k)
+		}
+		slices.Sort(keys)
+		for _, k := range keys {
+			builder.WriteString(fmt.Sprintf("  %s: %s\n", k, o.Context[k]))
+		}
+	}
+
+	return o.renderPreview(builder.String())
+}
+
+func (o *Option) renderPreview(details string) string {
+	var builder strings.Builder
+	builder.WriteString("# " + o.DisplayName + "\n\n")
+	builder.WriteString(fmt.Sprintf("Provider: %s [TYPE: %s]\n", o.ProviderName, o.ProviderType))
+	builder.WriteString("Directory: " + o.ResolveStartDirectory(true) + " [" + o.StartDirectory + "]\n")
+	if len(o.Tags) > 0 {
+		builder.WriteString("\nTags:\n")
+		for _, t := range o.Tags {
+			builder.WriteString("- " + t + "\n")
+		}
+	}
+
+	// module specific details
+	builder.WriteString("\n" + details)
+
+	// web url
+	if o.Web != "" {
+		builder.WriteString("\nURL: " + o.Web + "\n")
+	}
+
+	// free-text description (with fallback to context)
+	if o.Description != "" {
+		builder.WriteString("\n\n" + o.Description + "\n")
+	} else if o.Context["description"] != "" {
+		builder.WriteString("\n\n" + o.Context["description"] + "\n")
+	}
+
+	return builder.String()
+}
+
+// RenderPreviewTemplate renders a user-defined preview using text/template, the option is passed as data (e.g. {{ .Name }}, {{ .Context.status }} or {{ join .Tags ", " }})
+// the default preview of the module is available as {{ .Preview }}
+func RenderPreviewTemplate(module Module, option *Option, tmpl string) (string, error) {
+	t, err := template.New(option.ProviderName).Funcs(previewFuncs).Option("missingkey=zero").Parse(tmpl)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse preview template: %w", err)
+	}
+
+	data := struct {
+		*Option
+		Preview string
+	}{
+		Option:  option,
+		Preview: RenderPreview(module, option),
+	}
+
+	var builder strings.Builder
+	if err = t.Execute(&builder, data); err != nil {
+		return "", fmt.Errorf("failed to render preview template: %w", err)
+	}
+
+	return builder.String(), nil
+}
+
+var previewFuncs = template.FuncMap{
+	"join":      strings.Join,
+	"split":     strings.Split,
+	"upper":     strings.ToUpper,
+	"lower":     strings.ToLower,
+	"trim":      strings.TrimSpace,
+	"contains":  slices.Contains[[]string],
+	"hasPrefix": strings.HasPrefix,
+	"default": func(fallback string, value string) string {
+		if value == "" {
+			return fallback
+		}
+		return value
+	},
+}
+
+// PreviewField writes a line to the preview if the value is not empty
+func PreviewField(builder *strings.Builder, label string, value string) {
+	if value != "" {
+		builder.WriteString(fmt.Sprintf("%s: %s\n", label, value))
+	}
+}
+
+// PreviewList writes a list to the preview if it's not empty, e.g. the members of a group
+func PreviewList(builder *strings.Builder, label string, values []string) {
+	if len(values) == 0 {
+		return
+	}
+
+	builder.WriteString(fmt.Sprintf("%s (%d):\n", label, len(values)))
+	for _, v := range values {
+		builder.WriteString("- " + v + "\n")
+	}
+}
@@ -0,0 +1,72 @@
+package recon
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type previewModule struct {
+	Module
+}
+
+func (m previewModule) Name() string {
+	return "test"
+}
+
+func (m previewModule) Preview(option *Option) string {
+	var builder strings.Builder
+	PreviewField(&builder, "Status", option.Context["status"])
+	PreviewField(&builder, "Empty", option.Context["missing"])
+	PreviewList(&builder, "Members", []string{"jane", "john"})
+	return builder.String()
+}
+
+var previewOption = Option{
+	ProviderName:   "test",
+	ProviderType:   "test",
+	Id:             "DEV-1",
+	DisplayName:    "DEV-1: Fix login",
+	Name:           "DEV-1",
+	Web:            "https://example.com/DEV-1",
+	StartDirectory: "/tmp",
+	Tags:           []string{"jira", "ticket"},
+	Context:        map[string]string{"status": "Open", "assignee": "Jane"},
+}
+
+func TestRenderPreviewDefault(t *testing.T) {
+	option := previewOption
+	preview := RenderPreview(nil, &option)
+
+	assert.Contains(t, preview, "# DEV-1: Fix login\n")
+	assert.Contains(t, preview, "Tags:\n- jira\n- ticket\n")
+	assert.Contains(t, preview, "Context:\n  assignee: Jane\n  status: Open\n")
+	assert.Contains(t, preview, "URL: https://example.com/DEV-1\n")
+}
+
+func TestRenderPreviewModule(t *testing.T) {
+	option := previewOption
+	preview := RenderPreview(previewModule{}, &option)
+
+	assert.Contains(t, preview, "Status: Open\nMembers (2):\n- jane\n- john\n")
+	assert.NotContains(t, preview, "Empty:")
+	assert.NotContains(t, preview, "Context:")
+}
+
+func TestRenderPreviewTemplate(t *testing.T) {
+	option := previewOption
+	preview, err := RenderPreviewTemplate(previewModule{}, &option, `## {{ .Name }} ({{ upper .Context.status }})
+{{ join .Tags ", " }} - {{ default "unassigned" .Context.reviewer }}
+{{ if contains .Tags "ticket" }}{{ .Preview }}{{ end }}`)
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(preview, "## DEV-1 (OPEN)\njira, ticket - unassigned\n# DEV-1: Fix login"))
+	assert.Contains(t, preview, "Status: Open\n")
+
+	_, err = RenderPreviewTemplate(previewModule{}, &option, "{{ .Name ")
+	assert.Error(t, err)
+	_, err = RenderPreviewTemplate(previewModule{}, &option, "{{ .Unknown }}")
+	assert.Error(t, err)
+}
@@ -0,0 +1,135 @@
+package project
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+
+	"github.com/PhilippHeuer/fuzzmux/pkg/recon"
+	"github.com/PhilippHeuer/fuzzmux/pkg/util"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	actionClone    = "clone"
+	actionTemplate = "template"
+)
+
+// actionOptions returns an option to clone a repository for every source directory with cloning enabled, and an option for every project template
+func (p Module) actionOptions() []recon.Option {
+	var result []recon.Option
+
+	for i, source := range p.Config.SourceDirectories {
+		dir := util.ResolvePath(source.Directory)
+		tags := util.AddToSet(slices.Clone(source.Tags), "action")
+
+		if source.Clone || source.CloneTemplate != "" {
+			result = append(result, recon.Option{
+				ProviderName:   p.Name(),
+				ProviderType:   p.Type(),
+				Id:             "clone:" + dir,
+				DisplayName:    fmt.Sprintf("Clone repository into %s", source.Directory),
+				Name:           "clone",
+				StartDirectory: dir,
+				Tags:           tags,
+				Context: map[string]string{
+					"action":      actionClone,
+					"sourceIndex": strconv.Itoa(i),
+				},
+			})
+		}
+
+		if source.TemplateDirectory != "" {
+			templateDir := util.ResolvePath(source.TemplateDirectory)
+			entries, err := os.ReadDir(templateDir)
+			if err != nil {
+				log.Warn().Err(err).Str("directory", templateDir).Msg("failed to read project templates")
+				continue
+			}
+
+			for _, entry := range entries {
+				if !entry.IsDir() {
+					continue
+				}
+
+				result = append(result, recon.Option{
+					ProviderName:   p.Name(),
+					ProviderType:   p.Type(),
+					Id:             "template:" + dir + ":" + entry.Name(),
+					DisplayName:    fmt.Sprintf("New project from template %s in %s", entry.Name(), source.Directory),
+					Name:           entry.Name(),
+					StartDirectory: dir,
+					Tags:           tags,
+					Context: map[string]string{
+						"action":      actionTemplate,
+						"sourceIndex": strconv.Itoa(i),
+						"template":    filepath.Join(templateDir, entry.Name()),
+					},
+				})
+			}
+		}
+	}
+
+	return result
+}
+
+func (p Module) InputPrompt(option *recon.Option) string {
+	switch option.Context["action"] {
+	case actionClone:
+		return "Repository (url or owner/repo)"
+	case actionTemplate:
+		return "Project name"
+	}
+
+	return ""
+}
+
+// ApplyInput clones the repository or creates the project from the template, the option is updated to open the new project
+func (p Module) ApplyInput(option *recon.Option, input string) error {
+	i, err := strconv.Atoi(option.Context["sourceIndex"])
+	if err != nil || i < 0 || i >= len(p.Config.SourceDirectories) {
+		return fmt.Errorf("source directory %q is not configured", option.Context["sourceIndex"])
+	}
+	source := p.Config.SourceDirectories[i]
+
+	var path string
+	switch option.Context["action"] {
+	case actionClone:
+		ref, parseErr := ParseRepositoryQuery(input, p.Config.CloneHost, p.Config.CloneProtocol)
+		if parseErr != nil {
+			return parseErr
+		}
+		path, err = CloneRepository(source, ref)
+	case actionTemplate:
+		path, err = CreateFromTemplate(source, option.Context["template"], input)
+	default:
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	// replace the action with the new project
+	project := newProject(SourceDirectory{Directory: util.ResolvePath(source.Directory), Tags: source.Tags}, path)
+	*option = recon.Option{
+		ProviderName:   p.Name(),
+		ProviderType:   p.Type(),
+		Id:             project.Path,
+		DisplayName:    renderProjectDisplayName(project, p.Config.DisplayFormat),
+		Name:           project.Name,
+		StartDirectory: project.Path,
+		Tags:           slices.Clone(project.Tags),
+		Context: map[string]string{
+			"projectKind": string(project.Kind),
+			"layout":      moduleType,
+		},
+	}
+	if repo, err := openGitRepository(project.Path); err == nil {
+		applyGitInfo(option, repo.Info(gitInfoRefs))
+	}
+	option.ProcessUserTemplateStrings(p.Config.DisplayName, p.Config.StartDirectory)
+
+	return p.SelectOption(option)
+}
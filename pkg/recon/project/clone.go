@@ -0,0 +1,201 @@
+package project
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/PhilippHeuer/fuzzmux/pkg/util"
+	"github.com/rs/zerolog/log"
+)
+
+const defaultCloneTemplate = "{{host}}/{{owner}}/{{repo}}"
+
+const defaultCloneHost = "github.com"
+
+var ErrProjectExists = errors.New("project directory already exists")
+
+// RepositoryRef identifies a remote repository
+type RepositoryRef struct {
+	URL   string // URL is used to clone the repository
+	Host  string // Host is the hostname of the git server, e.g. github.com
+	Owner string // Owner is the user, organization or group (including subgroups)
+	Repo  string // Repo is the name of the repository
+}
+
+// ParseRepositoryQuery parses a git url (https, ssh, scp-like) or a shorthand query (host/owner/repo, owner/repo)
+func ParseRepositoryQuery(query string, defaultHost string, protocol string) (RepositoryRef, error) {
+	query = strings.TrimSpace(query)
+	if defaultHost == "" {
+		defaultHost = defaultCloneHost
+	}
+
+	ref := RepositoryRef{URL: query}
+	var path string
+	if host, remotePath := parseRemoteURL(query); host != "" && (strings.Contains(query, "://") || strings.Contains(query, "@")) {
+		ref.Host = host
+		path = remotePath
+	} else {
+		segments := strings.Split(strings.TrimSuffix(strings.Trim(query, "/"), ".git"), "/")
+		if len(segments) >= 3 && strings.Contains(segments[0], ".") {
+			ref.Host = segments[0]
+			segments = segments[1:]
+		} else {
+			ref.Host = defaultHost
+		}
+		path = strings.Join(segments, "/")
+
+		if protocol == "ssh" {
+			ref.URL = fmt.Sprintf("git@%s:%s.git", ref.Host, path)
+		} else {
+			ref.URL = fmt.Sprintf("https://%s/%s.git", ref.Host, path)
+		}
+	}
+
+	idx := strings.LastIndex(path, "/")
+	if idx <= 0 || idx == len(path)-1 {
+		return RepositoryRef{}, fmt.Errorf("invalid repository %q, expected a git url or owner/repo", query)
+	}
+	ref.Owner = path[:idx]
+	ref.Repo = path[idx+1:]
+	if err := validateRepositoryRef(ref); err != nil {
+		return RepositoryRef{}, err
+	}
+
+	return ref, nil
+}
+
+// validateRepositoryRef rejects empty, . and .. path segments, which would escape the source directory of the clone
+func validateRepositoryRef(ref RepositoryRef) error {
+	segments := append([]string{ref.Host, ref.Repo}, strings.Split(ref.Owner, "/")...)
+	for _, segment := range segments {
+		if segment == "" || segment == "." || segment == ".." || strings.ContainsAny(segment, `/\`) {
+			return fmt.Errorf("invalid repository %s/%s/%s, path segments must not be empty, . or ..", ref.Host, ref.Owner, ref.Repo)
+		}
+	}
+	return nil
+}
+
+// ClonePath renders the clone template of the source directory for the repository, the path must be inside the source directory
+func ClonePath(source SourceDirectory, ref RepositoryRef) (string, error) {
+	if err := validateRepositoryRef(ref); err != nil {
+		return "", err
+	}
+
+	template := source.CloneTemplate
+	if template == "" {
+		template = defaultCloneTemplate
+	}
+
+	path := util.ExpandPlaceholders(template, "host", ref.Host)
+	path = util.ExpandPlaceholders(path, "owner", ref.Owner)
+	path = util.ExpandPlaceholders(path, "repo", ref.Repo)
+
+	dir := filepath.Clean(util.ResolvePath(source.Directory))
+	target := filepath.Join(dir, filepath.FromSlash(path))
+	if rel, err := filepath.Rel(dir, target); err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("clone path %s is not inside of %s", target, dir)
+	}
+
+	return target, nil
+}
+
+// CloneRepository clones the repository into the source directory, an existing clone is reused
+func CloneRepository(source SourceDirectory, ref RepositoryRef) (string, error) {
+	target, err := ClonePath(source, ref)
+	if err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(filepath.Join(target, ".git")); err == nil {
+		log.Debug().Str("path", target).Msg("repository is already cloned")
+		return target, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return "", fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	log.Info().Str("url", ref.URL).Str("path", target).Msg("cloning repository")
+	cmd := exec.Command("git", "clone", "--", ref.URL, target)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("failed to clone %s: %w", ref.URL, err)
+	}
+
+	return target, nil
+}
+
+// CreateFromTemplate copies the template directory into a new project in the source directory
+func CreateFromTemplate(source SourceDirectory, templateDir string, name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		return "", fmt.Errorf("invalid project name %q", name)
+	}
+
+	target := filepath.Join(util.ResolvePath(source.Directory), name)
+	if _, err := os.Stat(target); err == nil {
+		return "", fmt.Errorf("%w: %s", ErrProjectExists, target)
+	}
+
+	err := filepath.WalkDir(templateDir, func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() && entry.Name() == ".git" {
+			return filepath.SkipDir
+		}
+
+		rel, err := filepath.Rel(templateDir, path)
+		if err != nil {
+			return err
+		}
+		dest := filepath.Join(target, rel)
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		switch {
+		case entry.IsDir():
+			return os.MkdirAll(dest, info.Mode().Perm()|0700)
+		case info.Mode()&os.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, dest)
+		default:
+			return copyFile(path, dest, info.Mode().Perm())
+		}
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to copy template %s: %w", templateDir, err)
+	}
+
+	return target, nil
+}
+
+func copyFile(src string, dest string, perm os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dest, os.O_CREATE|os.O_WRONLY|os.O_EXCL, perm)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+
+	return out.Close()
+}
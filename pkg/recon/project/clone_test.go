@@ -0,0 +1,109 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/PhilippHeuer/fuzzmux/pkg/recon"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRepositoryQuery(t *testing.T) {
+	tests := []struct {
+		query    string
+		protocol string
+		want     RepositoryRef
+	}{
+		{"PhilippHeuer/fuzzmux", "", RepositoryRef{URL: "https://github.com/PhilippHeuer/fuzzmux.git", Host: "github.com", Owner: "PhilippHeuer", Repo: "fuzzmux"}},
+		{"PhilippHeuer/fuzzmux", "ssh", RepositoryRef{URL: "git@github.com:PhilippHeuer/fuzzmux.git", Host: "github.com", Owner: "PhilippHeuer", Repo: "fuzzmux"}},
+		{"gitlab.com/group/sub/repo", "", RepositoryRef{URL: "https://gitlab.com/group/sub/repo.git", Host: "gitlab.com", Owner: "group/sub", Repo: "repo"}},
+		{"git@gitea.example.com:owner/repo.git", "", RepositoryRef{URL: "git@gitea.example.com:owner/repo.git", Host: "gitea.example.com", Owner: "owner", Repo: "repo"}},
+		{"https://github.com/cidverse/repoanalyzer", "", RepositoryRef{URL: "https://github.com/cidverse/repoanalyzer", Host: "github.com", Owner: "cidverse", Repo: "repoanalyzer"}},
+	}
+	for _, tt := range tests {
+		ref, err := ParseRepositoryQuery(tt.query, "", tt.protocol)
+		require.NoError(t, err, tt.query)
+		assert.Equal(t, tt.want, ref, tt.query)
+	}
+
+	_, err := ParseRepositoryQuery("fuzzmux", "", "")
+	assert.Error(t, err)
+
+	// path segments that escape the source directory
+	for _, query := range []string{"https://github.com/../../x", "git@github.com:owner/...git", "../owner/repo", "owner/..", "./repo"} {
+		_, err = ParseRepositoryQuery(query, "", "")
+		assert.Error(t, err, query)
+	}
+}
+
+func TestClonePath(t *testing.T) {
+	ref := RepositoryRef{Host: "github.com", Owner: "PhilippHeuer", Repo: "fuzzmux"}
+
+	path, err := ClonePath(SourceDirectory{Directory: "/src"}, ref)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join("/src", "github.com", "PhilippHeuer", "fuzzmux"), path)
+	path, err = ClonePath(SourceDirectory{Directory: "/src", CloneTemplate: "{{repo}}"}, ref)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join("/src", "fuzzmux"), path)
+
+	// refs from module data are validated as well
+	_, err = ClonePath(SourceDirectory{Directory: "/src"}, RepositoryRef{Host: "github.com", Owner: "..", Repo: ".."})
+	assert.Error(t, err)
+	_, err = ClonePath(SourceDirectory{Directory: "/src", CloneTemplate: "../{{repo}}"}, ref)
+	assert.Error(t, err)
+}
+
+func TestApplyInputClone(t *testing.T) {
+	remote := setupRepository(t)
+	root := t.TempDir()
+	module := NewModule(ModuleConfig{
+		SourceDirectories: []SourceDirectory{{Directory: root, Depth: 3, Clone: true}},
+		DisableIndex:      true,
+	})
+
+	actions := module.actionOptions()
+	require.Len(t, actions, 1)
+	option := actions[0]
+	assert.Equal(t, "Repository (url or owner/repo)", module.InputPrompt(&option))
+
+	// clone from a local remote into the default layout, the shorthand query is covered by TestParseRepositoryQuery
+	path, err := CloneRepository(module.Config.SourceDirectories[0], RepositoryRef{URL: remote, Host: "example.com", Owner: "octo", Repo: "repo"})
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(root, "example.com", "octo", "repo"), path)
+	assert.FileExists(t, filepath.Join(path, "README.md"))
+
+	// existing clones are reused
+	require.NoError(t, module.ApplyInput(&option, "https://example.com/octo/repo.git"))
+	assert.Equal(t, path, option.StartDirectory)
+	assert.Equal(t, "project", option.Context["layout"])
+	assert.Equal(t, "main", option.Context["gitBranch"])
+	assert.Empty(t, module.InputPrompt(&option))
+}
+
+func TestApplyInputTemplate(t *testing.T) {
+	root := t.TempDir()
+	templates := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(templates, "go-cli", "cmd"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(templates, "go-cli", "cmd", "main.go"), []byte("package main\n"), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(templates, "go-cli", ".git"), 0755))
+
+	module := NewModule(ModuleConfig{
+		SourceDirectories: []SourceDirectory{{Directory: root, Depth: 1, TemplateDirectory: templates}},
+	})
+	actions := module.actionOptions()
+	require.Len(t, actions, 1)
+	assert.Equal(t, "New project from template go-cli in "+root, actions[0].DisplayName)
+
+	option := actions[0]
+	require.NoError(t, module.ApplyInput(&option, "my-tool"))
+	assert.Equal(t, filepath.Join(root, "my-tool"), option.StartDirectory)
+	assert.FileExists(t, filepath.Join(root, "my-tool", "cmd", "main.go"))
+	assert.NoDirExists(t, filepath.Join(root, "my-tool", ".git"))
+
+	// existing projects are not overwritten
+	option = actions[0]
+	assert.ErrorIs(t, module.ApplyInput(&option, "my-tool"), ErrProjectExists)
+	assert.Error(t, module.ApplyInput(&recon.Option{Context: map[string]string{"action": actionTemplate, "sourceIndex": "1"}}, "x"))
+}
@@ -0,0 +1,425 @@
+package project
+
+import (
+	"bufio"
+	"bytes"
+	"container/heap"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// maxHistoryWalk limits the number of commits visited to calculate ahead / behind counts
+const maxHistoryWalk = 10000
+
+// aheadBehindUnknown is used for the ahead / behind counts if the history walk exceeded maxHistoryWalk
+const aheadBehindUnknown = -1
+
+// gitRepository provides read-only access to a git repository, without depending on the git binary
+type gitRepository struct {
+	Dir       string // Dir is the working tree
+	GitDir    string // GitDir contains HEAD and the index, this differs from CommonDir for worktrees
+	CommonDir string // CommonDir contains the shared objects, refs and config
+	packs     []*packIndex
+	packFiles map[string]*os.File
+	objects   *objectCache
+}
+
+// GitInfo holds the git metadata of a project
+type GitInfo struct {
+	Branch            string
+	Head              string
+	RemoteURL         string
+	Upstream          string
+	Ahead             int // Ahead is aheadBehindUnknown if it couldn't be calculated
+	Behind            int // Behind is aheadBehindUnknown if it couldn't be calculated
+	Dirty             bool
+	LastCommitTime    time.Time
+	LastCommitAuthor  string
+	LastCommitMessage string
+	Status            bool // Status is true if the ahead / behind counts and the working tree status have been computed
+}
+
+// gitInfoLevel defines which git metadata is collected, higher levels include all lower levels
+type gitInfoLevel int
+
+const (
+	gitInfoRefs   gitInfoLevel = iota // gitInfoRefs reads the branch, head and remote
+	gitInfoCommit                     // gitInfoCommit reads the last commit
+	gitInfoStatus                     // gitInfoStatus walks the history to the upstream and checks the working tree, which is expensive for large repositories
+)
+
+// openGitRepository opens the repository in the given working tree, .git can be a directory or a file pointing to the git dir (worktrees, submodules)
+func openGitRepository(dir string) (*gitRepository, error) {
+	gitDir := filepath.Join(dir, ".git")
+	info, err := os.Stat(gitDir)
+	if err != nil {
+		return nil, fmt.Errorf("not a git repository: %w", err)
+	}
+
+	if !info.IsDir() {
+		content, err := os.ReadFile(gitDir)
+		if err != nil {
+			return nil, err
+		}
+		target, found := strings.CutPrefix(strings.TrimSpace(string(content)), "gitdir:")
+		if !found {
+			return nil, fmt.Errorf("invalid .git file in %s", dir)
+		}
+		gitDir = strings.TrimSpace(target)
+		if !filepath.IsAbs(gitDir) {
+			gitDir = filepath.Join(dir, gitDir)
+		}
+	}
+
+	// worktrees share objects, refs and config with the main repository
+	commonDir := gitDir
+	if content, err := os.ReadFile(filepath.Join(gitDir, "commondir")); err == nil {
+		commonDir = strings.TrimSpace(string(content))
+		if !filepath.IsAbs(commonDir) {
+			commonDir = filepath.Join(gitDir, commonDir)
+		}
+	}
+
+	return &gitRepository{
+		Dir:       dir,
+		GitDir:    filepath.Clean(gitDir),
+		CommonDir: filepath.Clean(commonDir),
+	}, nil
+}
+
+// head returns the current branch (empty if detached) and the commit hash
+func (r *gitRepository) head() (string, string, error) {
+	content, err := os.ReadFile(filepath.Join(r.GitDir, "HEAD"))
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read HEAD: %w", err)
+	}
+
+	value := strings.TrimSpace(string(content))
+	ref, symbolic := strings.CutPrefix(value, "ref: ")
+	if !symbolic {
+		return "", value, nil
+	}
+
+	hash, _ := r.resolveRef(ref)
+	return strings.TrimPrefix(ref, "refs/heads/"), hash, nil
+}
+
+// resolveRef resolves a full ref name (e.g. refs/heads/main) from loose refs or packed-refs
+func (r *gitRepository) resolveRef(ref string) (string, error) {
+	for _, dir := range []string{r.GitDir, r.CommonDir} {
+		content, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(ref)))
+		if err == nil {
+			value := strings.TrimSpace(string(content))
+			if target, symbolic := strings.CutPrefix(value, "ref: "); symbolic {
+				return r.resolveRef(target)
+			}
+			return value, nil
+		}
+	}
+
+	content, err := os.ReadFile(filepath.Join(r.CommonDir, "packed-refs"))
+	if err != nil {
+		return "", fmt.Errorf("ref %s not found", ref)
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		hash, name, found := strings.Cut(scanner.Text(), " ")
+		if found && name == ref {
+			return hash, nil
+		}
+	}
+
+	return "", fmt.Errorf("ref %s not found", ref)
+}
+
+// config parses the repository config into "section.subsection.key" entries, e.g. "remote.origin.url"
+func (r *gitRepository) config() map[string]string {
+	result := make(map[string]string)
+
+	content, err := os.ReadFile(filepath.Join(r.CommonDir, "config"))
+	if err != nil {
+		return result
+	}
+
+	section := ""
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		// [section "subsection"]
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			name, sub, found := strings.Cut(strings.Trim(line, "[]"), " ")
+			section = strings.ToLower(name)
+			if found {
+				section += "." + strings.Trim(strings.TrimSpace(sub), `"`)
+			}
+			continue
+		}
+
+		key, value, _ := strings.Cut(line, "=")
+		result[section+"."+strings.ToLower(strings.TrimSpace(key))] = strings.Trim(strings.TrimSpace(value), `"`)
+	}
+
+	return result
+}
+
+// Info collects the git metadata up to the given level
+func (r *gitRepository) Info(level gitInfoLevel) GitInfo {
+	var info GitInfo
+	config := r.config()
+
+	branch, head, err := r.head()
+	if err != nil {
+		return info
+	}
+	info.Branch = branch
+	info.Head = head
+
+	// remote, from the branch upstream or origin
+	remote := config["branch."+branch+".remote"]
+	if remote == "" || remote == "." {
+		remote = "origin"
+	}
+	info.RemoteURL = config["remote."+remote+".url"]
+
+	if level < gitInfoCommit || head == "" {
+		return info
+	}
+
+	// last commit
+	if commit, err := r.readCommit(head); err == nil {
+		info.LastCommitTime = commit.AuthorTime
+		info.LastCommitAuthor = commit.Author
+		info.LastCommitMessage, _, _ = strings.Cut(commit.Message, "\n")
+	}
+	if level < gitInfoStatus {
+		return info
+	}
+
+	// ahead / behind upstream
+	if merge := config["branch."+branch+".merge"]; merge != "" && branch != "" {
+		upstreamRef := "refs/remotes/" + remote + "/" + strings.TrimPrefix(merge, "refs/heads/")
+		if upstream, err := r.resolveRef(upstreamRef); err == nil {
+			info.Upstream = strings.TrimPrefix(upstreamRef, "refs/remotes/")
+			var ok bool
+			info.Ahead, info.Behind, ok = r.aheadBehind(head, upstream)
+			if !ok {
+				info.Ahead, info.Behind = aheadBehindUnknown, aheadBehindUnknown
+			}
+		}
+	}
+
+	// working tree status
+	info.Dirty = r.isDirty(head)
+	info.Status = true
+
+	return info
+}
+
+// aheadBehind counts the commits that are only reachable from local or only from upstream, like git rev-list --left-right --count
+// both sides are walked together ordered by commit time until only commits reachable from both sides (the merge base and its ancestors) are left
+// ok is false if the walk exceeds maxHistoryWalk commits, e.g. for unrelated histories
+func (r *gitRepository) aheadBehind(local string, upstream string) (ahead int, behind int, ok bool) {
+	if local == upstream {
+		return 0, 0, true
+	}
+
+	flags := make(map[string]uint8)
+	queue := &commitQueue{}
+	push := func(hash string, flag uint8) {
+		if flags[hash]&flag == flag {
+			return
+		}
+		flags[hash] |= flag
+
+		commit, err := r.readCommit(hash)
+		if err != nil {
+			return
+		}
+		heap.Push(queue, commitQueueEntry{hash: hash, time: commit.CommitTime, parents: commit.Parents, seq: queue.seq})
+		queue.seq++
+	}
+	push(local, walkLocal)
+	push(upstream, walkUpstream)
+
+	for visited := 0; queue.Len() > 0 && !queue.allStale(flags); visited++ {
+		if visited >= maxHistoryWalk {
+			return 0, 0, false
+		}
+
+		entry := heap.Pop(queue).(commitQueueEntry)
+		flag := flags[entry.hash]
+		if flag&walkBoth == walkBoth {
+			flag |= walkStale
+			flags[entry.hash] = flag
+		}
+		for _, parent := range entry.parents {
+			push(parent, flag)
+		}
+	}
+
+	for _, flag := range flags {
+		switch flag & walkBoth {
+		case walkLocal:
+			ahead++
+		case walkUpstream:
+			behind++
+		}
+	}
+
+	return ahead, behind, true
+}
+
+const (
+	walkLocal    uint8 = 1 << iota // reachable from the local branch
+	walkUpstream                   // reachable from the upstream branch
+	walkStale                      // reachable from both, ancestors don't need to be counted
+
+	walkBoth = walkLocal | walkUpstream
+)
+
+type commitQueueEntry struct {
+	hash    string
+	time    time.Time
+	parents []string
+	seq     int
+}
+
+// commitQueue is a priority queue, newer commits first and commits with the same time in insertion order
+type commitQueue struct {
+	entries []commitQueueEntry
+	seq     int
+}
+
+func (q *commitQueue) Len() int { return len(q.entries) }
+func (q *commitQueue) Less(i, j int) bool {
+	if !q.entries[i].time.Equal(q.entries[j].time) {
+		return q.entries[i].time.After(q.entries[j].time)
+	}
+	return q.entries[i].seq < q.entries[j].seq
+}
+func (q *commitQueue) Swap(i, j int)      { q.entries[i], q.entries[j] = q.entries[j], q.entries[i] }
+func (q *commitQueue) Push(x interface{}) { q.entries = append(q.entries, x.(commitQueueEntry)) }
+func (q *commitQueue) Pop() interface{} {
+	entry := q.entries[len(q.entries)-1]
+	q.entries = q.entries[:len(q.entries)-1]
+	return entry
+}
+
+// allStale checks if all queued commits are reachable from both sides
+func (q *commitQueue) allStale(flags map[string]uint8) bool {
+	for _, e := range q.entries {
+		if flags[e.hash]&walkStale == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// gitWebURL derives the web url from a remote url, e.g. git@github.com:owner/repo.git -> https://github.com/owner/repo
+func gitWebURL(remoteURL string) string {
+	host, path := parseRemoteURL(remoteURL)
+	if host == "" || path == "" {
+		return ""
+	}
+
+	return "https://" + host + "/" + path
+}
+
+// parseRemoteURL returns the host and repository path of https, ssh and scp-like remote urls
+func parseRemoteURL(remoteURL string) (string, string) {
+	remoteURL = strings.TrimSpace(remoteURL)
+	if remoteURL == "" {
+		return "", ""
+	}
+
+	var host, path string
+	if strings.Contains(remoteURL, "://") {
+		u, err := url.Parse(remoteURL)
+		if err != nil || u.Scheme == "file" {
+			return "", ""
+		}
+		host = u.Hostname()
+		path = u.Path
+	} else if hostPart, pathPart, found := strings.Cut(remoteURL, ":"); found && !strings.HasPrefix(remoteURL, "/") {
+		// scp-like syntax: [user@]host:owner/repo.git
+		if _, h, hasUser := strings.Cut(hostPart, "@"); hasUser {
+			hostPart = h
+		}
+		host = hostPart
+		path = pathPart
+	} else {
+		return "", ""
+	}
+
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	return host, path
+}
+
+// gitWorktree is a linked working tree of a repository
+type gitWorktree struct {
+	Path   string
+	Branch string
+}
+
+// worktrees lists the linked worktrees of the repository, worktrees whose directory no longer exists are skipped
+func (r *gitRepository) worktrees() []gitWorktree {
+	var result []gitWorktree
+
+	entries, err := os.ReadDir(filepath.Join(r.CommonDir, "worktrees"))
+	if err != nil {
+		return result
+	}
+
+	for _, entry := range entries {
+		// gitdir points to the .git file inside the worktree
+		content, err := os.ReadFile(filepath.Join(r.CommonDir, "worktrees", entry.Name(), "gitdir"))
+		if err != nil {
+			continue
+		}
+		path := filepath.Dir(strings.TrimSpace(string(content)))
+		if _, err = os.Stat(path); err != nil {
+			continue
+		}
+
+		worktree := gitWorktree{Path: path}
+		if repo, err := openGitRepository(path); err == nil {
+			worktree.Branch, _, _ = repo.head()
+		}
+		result = append(result, worktree)
+	}
+
+	return result
+}
+
+// isLinkedWorktree returns true if the working tree is a linked worktree of another repository
+func (r *gitRepository) isLinkedWorktree() bool {
+	return r.GitDir != r.CommonDir
+}
+
+// submodulePaths returns the paths of the submodules declared in .gitmodules, relative to the working tree
+func (r *gitRepository) submodulePaths() []string {
+	var result []string
+
+	content, err := os.ReadFile(filepath.Join(r.Dir, ".gitmodules"))
+	if err != nil {
+		return result
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		key, value, found := strings.Cut(scanner.Text(), "=")
+		if found && strings.TrimSpace(key) == "path" {
+			result = append(result, filepath.FromSlash(strings.TrimSpace(value)))
+		}
+	}
+
+	return result
+}
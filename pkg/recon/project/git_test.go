@@ -0,0 +1,292 @@
+package project
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func runGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=Jane Doe", "GIT_AUTHOR_EMAIL=jane@example.com", "GIT_AUTHOR_DATE=2024-01-02T03:04:05Z",
+		"GIT_COMMITTER_NAME=Jane Doe", "GIT_COMMITTER_EMAIL=jane@example.com", "GIT_COMMITTER_DATE=2024-01-02T03:04:05Z",
+		"GIT_CONFIG_GLOBAL=/dev/null", "GIT_CONFIG_NOSYSTEM=1",
+	)
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(out))
+}
+
+// setupRepository creates a repository with a remote tracking branch, the local branch is one commit ahead and one behind
+func setupRepository(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	remote := t.TempDir()
+	runGit(t, remote, "init", "-q", "-b", "main")
+	require.NoError(t, os.WriteFile(filepath.Join(remote, "README.md"), []byte("hello\n"), 0644))
+	runGit(t, remote, "add", ".")
+	runGit(t, remote, "commit", "-q", "-m", "initial commit")
+
+	dir := filepath.Join(t.TempDir(), "repo")
+	runGit(t, filepath.Dir(dir), "clone", "-q", remote, dir)
+	runGit(t, dir, "remote", "set-url", "origin", "git@github.com:octo/repo.git")
+
+	require.NoError(t, os.WriteFile(filepath.Join(remote, "remote.txt"), []byte("remote\n"), 0644))
+	runGit(t, remote, "add", ".")
+	runGit(t, remote, "commit", "-q", "-m", "remote change")
+	runGit(t, dir, "fetch", "-q", remote, "main:refs/remotes/origin/main")
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "src"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "src", "main.go"), []byte("package main\n"), 0644))
+	runGit(t, dir, "add", ".")
+	runGit(t, dir, "commit", "-q", "-m", "local change\n\nwith body")
+
+	return dir
+}
+
+func TestGitInfo(t *testing.T) {
+	dir := setupRepository(t)
+
+	repo, err := openGitRepository(dir)
+	require.NoError(t, err)
+	info := repo.Info(gitInfoStatus)
+
+	assert.Equal(t, "main", info.Branch)
+	assert.Len(t, info.Head, 40)
+	assert.Equal(t, "git@github.com:octo/repo.git", info.RemoteURL)
+	assert.Equal(t, "origin/main", info.Upstream)
+	assert.Equal(t, 1, info.Ahead)
+	assert.Equal(t, 1, info.Behind)
+	assert.False(t, info.Dirty)
+	assert.Equal(t, "Jane Doe <jane@example.com>", info.LastCommitAuthor)
+	assert.Equal(t, "local change", info.LastCommitMessage)
+	assert.Equal(t, int64(1704164645), info.LastCommitTime.Unix())
+
+	// the cache-tree of the index matches the HEAD tree after a commit
+	index, err := repo.readIndex()
+	require.NoError(t, err)
+	commit, err := repo.readCommit(info.Head)
+	require.NoError(t, err)
+	assert.Equal(t, commit.Tree, index.TreeHash)
+
+	// previews skip the history walk and the working tree status
+	preview := repo.Info(gitInfoCommit)
+	assert.Equal(t, "local change", preview.LastCommitMessage)
+	assert.False(t, preview.Status)
+	assert.Empty(t, preview.Upstream)
+
+	// unstaged modification
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("changed\n"), 0644))
+	assert.True(t, repo.Info(gitInfoStatus).Dirty)
+
+	// staged modification, invalidates the cache-tree
+	runGit(t, dir, "add", ".")
+	index, err = repo.readIndex()
+	require.NoError(t, err)
+	assert.Empty(t, index.TreeHash)
+	assert.True(t, repo.Info(gitInfoStatus).Dirty)
+}
+
+func TestGitInfoPacked(t *testing.T) {
+	dir := setupRepository(t)
+	runGit(t, dir, "gc", "-q", "--aggressive")
+	runGit(t, dir, "update-index", "--index-version", "4")
+
+	repo, err := openGitRepository(dir)
+	require.NoError(t, err)
+	info := repo.Info(gitInfoStatus)
+
+	assert.Equal(t, "main", info.Branch)
+	assert.Equal(t, 1, info.Ahead)
+	assert.Equal(t, 1, info.Behind)
+	assert.False(t, info.Dirty)
+	assert.Equal(t, "local change", info.LastCommitMessage)
+
+	require.NoError(t, os.Remove(filepath.Join(dir, "src", "main.go")))
+	assert.True(t, repo.Info(gitInfoStatus).Dirty)
+}
+
+func TestGitWebURL(t *testing.T) {
+	tests := []struct {
+		remote string
+		want   string
+	}{
+		{"git@github.com:octo/repo.git", "https://github.com/octo/repo"},
+		{"ssh://git@gitlab.example.com:2222/group/sub/repo.git", "https://gitlab.example.com/group/sub/repo"},
+		{"https://user@gitea.example.com/owner/repo", "https://gitea.example.com/owner/repo"},
+		{"/srv/git/repo.git", ""},
+		{"file:///srv/git/repo.git", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, gitWebURL(tt.remote), tt.remote)
+	}
+}
+
+func TestGitProjectsWorktreesAndSubmodules(t *testing.T) {
+	dir := setupRepository(t)
+	source := SourceDirectory{Directory: filepath.Dir(dir), Depth: 2, Submodules: true}
+
+	// worktree outside of the repository
+	runGit(t, dir, "worktree", "add", "-q", "-b", "feature", filepath.Join(source.Directory, "repo-feature"))
+
+	// submodule
+	lib := t.TempDir()
+	runGit(t, lib, "init", "-q", "-b", "main")
+	require.NoError(t, os.WriteFile(filepath.Join(lib, "lib.go"), []byte("package lib\n"), 0644))
+	runGit(t, lib, "add", ".")
+	runGit(t, lib, "commit", "-q", "-m", "lib")
+	runGit(t, dir, "-c", "protocol.file.allow=always", "submodule", "add", "-q", lib, "vendor/lib")
+
+	projects, err := searchInDirectory(source, []string{".git"}, nil, nil)
+	require.NoError(t, err)
+	require.Len(t, projects, 3)
+
+	assert.Equal(t, KindProject, projects[0].Kind)
+	assert.Equal(t, dir, projects[0].Path)
+
+	assert.Equal(t, KindWorktree, projects[1].Kind)
+	assert.Equal(t, "feature", projects[1].Branch)
+	assert.Equal(t, "repo [feature]", renderProjectDisplayName(projects[1], BaseName))
+
+	assert.Equal(t, KindSubmodule, projects[2].Kind)
+	assert.Equal(t, filepath.Join(dir, "vendor", "lib"), projects[2].Path)
+	assert.Equal(t, "repo/vendor/lib", renderProjectDisplayName(projects[2], BaseName))
+
+	// submodules are disabled by default
+	source.Submodules = false
+	projects, err = searchInDirectory(source, []string{".git"}, nil, nil)
+	require.NoError(t, err)
+	assert.Len(t, projects, 2)
+}
+
+func TestGitAheadBehindLongHistory(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	// linear history that is longer than the old walk limit, with a merged feature branch on main
+	var stream strings.Builder
+	commit := func(ref string, mark int, parents ...int) {
+		stream.WriteString(fmt.Sprintf("commit %s\nmark :%d\ncommitter Jane Doe <jane@example.com> %d +0000\ndata 0\n", ref, mark, 1700000000+mark))
+		for i, p := range parents {
+			if i == 0 {
+				stream.WriteString(fmt.Sprintf("from :%d\n", p))
+			} else {
+				stream.WriteString(fmt.Sprintf("merge :%d\n", p))
+			}
+		}
+		stream.WriteString("\n")
+	}
+	commit("refs/heads/main", 1)
+	for i := 2; i <= 2500; i++ {
+		commit("refs/heads/main", i, i-1)
+	}
+	commit("refs/heads/feature", 2501, 1500)
+	commit("refs/heads/feature", 2502, 2501)
+	commit("refs/heads/main", 2503, 2500, 2502)
+	commit("refs/heads/local", 2504, 2503)
+
+	dir := t.TempDir()
+	runGit(t, dir, "init", "-q", "-b", "main")
+	cmd := exec.Command("git", "fast-import", "--quiet")
+	cmd.Dir = dir
+	cmd.Stdin = strings.NewReader(stream.String())
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(out))
+
+	repo, err := openGitRepository(dir)
+	require.NoError(t, err)
+	hash := func(ref string) string {
+		h, err := repo.resolveRef("refs/heads/" + ref)
+		require.NoError(t, err)
+		return h
+	}
+
+	tests := []struct {
+		local, upstream string
+		ahead, behind   int
+	}{
+		{"local", "main", 1, 0},
+		{"main", "local", 0, 1},
+		{"feature", "main", 0, 1001},
+		{"local", "feature", 1002, 0},
+	}
+	for _, tt := range tests {
+		ahead, behind, ok := repo.aheadBehind(hash(tt.local), hash(tt.upstream))
+		assert.True(t, ok)
+		assert.Equal(t, tt.ahead, ahead, "%s...%s ahead", tt.local, tt.upstream)
+		assert.Equal(t, tt.behind, behind, "%s...%s behind", tt.local, tt.upstream)
+	}
+}
+
+func TestGitProjectsIndex(t *testing.T) {
+	dir := setupRepository(t)
+	source := SourceDirectory{Directory: filepath.Dir(dir), Depth: 2}
+	indexFile := filepath.Join(t.TempDir(), "index.json")
+
+	index := LoadScanIndex(indexFile, []string{".git"})
+	projects, err := searchInDirectory(source, []string{".git"}, nil, index)
+	require.NoError(t, err)
+	require.Len(t, projects, 1)
+	require.NoError(t, index.Save())
+	info, err := os.Stat(indexFile)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	// the detected projects are stored in the index, source dependent fields are applied on every scan
+	index = LoadScanIndex(indexFile, []string{".git"})
+	require.Contains(t, index.Directories, dir)
+	assert.Len(t, index.Directories[dir].Projects, 1)
+	source.Tags = []string{"work"}
+	projects, err = searchInDirectory(source, []string{".git"}, nil, index)
+	require.NoError(t, err)
+	require.Len(t, projects, 1)
+	assert.Equal(t, []string{"work"}, projects[0].Tags)
+
+	// new worktrees change the git metadata and are detected without a change to the project directory
+	runGit(t, dir, "worktree", "add", "-q", "-b", "feature", filepath.Join(t.TempDir(), "repo-feature"))
+	projects, err = searchInDirectory(source, []string{".git"}, nil, index)
+	require.NoError(t, err)
+	require.Len(t, projects, 2)
+	assert.Equal(t, KindWorktree, projects[1].Kind)
+	assert.Equal(t, "feature", projects[1].Branch)
+	assert.Equal(t, []string{"work"}, projects[1].Parent.Tags)
+}
+
+func TestObjectCache(t *testing.T) {
+	cache := newObjectCache(10)
+	cache.add(packObjectKey{pack: "a", offset: 1}, objBlob, []byte("12"))
+	cache.add(packObjectKey{pack: "a", offset: 2}, objBlob, []byte("34"))
+	cache.add(packObjectKey{pack: "a", offset: 3}, objBlob, []byte("56"))
+
+	// objects larger than a quarter of the limit are not cached
+	cache.add(packObjectKey{pack: "a", offset: 4}, objBlob, []byte("789"))
+	_, _, ok := cache.get(packObjectKey{pack: "a", offset: 4})
+	assert.False(t, ok)
+
+	// the least recently used object is evicted
+	_, _, ok = cache.get(packObjectKey{pack: "a", offset: 1})
+	assert.True(t, ok)
+	cache.add(packObjectKey{pack: "a", offset: 5}, objBlob, []byte("ab"))
+	cache.add(packObjectKey{pack: "a", offset: 6}, objBlob, []byte("cd"))
+	cache.add(packObjectKey{pack: "a", offset: 7}, objBlob, []byte("ef"))
+	_, _, ok = cache.get(packObjectKey{pack: "a", offset: 2})
+	assert.False(t, ok)
+	objType, data, ok := cache.get(packObjectKey{pack: "a", offset: 1})
+	assert.True(t, ok)
+	assert.Equal(t, objBlob, objType)
+	assert.Equal(t, []byte("12"), data)
+	assert.Equal(t, 10, cache.size)
+}
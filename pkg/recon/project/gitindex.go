@@ -0,0 +1,249 @@
+package project
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// gitlink entries (submodules) have this mode in the index
+const gitlinkMode = 0160000
+
+// indexEntry is a single file entry of the git index
+type indexEntry struct {
+	Path      string
+	Hash      string
+	Mode      uint32
+	Size      uint32
+	MTimeSec  uint32
+	MTimeNsec uint32
+}
+
+// gitIndex is the parsed git index file
+type gitIndex struct {
+	Entries  []indexEntry
+	TreeHash string // TreeHash is the root tree of the cache-tree extension, empty if missing or invalidated by changes to the index
+}
+
+// readIndex parses the git index file (version 2, 3 and 4)
+func (r *gitRepository) readIndex() (*gitIndex, error) {
+	data, err := os.ReadFile(filepath.Join(r.GitDir, "index"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read index: %w", err)
+	}
+
+	if len(data) < 12 || string(data[:4]) != "DIRC" {
+		return nil, fmt.Errorf("invalid index file")
+	}
+	version := binary.BigEndian.Uint32(data[4:8])
+	if version < 2 || version > 4 {
+		return nil, fmt.Errorf("unsupported index version %d", version)
+	}
+	count := int(binary.BigEndian.Uint32(data[8:12]))
+
+	entries := make([]indexEntry, 0, count)
+	pos := 12
+	previousPath := ""
+	for i := 0; i < count; i++ {
+		start := pos
+		if len(data) < pos+62 {
+			return nil, fmt.Errorf("truncated index file")
+		}
+
+		entry := indexEntry{
+			MTimeSec:  binary.BigEndian.Uint32(data[pos+8:]),
+			MTimeNsec: binary.BigEndian.Uint32(data[pos+12:]),
+			Mode:      binary.BigEndian.Uint32(data[pos+24:]),
+			Size:      binary.BigEndian.Uint32(data[pos+36:]),
+			Hash:      hex.EncodeToString(data[pos+40 : pos+60]),
+		}
+		flags := binary.BigEndian.Uint16(data[pos+60:])
+		pos += 62
+		if version >= 3 && flags&0x4000 != 0 {
+			pos += 2 // extended flags
+		}
+
+		if version == 4 {
+			// path is prefix-compressed against the previous entry
+			strip, n := readIndexVarint(data[pos:])
+			if n == 0 || strip > len(previousPath) {
+				return nil, fmt.Errorf("invalid index path compression")
+			}
+			pos += n
+			end := bytes.IndexByte(data[pos:], 0)
+			if end == -1 {
+				return nil, fmt.Errorf("truncated index file")
+			}
+			entry.Path = previousPath[:len(previousPath)-strip] + string(data[pos:pos+end])
+			pos += end + 1
+		} else {
+			end := bytes.IndexByte(data[pos:], 0)
+			if end == -1 {
+				return nil, fmt.Errorf("truncated index file")
+			}
+			entry.Path = string(data[pos : pos+end])
+			pos += end + 1
+
+			// entries are padded with NUL bytes to a multiple of 8
+			pos = start + (pos-start+7)/8*8
+		}
+
+		// skip entries in conflict stages
+		if (flags>>12)&0x3 == 0 {
+			entries = append(entries, entry)
+		}
+		previousPath = entry.Path
+	}
+
+	// extensions: 4 byte signature, 4 byte size and data, followed by the 20 byte checksum of the file
+	index := &gitIndex{Entries: entries}
+	for pos+8 <= len(data)-20 {
+		signature := string(data[pos : pos+4])
+		size := int(binary.BigEndian.Uint32(data[pos+4:]))
+		pos += 8
+		if pos+size > len(data)-20 {
+			break
+		}
+		if signature == "TREE" {
+			index.TreeHash = parseCacheTreeRoot(data[pos : pos+size])
+		}
+		pos += size
+	}
+
+	return index, nil
+}
+
+// parseCacheTreeRoot returns the tree hash of the root entry of the cache-tree extension, empty if the root has been invalidated
+// entries: "<path>\0<entry count> <subtree count>\n<20 byte hash>", the hash is omitted if the entry count is -1
+func parseCacheTreeRoot(data []byte) string {
+	path, rest, found := bytes.Cut(data, []byte{0})
+	if !found || len(path) != 0 {
+		return ""
+	}
+	counts, rest, found := bytes.Cut(rest, []byte{'\n'})
+	if !found || len(rest) < 20 {
+		return ""
+	}
+	entryCount, _, _ := bytes.Cut(counts, []byte{' '})
+	if count, err := strconv.Atoi(string(entryCount)); err != nil || count < 0 {
+		return ""
+	}
+
+	return hex.EncodeToString(rest[:20])
+}
+
+// readIndexVarint reads the offset encoded integer used by index version 4
+func readIndexVarint(data []byte) (int, int) {
+	if len(data) == 0 {
+		return 0, 0
+	}
+
+	value := int(data[0] & 0x7f)
+	n := 1
+	for data[n-1]&0x80 != 0 {
+		if n >= len(data) {
+			return 0, 0
+		}
+		value = ((value + 1) << 7) | int(data[n]&0x7f)
+		n++
+	}
+	return value, n
+}
+
+// isDirty reports whether the index differs from the HEAD commit or tracked files in the working tree have been modified, untracked files are ignored
+func (r *gitRepository) isDirty(head string) bool {
+	index, err := r.readIndex()
+	if err != nil {
+		return false
+	}
+
+	// staged changes
+	if r.hasStagedChanges(head, index) {
+		return true
+	}
+
+	// unstaged changes, only compares the stat data unless it differs from the index
+	for _, entry := range index.Entries {
+		if entry.Mode == gitlinkMode {
+			continue
+		}
+		if r.isModified(entry) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hasStagedChanges compares the index with the tree of the HEAD commit
+// the cache-tree extension is used if it is valid, the HEAD tree is only read if the index has been modified since the last tree was written (e.g. git add)
+func (r *gitRepository) hasStagedChanges(head string, index *gitIndex) bool {
+	commit, err := r.readCommit(head)
+	if err != nil {
+		return len(index.Entries) > 0
+	}
+	if index.TreeHash != "" {
+		return index.TreeHash != commit.Tree
+	}
+
+	headFiles := make(map[string]string)
+	if err = r.readTreeRecursive(commit.Tree, "", headFiles); err != nil {
+		return false
+	}
+	if len(headFiles) != len(index.Entries) {
+		return true
+	}
+	for _, entry := range index.Entries {
+		if headFiles[entry.Path] != entry.Hash {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isModified compares an index entry with the working tree, only hashing the content if the stat data differs
+func (r *gitRepository) isModified(entry indexEntry) bool {
+	path := filepath.Join(r.Dir, filepath.FromSlash(entry.Path))
+	info, err := os.Lstat(path)
+	if err != nil {
+		return true
+	}
+	if uint32(info.Size()) != entry.Size {
+		return true
+	}
+	if mtime := info.ModTime(); uint32(mtime.Unix()) == entry.MTimeSec && uint32(mtime.Nanosecond()) == entry.MTimeNsec {
+		return false
+	}
+
+	var content []byte
+	if info.Mode()&os.ModeSymlink != 0 {
+		target, err := os.Readlink(path)
+		if err != nil {
+			return true
+		}
+		content = []byte(target)
+	} else {
+		content, err = os.ReadFile(path)
+		if err != nil {
+			return true
+		}
+	}
+
+	return blobHash(content) != entry.Hash
+}
+
+// blobHash calculates the git object hash of a blob
+func blobHash(content []byte) string {
+	h := sha1.New()
+	h.Write([]byte("blob " + strconv.Itoa(len(content)) + "\x00"))
+	h.Write(content)
+	return hex.EncodeToString(h.Sum(nil))
+}
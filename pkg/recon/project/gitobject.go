@@ -0,0 +1,505 @@
+package project
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"container/list"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// git object types, as stored in pack files
+const (
+	objCommit   = 1
+	objTree     = 2
+	objBlob     = 3
+	objTag      = 4
+	objOfsDelta = 6
+	objRefDelta = 7
+)
+
+// objectCacheLimit is the maximum size of the resolved pack objects kept in memory, delta chains often share the same bases
+const objectCacheLimit = 16 << 20
+
+var errObjectNotFound = errors.New("git object not found")
+
+var objectTypeNames = map[string]int{"commit": objCommit, "tree": objTree, "blob": objBlob, "tag": objTag}
+
+// packIndex is a parsed version 2 pack index (.idx) file
+type packIndex struct {
+	packFile string
+	hashes   [][]byte
+	offsets  []int64
+}
+
+// gitCommit holds the parsed fields of a commit object
+type gitCommit struct {
+	Tree       string
+	Parents    []string
+	Author     string
+	AuthorTime time.Time
+	CommitTime time.Time // CommitTime is the committer date, used to walk the history in the same order as git
+	Message    string
+}
+
+// readObject reads an object from the loose object store or the pack files
+func (r *gitRepository) readObject(hash string) (int, []byte, error) {
+	objType, data, err := r.readLooseObject(hash)
+	if err == nil {
+		return objType, data, nil
+	} else if !errors.Is(err, errObjectNotFound) {
+		return 0, nil, err
+	}
+
+	raw, err := hex.DecodeString(hash)
+	if err != nil || len(raw) != 20 {
+		return 0, nil, fmt.Errorf("invalid object hash %q", hash)
+	}
+
+	packs, err := r.packIndexes()
+	if err != nil {
+		return 0, nil, err
+	}
+	for _, pack := range packs {
+		if offset, ok := pack.find(raw); ok {
+			return r.readPackObject(pack.packFile, offset)
+		}
+	}
+
+	return 0, nil, fmt.Errorf("%w: %s", errObjectNotFound, hash)
+}
+
+func (r *gitRepository) readLooseObject(hash string) (int, []byte, error) {
+	if len(hash) != 40 {
+		return 0, nil, fmt.Errorf("invalid object hash %q", hash)
+	}
+
+	file, err := os.Open(filepath.Join(r.CommonDir, "objects", hash[:2], hash[2:]))
+	if os.IsNotExist(err) {
+		return 0, nil, errObjectNotFound
+	} else if err != nil {
+		return 0, nil, err
+	}
+	defer file.Close()
+
+	zr, err := zlib.NewReader(file)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to decompress object %s: %w", hash, err)
+	}
+	defer zr.Close()
+
+	content, err := io.ReadAll(zr)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to decompress object %s: %w", hash, err)
+	}
+
+	// header: "<type> <size>\0"
+	header, data, found := bytes.Cut(content, []byte{0})
+	if !found {
+		return 0, nil, fmt.Errorf("invalid object header for %s", hash)
+	}
+	typeName, _, _ := strings.Cut(string(header), " ")
+	objType, ok := objectTypeNames[typeName]
+	if !ok {
+		return 0, nil, fmt.Errorf("unknown object type %q for %s", typeName, hash)
+	}
+
+	return objType, data, nil
+}
+
+// packIndexes loads all pack index files, the result is cached per repository
+func (r *gitRepository) packIndexes() ([]*packIndex, error) {
+	if r.packs != nil {
+		return r.packs, nil
+	}
+
+	files, err := filepath.Glob(filepath.Join(r.CommonDir, "objects", "pack", "*.idx"))
+	if err != nil {
+		return nil, err
+	}
+
+	r.packs = []*packIndex{}
+	for _, file := range files {
+		idx, err := parsePackIndex(file)
+		if err != nil {
+			return nil, err
+		}
+		r.packs = append(r.packs, idx)
+	}
+
+	return r.packs, nil
+}
+
+func parsePackIndex(file string) (*packIndex, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pack index: %w", err)
+	}
+	if len(data) < 8+256*4 || !bytes.Equal(data[:4], []byte{0xff, 't', 'O', 'c'}) || binary.BigEndian.Uint32(data[4:8]) != 2 {
+		return nil, fmt.Errorf("unsupported pack index format: %s", file)
+	}
+
+	count := int(binary.BigEndian.Uint32(data[8+255*4 : 8+256*4]))
+	hashStart := 8 + 256*4
+	offsetStart := hashStart + count*20 + count*4
+	largeOffsetStart := offsetStart + count*4
+	if len(data) < largeOffsetStart {
+		return nil, fmt.Errorf("truncated pack index: %s", file)
+	}
+
+	idx := &packIndex{
+		packFile: strings.TrimSuffix(file, ".idx") + ".pack",
+		hashes:   make([][]byte, count),
+		offsets:  make([]int64, count),
+	}
+	for i := 0; i < count; i++ {
+		idx.hashes[i] = data[hashStart+i*20 : hashStart+(i+1)*20]
+
+		offset := binary.BigEndian.Uint32(data[offsetStart+i*4:])
+		if offset&0x80000000 != 0 {
+			pos := largeOffsetStart + int(offset&0x7fffffff)*8
+			if len(data) < pos+8 {
+				return nil, fmt.Errorf("truncated pack index: %s", file)
+			}
+			idx.offsets[i] = int64(binary.BigEndian.Uint64(data[pos:]))
+		} else {
+			idx.offsets[i] = int64(offset)
+		}
+	}
+
+	return idx, nil
+}
+
+func (p *packIndex) find(hash []byte) (int64, bool) {
+	i := sort.Search(len(p.hashes), func(i int) bool {
+		return bytes.Compare(p.hashes[i], hash) >= 0
+	})
+	if i < len(p.hashes) && bytes.Equal(p.hashes[i], hash) {
+		return p.offsets[i], true
+	}
+	return 0, false
+}
+
+// readPackObject reads the object at the given offset, resolving delta chains
+// pack files are kept open until the repository is closed
+func (r *gitRepository) readPackObject(packFile string, offset int64) (int, []byte, error) {
+	file, ok := r.packFiles[packFile]
+	if !ok {
+		var err error
+		if file, err = os.Open(packFile); err != nil {
+			return 0, nil, err
+		}
+		if r.packFiles == nil {
+			r.packFiles = make(map[string]*os.File)
+		}
+		r.packFiles[packFile] = file
+	}
+
+	return r.readPackObjectAt(file, offset, 0)
+}
+
+// Close closes the pack files opened to read objects
+func (r *gitRepository) Close() {
+	for _, file := range r.packFiles {
+		_ = file.Close()
+	}
+	r.packFiles = nil
+	r.objects = nil
+}
+
+func (r *gitRepository) readPackObjectAt(file *os.File, offset int64, depth int) (int, []byte, error) {
+	if depth > 50 {
+		return 0, nil, fmt.Errorf("delta chain too long")
+	}
+
+	key := packObjectKey{pack: file.Name(), offset: offset}
+	if objType, data, ok := r.objects.get(key); ok {
+		return objType, data, nil
+	}
+	objType, data, err := r.readPackObjectData(file, offset, depth)
+	if err != nil {
+		return 0, nil, err
+	}
+	if r.objects == nil {
+		r.objects = newObjectCache(objectCacheLimit)
+	}
+	r.objects.add(key, objType, data)
+
+	return objType, data, nil
+}
+
+func (r *gitRepository) readPackObjectData(file *os.File, offset int64, depth int) (int, []byte, error) {
+	reader := bufio.NewReader(io.NewSectionReader(file, offset, 1<<62))
+
+	// header: type and size as variable-length integer
+	b, err := reader.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+	objType := int(b>>4) & 7
+	for b&0x80 != 0 {
+		if b, err = reader.ReadByte(); err != nil {
+			return 0, nil, err
+		}
+	}
+
+	var baseType int
+	var base []byte
+	switch objType {
+	case objOfsDelta:
+		b, err = reader.ReadByte()
+		if err != nil {
+			return 0, nil, err
+		}
+		baseOffset := int64(b & 0x7f)
+		for b&0x80 != 0 {
+			if b, err = reader.ReadByte(); err != nil {
+				return 0, nil, err
+			}
+			baseOffset = ((baseOffset + 1) << 7) | int64(b&0x7f)
+		}
+		baseType, base, err = r.readPackObjectAt(file, offset-baseOffset, depth+1)
+		if err != nil {
+			return 0, nil, err
+		}
+	case objRefDelta:
+		hash := make([]byte, 20)
+		if _, err = io.ReadFull(reader, hash); err != nil {
+			return 0, nil, err
+		}
+		baseType, base, err = r.readObject(hex.EncodeToString(hash))
+		if err != nil {
+			return 0, nil, err
+		}
+	}
+
+	zr, err := zlib.NewReader(reader)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to decompress pack object: %w", err)
+	}
+	defer zr.Close()
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to decompress pack object: %w", err)
+	}
+
+	if objType == objOfsDelta || objType == objRefDelta {
+		data, err = applyDelta(base, data)
+		return baseType, data, err
+	}
+
+	return objType, data, nil
+}
+
+// applyDelta applies a git delta to the base object
+func applyDelta(base []byte, delta []byte) ([]byte, error) {
+	pos := 0
+	readSize := func() int {
+		size, shift := 0, 0
+		for pos < len(delta) {
+			b := delta[pos]
+			pos++
+			size |= int(b&0x7f) << shift
+			shift += 7
+			if b&0x80 == 0 {
+				break
+			}
+		}
+		return size
+	}
+
+	if readSize() != len(base) {
+		return nil, fmt.Errorf("delta base size mismatch")
+	}
+	result := make([]byte, 0, readSize())
+
+	for pos < len(delta) {
+		op := delta[pos]
+		pos++
+
+		if op&0x80 != 0 {
+			// copy from base
+			var offset, size int
+			for i := 0; i < 4; i++ {
+				if op&(1<<i) != 0 && pos < len(delta) {
+					offset |= int(delta[pos]) << (8 * i)
+					pos++
+				}
+			}
+			for i := 0; i < 3; i++ {
+				if op&(1<<(4+i)) != 0 && pos < len(delta) {
+					size |= int(delta[pos]) << (8 * i)
+					pos++
+				}
+			}
+			if size == 0 {
+				size = 0x10000
+			}
+			if offset+size > len(base) {
+				return nil, fmt.Errorf("invalid delta copy instruction")
+			}
+			result = append(result, base[offset:offset+size]...)
+		} else if op != 0 {
+			// insert literal data
+			if pos+int(op) > len(delta) {
+				return nil, fmt.Errorf("invalid delta insert instruction")
+			}
+			result = append(result, delta[pos:pos+int(op)]...)
+			pos += int(op)
+		} else {
+			return nil, fmt.Errorf("invalid delta opcode")
+		}
+	}
+
+	return result, nil
+}
+
+// readCommit reads and parses a commit object
+func (r *gitRepository) readCommit(hash string) (gitCommit, error) {
+	objType, data, err := r.readObject(hash)
+	if err != nil {
+		return gitCommit{}, err
+	}
+	if objType != objCommit {
+		return gitCommit{}, fmt.Errorf("object %s is not a commit", hash)
+	}
+
+	var commit gitCommit
+	headers, message, _ := strings.Cut(string(data), "\n\n")
+	commit.Message = strings.TrimSpace(message)
+	for _, line := range strings.Split(headers, "\n") {
+		key, value, _ := strings.Cut(line, " ")
+		switch key {
+		case "tree":
+			commit.Tree = value
+		case "parent":
+			commit.Parents = append(commit.Parents, value)
+		case "author":
+			commit.Author, commit.AuthorTime = parseSignature(value)
+		case "committer":
+			_, commit.CommitTime = parseSignature(value)
+		}
+	}
+
+	return commit, nil
+}
+
+// parseSignature parses "Name <email> 1700000000 +0100"
+func parseSignature(value string) (string, time.Time) {
+	end := strings.LastIndex(value, ">")
+	if end == -1 {
+		return value, time.Time{}
+	}
+
+	author := value[:end+1]
+	fields := strings.Fields(value[end+1:])
+	if len(fields) == 0 {
+		return author, time.Time{}
+	}
+	seconds, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return author, time.Time{}
+	}
+
+	return author, time.Unix(seconds, 0).UTC()
+}
+
+// readTreeRecursive returns all blob paths of a tree with their object hashes
+func (r *gitRepository) readTreeRecursive(hash string, prefix string, result map[string]string) error {
+	objType, data, err := r.readObject(hash)
+	if err != nil {
+		return err
+	}
+	if objType != objTree {
+		return fmt.Errorf("object %s is not a tree", hash)
+	}
+
+	// entries: "<mode> <name>\0<20 byte hash>"
+	for len(data) > 0 {
+		header, rest, found := bytes.Cut(data, []byte{0})
+		if !found || len(rest) < 20 {
+			return fmt.Errorf("invalid tree object %s", hash)
+		}
+		mode, name, _ := strings.Cut(string(header), " ")
+		entryHash := hex.EncodeToString(rest[:20])
+		data = rest[20:]
+
+		if mode == "40000" {
+			if err = r.readTreeRecursive(entryHash, prefix+name+"/", result); err != nil {
+				return err
+			}
+			continue
+		}
+		result[prefix+name] = entryHash
+	}
+
+	return nil
+}
+
+// packObjectKey identifies an object by its position in a pack file
+type packObjectKey struct {
+	pack   string
+	offset int64
+}
+
+type cachedObject struct {
+	key     packObjectKey
+	objType int
+	data    []byte
+}
+
+// objectCache is a LRU cache of resolved pack objects, limited by the total size of the object data
+type objectCache struct {
+	limit   int
+	size    int
+	order   *list.List
+	entries map[packObjectKey]*list.Element
+}
+
+func newObjectCache(limit int) *objectCache {
+	return &objectCache{
+		limit:   limit,
+		order:   list.New(),
+		entries: make(map[packObjectKey]*list.Element),
+	}
+}
+
+func (c *objectCache) get(key packObjectKey) (int, []byte, bool) {
+	if c == nil {
+		return 0, nil, false
+	}
+	element, ok := c.entries[key]
+	if !ok {
+		return 0, nil, false
+	}
+	c.order.MoveToFront(element)
+	object := element.Value.(*cachedObject)
+	return object.objType, object.data, true
+}
+
+// add stores the object, the least recently used objects are evicted once the limit is exceeded
+func (c *objectCache) add(key packObjectKey, objType int, data []byte) {
+	if _, ok := c.entries[key]; ok || len(data) > c.limit/4 {
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&cachedObject{key: key, objType: objType, data: data})
+	c.size += len(data)
+	for c.size > c.limit {
+		oldest := c.order.Back()
+		object := oldest.Value.(*cachedObject)
+		c.order.Remove(oldest)
+		delete(c.entries, object.key)
+		c.size -= len(object.data)
+	}
+}
@@ -0,0 +1,143 @@
+package project
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var defaultIgnoreFiles = []string{".gitignore", ".ignore"}
+
+// ignoreRule is a single pattern of a .gitignore-style file
+type ignoreRule struct {
+	base    string // base is the directory containing the ignore file
+	pattern *regexp.Regexp
+	negate  bool
+}
+
+// ignoreMatcher holds the rules of all ignore files from the source directory down to the current directory
+type ignoreMatcher struct {
+	rules []ignoreRule
+}
+
+// withFiles returns a matcher that additionally contains the rules of the ignore files in dir, rules of deeper files take precedence
+func (m ignoreMatcher) withFiles(dir string, files []string) ignoreMatcher {
+	result := ignoreMatcher{rules: slices.Clip(m.rules)}
+	for _, file := range files {
+		content, err := os.ReadFile(filepath.Join(dir, file))
+		if err != nil {
+			continue
+		}
+		result.rules = append(result.rules, parseIgnoreRules(dir, content)...)
+	}
+
+	return result
+}
+
+// ignored checks if the directory is ignored, the last matching rule wins
+func (m ignoreMatcher) ignored(path string) bool {
+	ignored := false
+	for _, rule := range m.rules {
+		rel, err := filepath.Rel(rule.base, path)
+		if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+			continue
+		}
+		if rule.pattern.MatchString(filepath.ToSlash(rel)) {
+			ignored = !rule.negate
+		}
+	}
+
+	return ignored
+}
+
+// parseIgnoreRules parses the content of a .gitignore-style file
+func parseIgnoreRules(base string, content []byte) []ignoreRule {
+	var rules []ignoreRule
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		rule := ignoreRule{base: base}
+		if strings.HasPrefix(line, "!") {
+			rule.negate = true
+			line = line[1:]
+		}
+		line = strings.TrimPrefix(line, `\`)
+
+		// only directories are scanned, so directory-only patterns are treated like regular patterns
+		line = strings.TrimSuffix(line, "/")
+		if line == "" {
+			continue
+		}
+
+		// patterns containing a slash are relative to the ignore file, others match at any depth
+		anchored := strings.Contains(line, "/")
+		line = strings.TrimPrefix(line, "/")
+
+		expr := ignorePatternToRegex(line)
+		if anchored {
+			expr = "^" + expr + "(/.*)?$"
+		} else {
+			expr = "^(.*/)?" + expr + "(/.*)?$"
+		}
+		pattern, err := regexp.Compile(expr)
+		if err != nil {
+			continue
+		}
+		rule.pattern = pattern
+		rules = append(rules, rule)
+	}
+
+	return rules
+}
+
+// ignorePatternToRegex converts the glob syntax (*, ?, [...], **) into a regular expression
+func ignorePatternToRegex(pattern string) string {
+	var builder strings.Builder
+
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch {
+		case strings.HasPrefix(pattern[i:], "**/"):
+			builder.WriteString("(.*/)?")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "/**") && i+3 == len(pattern):
+			builder.WriteString("/.*")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "**"):
+			builder.WriteString(".*")
+			i++
+		case c == '*':
+			builder.WriteString("[^/]*")
+		case c == '?':
+			builder.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end == -1 {
+				builder.WriteString(regexp.QuoteMeta(string(c)))
+				continue
+			}
+			class := pattern[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			builder.WriteString("[" + class + "]")
+			i += end + 1
+		case c == '\\' && i+1 < len(pattern):
+			builder.WriteString(regexp.QuoteMeta(string(pattern[i+1])))
+			i++
+		default:
+			builder.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	return builder.String()
+}
@@ -0,0 +1,198 @@
+package project
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/PhilippHeuer/fuzzmux/pkg/recon"
+	"github.com/rs/zerolog/log"
+)
+
+// ScanIndex remembers the scanned directories, unchanged directories (same mtime) are not read again.
+// All directories are still visited on a rescan, as a change in a nested directory does not update the mtime of its parents.
+type ScanIndex struct {
+	// Checks that have been used to detect the projects, the index is discarded if they change
+	Checks []string `json:"checks"`
+
+	// Directories maps the absolute path of a directory to the scan result
+	Directories map[string]*IndexedDirectory `json:"directories"`
+
+	file    string
+	mutex   sync.Mutex
+	visited map[string]bool
+}
+
+type IndexedDirectory struct {
+	// ModTime is the modification time of the directory, which changes if entries are added, removed or renamed
+	ModTime time.Time `json:"modTime"`
+
+	// Project is true if one of the checks matched
+	Project bool `json:"project,omitempty"`
+
+	// Children are the names of all subdirectories, before applying any exclusions
+	Children []string `json:"children,omitempty"`
+
+	// Projects are the projects detected in a project directory, including git worktrees and submodules
+	Projects []Project `json:"projects,omitempty"`
+
+	// GitModTime is the latest modification time of the git metadata at the time the projects were detected, nil if not detected yet
+	GitModTime *time.Time `json:"gitModTime,omitempty"`
+}
+
+// LoadScanIndex reads the index from file, a new index is returned if the file is missing or invalid
+func LoadScanIndex(file string, checks []string) *ScanIndex {
+	index := &ScanIndex{
+		Checks:      checks,
+		Directories: make(map[string]*IndexedDirectory),
+		file:        file,
+		visited:     make(map[string]bool),
+	}
+	if file == "" {
+		return index
+	}
+
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return index
+	}
+
+	var stored ScanIndex
+	if err = json.Unmarshal(content, &stored); err != nil {
+		log.Debug().Err(err).Str("file", file).Msg("ignoring invalid project index")
+		return index
+	}
+	if !slices.Equal(stored.Checks, checks) || stored.Directories == nil {
+		return index
+	}
+
+	index.Directories = stored.Directories
+	return index
+}
+
+// Save writes the index to disk, directories that have not been visited during the last scan are removed
+func (i *ScanIndex) Save() error {
+	if i == nil || i.file == "" {
+		return nil
+	}
+
+	i.mutex.Lock()
+	for path := range i.Directories {
+		if !i.visited[path] {
+			delete(i.Directories, path)
+		}
+	}
+	content, err := json.Marshal(i)
+	i.mutex.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to marshal project index: %w", err)
+	}
+
+	if err = recon.WriteCacheFile(i.file, content); err != nil {
+		return fmt.Errorf("failed to write project index: %w", err)
+	}
+
+	return nil
+}
+
+// directory returns the cached scan result of a directory, or reads the directory if it changed since the last scan
+func (i *ScanIndex) directory(path string, checks []string) (*IndexedDirectory, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if i != nil {
+		i.mutex.Lock()
+		i.visited[path] = true
+		cached, ok := i.Directories[path]
+		i.mutex.Unlock()
+		if ok && cached.ModTime.Equal(info.ModTime()) {
+			return cached, nil
+		}
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &IndexedDirectory{ModTime: info.ModTime()}
+	for _, check := range checks {
+		if _, err := os.Stat(filepath.Join(path, check)); err == nil {
+			result.Project = true
+			break
+		}
+	}
+	if !result.Project {
+		for _, entry := range entries {
+			if entry.IsDir() {
+				result.Children = append(result.Children, entry.Name())
+			}
+		}
+	}
+
+	if i != nil {
+		i.mutex.Lock()
+		i.Directories[path] = result
+		i.mutex.Unlock()
+	}
+
+	return result, nil
+}
+
+// projects returns the cached projects of a project directory, they are detected again if the git metadata changed since the last scan
+func (i *ScanIndex) projects(source SourceDirectory, path string, dir *IndexedDirectory) []Project {
+	if i == nil {
+		return gitProjects(source, path)
+	}
+
+	modTime := gitModTime(path)
+	i.mutex.Lock()
+	cached, valid := dir.Projects, dir.GitModTime != nil && dir.GitModTime.Equal(modTime)
+	i.mutex.Unlock()
+	if !valid {
+		cached = gitProjects(source, path)
+		i.mutex.Lock()
+		dir.Projects = cached
+		dir.GitModTime = &modTime
+		i.mutex.Unlock()
+	}
+
+	projects := make([]Project, 0, len(cached))
+	for _, project := range cached {
+		projects = append(projects, project.withSource(source))
+	}
+	return projects
+}
+
+// gitModTime returns the latest modification time of the git metadata that is used to detect worktrees and submodules
+func gitModTime(path string) time.Time {
+	var latest time.Time
+	track := func(file string) {
+		if info, err := os.Stat(file); err == nil && info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+
+	repo, err := openGitRepository(path)
+	if err != nil {
+		return latest
+	}
+	track(filepath.Join(repo.GitDir, "HEAD"))
+	track(filepath.Join(repo.Dir, ".gitmodules"))
+	track(filepath.Join(repo.CommonDir, "modules"))
+
+	worktrees := filepath.Join(repo.CommonDir, "worktrees")
+	track(worktrees)
+	entries, _ := os.ReadDir(worktrees)
+	for _, entry := range entries {
+		track(filepath.Join(worktrees, entry.Name(), "HEAD"))
+	}
+
+	return latest
+}
@@ -2,9 +2,15 @@ package project
 
 import (
 	"fmt"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"time"
+
 	"github.com/PhilippHeuer/fuzzmux/pkg/recon"
 	"github.com/PhilippHeuer/fuzzmux/pkg/util"
 	"github.com/cidverse/repoanalyzer/analyzer"
+	"github.com/rs/zerolog/log"
 )
 
 const moduleType = "project"
@@ -33,6 +39,18 @@ type ModuleConfig struct {
 
 	// DisplayFormat is the format that should be used to display the project name
 	DisplayFormat ProjectDisplayFormat `yaml:"display-format"`
+
+	// IgnoreFiles is a list of .gitignore-style files, matching directories are not scanned
+	IgnoreFiles []string `yaml:"ignore-files"`
+
+	// DisableIndex disables the on-disk index, which is used to only rescan changed directories
+	DisableIndex bool `yaml:"disable-index"`
+
+	// CloneHost is used for shorthand clone queries (owner/repo), defaults to github.com
+	CloneHost string `yaml:"clone-host"`
+
+	// CloneProtocol is used for shorthand clone queries, https (default) or ssh
+	CloneProtocol string `yaml:"clone-protocol"`
 }
 
 type SourceDirectory struct {
@@ -47,6 +65,18 @@ type SourceDirectory struct {
 
 	// Tags can be used to filter directories
 	Tags []string `yaml:"tags"`
+
+	// Submodules adds initialized git submodules as separate projects
+	Submodules bool `yaml:"submodules"`
+
+	// Clone enables cloning into this directory, also enabled if a CloneTemplate is set
+	Clone bool `yaml:"clone"`
+
+	// CloneTemplate enables cloning into this directory, the template defines the path of the clone, defaults to {{host}}/{{owner}}/{{repo}}
+	CloneTemplate string `yaml:"clone-template"`
+
+	// TemplateDirectory contains project templates (one per subdirectory), that can be used to create new projects in this directory
+	TemplateDirectory string `yaml:"template-directory"`
 }
 
 type ProjectDisplayFormat string
@@ -72,10 +102,17 @@ func (p Module) Options() ([]recon.Option, error) {
 	var result []recon.Option
 
 	// search for projects
-	projects, err := SearchProjectDirectories(p.Config.SourceDirectories, p.Config.Checks)
+	var index *ScanIndex
+	if !p.Config.DisableIndex {
+		index = LoadScanIndex(recon.StateFile(fmt.Sprintf("project-index-%s.json", p.Name())), p.Config.Checks)
+	}
+	projects, err := SearchProjectDirectories(p.Config.SourceDirectories, p.Config.Checks, p.Config.IgnoreFiles, index)
 	if err != nil {
 		return result, fmt.Errorf("failed to scan for projects: %w", err)
 	}
+	if err = index.Save(); err != nil {
+		log.Warn().Err(err).Msg("failed to save project index")
+	}
 
 	for _, project := range projects {
 		opt := recon.Option{
@@ -85,12 +122,23 @@ func (p Module) Options() ([]recon.Option, error) {
 			DisplayName:    renderProjectDisplayName(project, p.Config.DisplayFormat),
 			Name:           project.Name,
 			StartDirectory: project.Path,
-			Tags:           project.Tags,
+			Tags:           slices.Clone(project.Tags),
+			Context:        map[string]string{"projectKind": string(project.Kind)},
+		}
+		if project.Parent != nil {
+			opt.Context["parentDirectory"] = project.Parent.Path
+			opt.Tags = util.AddToSet(opt.Tags, string(project.Kind))
+		}
+		if repo, err := openGitRepository(project.Path); err == nil {
+			applyGitInfo(&opt, repo.Info(gitInfoRefs))
 		}
 		opt.ProcessUserTemplateStrings(p.Config.DisplayName, p.Config.StartDirectory)
 		result = append(result, opt)
 	}
 
+	// clone and template actions
+	result = append(result, p.actionOptions()...)
+
 	return result, nil
 }
 
@@ -99,6 +147,11 @@ func (p Module) OptionsOrCache(maxAge float64) ([]recon.Option, error) {
 }
 
 func (p Module) SelectOption(option *recon.Option) error {
+	// actions are handled after the input has been provided
+	if option.Context["action"] != "" {
+		return nil
+	}
+
 	// run repo analyzer
 	modules := analyzer.ScanDirectory(option.StartDirectory)
 	for _, m := range modules {
@@ -111,12 +164,23 @@ func (p Module) SelectOption(option *recon.Option) error {
 		option.Tags = util.AddToSet(option.Tags, "buildsystem-"+string(m.BuildSystem))
 	}
 
+	// git status and history, previews skip the history walk and working tree status
+	if repo, err := openGitRepository(option.StartDirectory); err == nil {
+		level := gitInfoStatus
+		if option.Preview {
+			level = gitInfoCommit
+		}
+		applyGitInfo(option, repo.Info(level))
+		repo.Close()
+	}
+
 	return nil
 }
 
 func (p Module) Columns() []recon.Column {
 	return append(recon.DefaultColumns(),
 		recon.Column{Key: "directory", Name: "Directory"},
+		recon.Column{Key: "gitBranch", Name: "Branch"},
 	)
 }
 
@@ -124,6 +188,9 @@ func NewModule(config ModuleConfig) Module {
 	if config.Checks == nil || len(config.Checks) == 0 {
 		config.Checks = defaultChecks
 	}
+	if config.IgnoreFiles == nil {
+		config.IgnoreFiles = defaultIgnoreFiles
+	}
 
 	return Module{
 		Config: config,
@@ -131,6 +198,21 @@ func NewModule(config ModuleConfig) Module {
 }
 
 func renderProjectDisplayName(project Project, displayFormat ProjectDisplayFormat) string {
+	// worktrees and submodules are grouped under their parent repository
+	if project.Parent != nil {
+		parent := renderProjectDisplayName(*project.Parent, displayFormat)
+		if project.Kind == KindWorktree {
+			branch := project.Branch
+			if branch == "" {
+				branch = "detached"
+			}
+			return parent + " [" + branch + "]"
+		}
+		if rel, err := filepath.Rel(project.Parent.Path, project.Path); err == nil {
+			return parent + "/" + filepath.ToSlash(rel)
+		}
+	}
+
 	output := project.Name
 	if displayFormat == AbsolutePath {
 		output = project.Path
@@ -140,3 +222,57 @@ func renderProjectDisplayName(project Project, displayFormat ProjectDisplayForma
 
 	return output
 }
+
+// applyGitInfo adds the git metadata to the option context, the web url is derived from the remote
+func applyGitInfo(option *recon.Option, info GitInfo) {
+	if option.Context == nil {
+		option.Context = map[string]string{}
+	}
+	option.Tags = util.AddToSet(option.Tags, "git")
+
+	setIfNotEmpty := func(key, value string) {
+		if value != "" {
+			option.Context[key] = value
+		}
+	}
+	setIfNotEmpty("gitBranch", info.Branch)
+	setIfNotEmpty("gitCommit", info.Head)
+	setIfNotEmpty("gitRemote", info.RemoteURL)
+	if host, path := parseRemoteURL(info.RemoteURL); host != "" {
+		option.Context["gitHost"] = host
+		option.Context["gitRepository"] = path
+	}
+	if option.Web == "" {
+		option.Web = gitWebURL(info.RemoteURL)
+	}
+
+	// only available after the option has been selected
+	if info.Head == "" || info.LastCommitTime.IsZero() {
+		return
+	}
+	option.Context["gitLastCommitAt"] = info.LastCommitTime.Format(time.RFC3339)
+	setIfNotEmpty("gitLastCommitAuthor", info.LastCommitAuthor)
+	setIfNotEmpty("gitLastCommitMessage", info.LastCommitMessage)
+
+	// not computed for previews
+	if !info.Status {
+		return
+	}
+	option.Context["gitDirty"] = strconv.FormatBool(info.Dirty)
+	if info.Upstream != "" {
+		option.Context["gitUpstream"] = info.Upstream
+		option.Context["gitAhead"] = aheadBehindString(info.Ahead)
+		option.Context["gitBehind"] = aheadBehindString(info.Behind)
+	}
+	if info.Dirty {
+		option.Tags = util.AddToSet(option.Tags, "git-dirty")
+	}
+}
+
+// aheadBehindString formats the ahead / behind count, unknown if the history was too large to compare
+func aheadBehindString(count int) string {
+	if count == aheadBehindUnknown {
+		return "unknown"
+	}
+	return strconv.Itoa(count)
+}
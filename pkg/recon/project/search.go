@@ -13,18 +13,30 @@ import (
 )
 
 type Project struct {
-	Name         string   // Name is the name of the project
-	Path         string   // Path is the absolute path to the project
-	RelativePath string   // RelativePath is the path relative to the source directory
-	Tags         []string // Tags are the tags of the project
+	Name         string      `json:"name"`             // Name is the name of the project
+	Path         string      `json:"path"`             // Path is the absolute path to the project
+	RelativePath string      `json:"relativePath"`     // RelativePath is the path relative to the source directory
+	Tags         []string    `json:"tags,omitempty"`   // Tags are the tags of the project
+	Kind         ProjectKind `json:"kind"`             // Kind is the type of the project, e.g. a git worktree or submodule
+	Branch       string      `json:"branch,omitempty"` // Branch is the checked out branch of a git worktree
+	Parent       *Project    `json:"parent,omitempty"` // Parent is the repository the worktree or submodule belongs to
 }
 
+type ProjectKind string
+
+const (
+	KindProject   ProjectKind = "project"
+	KindWorktree  ProjectKind = "worktree"
+	KindSubmodule ProjectKind = "submodule"
+)
+
 type ScanResult struct {
 	Projects []Project
 	Error    error
 }
 
-func SearchProjectDirectories(sources []SourceDirectory, checks []string) ([]Project, error) {
+// SearchProjectDirectories scans the source directories for projects, the index is optional and will be updated with the scan results
+func SearchProjectDirectories(sources []SourceDirectory, checks []string, ignoreFiles []string, index *ScanIndex) ([]Project, error) {
 	log.Debug().Interface("directories", sources).Msg("searching for project directories")
 	var (
 		wg      sync.WaitGroup
@@ -38,7 +50,7 @@ func SearchProjectDirectories(sources []SourceDirectory, checks []string) ([]Pro
 		go func(source SourceDirectory) {
 			defer wg.Done()
 
-			projects, err := searchInDirectory(source, checks)
+			projects, err := searchInDirectory(source, checks, ignoreFiles, index)
 			results <- ScanResult{Projects: projects, Error: err}
 		}(source)
 	}
@@ -49,19 +61,24 @@ func SearchProjectDirectories(sources []SourceDirectory, checks []string) ([]Pro
 	}()
 
 	var projects []Project
+	seen := make(map[string]bool)
 	for result := range results {
 		if result.Error != nil {
 			return nil, result.Error
 		}
-		projects = append(projects, result.Projects...)
+		for _, project := range result.Projects {
+			if seen[project.Path] {
+				continue
+			}
+			seen[project.Path] = true
+			projects = append(projects, project)
+		}
 	}
 
 	return projects, nil
 }
 
-func searchInDirectory(source SourceDirectory, checks []string) ([]Project, error) {
-	var projects []Project
-
+func searchInDirectory(source SourceDirectory, checks []string, ignoreFiles []string, index *ScanIndex) ([]Project, error) {
 	// Compile regex patterns for exclusion
 	var excludePatterns []*regexp.Regexp
 	for _, pattern := range source.Exclude {
@@ -72,49 +89,162 @@ func searchInDirectory(source SourceDirectory, checks []string) ([]Project, erro
 		excludePatterns = append(excludePatterns, excludePattern)
 	}
 
-	err := filepath.WalkDir(source.Directory, func(path string, info os.DirEntry, err error) error {
-		if err != nil {
-			return err
-		}
+	s := directoryScanner{
+		source:          source,
+		checks:          checks,
+		ignoreFiles:     ignoreFiles,
+		excludePatterns: excludePatterns,
+		index:           index,
+	}
+	err := s.scan(source.Directory, ignoreMatcher{})
+
+	return s.projects, err
+}
+
+// directoryScanner walks a source directory, using the index to skip reading unchanged directories.
+// Every directory up to the configured depth is still visited and stat'ed, because the modification time of a directory does not change if a nested directory changes.
+type directoryScanner struct {
+	source          SourceDirectory
+	checks          []string
+	ignoreFiles     []string
+	excludePatterns []*regexp.Regexp
+	index           *ScanIndex
+	projects        []Project
+}
 
-		// skip files
-		if !info.IsDir() {
+func (s *directoryScanner) scan(path string, ignore ignoreMatcher) error {
+	// exclusion patterns
+	for _, pattern := range s.excludePatterns {
+		log.Trace().Str("path", path).Str("pattern", pattern.String()).Bool("match", pattern.MatchString(filepath.Base(path))).Msg("checking for matches with exclude pattern")
+		if pattern.MatchString(filepath.Base(path)) {
 			return nil
 		}
+	}
 
-		// exclusion patterns
-		for _, pattern := range excludePatterns {
-			log.Trace().Str("path", path).Str("pattern", pattern.String()).Bool("match", pattern.MatchString(info.Name())).Msg("checking for matches with exclude pattern")
-			if pattern.MatchString(info.Name()) {
-				return filepath.SkipDir
-			}
+	// ignore files
+	if ignore.ignored(path) {
+		log.Trace().Str("path", path).Msg("skipping ignored directory")
+		return nil
+	}
+
+	// check depth
+	rel, err := filepath.Rel(s.source.Directory, path)
+	if err != nil {
+		return err
+	}
+	depth := strings.Count(filepath.ToSlash(rel), "/") + 1
+	if depth > s.source.Depth {
+		return nil
+	}
+
+	// check
+	dir, err := s.index.directory(path, s.checks)
+	if err != nil {
+		// directories that have been removed since the last scan
+		if os.IsNotExist(err) && path != s.source.Directory {
+			return nil
 		}
+		return err
+	}
+	if dir.Project {
+		s.projects = append(s.projects, s.index.projects(s.source, path, dir)...)
+		return nil
+	}
 
-		// check depth
-		rel, err := filepath.Rel(source.Directory, path)
-		if err != nil {
+	ignore = ignore.withFiles(path, s.ignoreFiles)
+	for _, child := range dir.Children {
+		if err = s.scan(filepath.Join(path, child), ignore); err != nil {
 			return err
 		}
-		depth := strings.Count(filepath.ToSlash(rel), "/") + 1
-		if depth > source.Depth {
-			return filepath.SkipDir
+	}
+
+	return nil
+}
+
+// withSource returns a copy of the project with the source dependent fields (relative path, tags) set for the given source
+func (p Project) withSource(source SourceDirectory) Project {
+	project := newProject(source, p.Path)
+	project.Kind = p.Kind
+	project.Branch = p.Branch
+	if p.Parent != nil {
+		parent := p.Parent.withSource(source)
+		project.Parent = &parent
+	}
+	return project
+}
+
+func newProject(source SourceDirectory, path string) Project {
+	relativePath := path
+	if rel, err := filepath.Rel(source.Directory, path); err == nil && !strings.HasPrefix(rel, "..") {
+		relativePath = filepath.Base(source.Directory) + "/" + filepath.ToSlash(rel)
+	}
+
+	return Project{
+		Name:         filepath.Base(path),
+		Path:         path,
+		RelativePath: relativePath,
+		Tags:         source.Tags,
+		Kind:         KindProject,
+	}
+}
+
+// gitProjects returns the project at path, followed by its git worktrees and submodules (if enabled)
+func gitProjects(source SourceDirectory, path string) []Project {
+	project := newProject(source, path)
+
+	repo, err := openGitRepository(path)
+	if err != nil {
+		return []Project{project}
+	}
+
+	// linked worktrees are listed together with their main repository
+	if repo.isLinkedWorktree() {
+		mainPath := filepath.Dir(repo.CommonDir)
+		if rel, err := filepath.Rel(source.Directory, mainPath); err == nil && !strings.HasPrefix(rel, "..") {
+			return nil
 		}
 
-		// check
-		for _, check := range checks {
-			if _, err := os.Stat(filepath.Join(path, check)); err == nil {
-				projects = append(projects, Project{
-					Name:         filepath.Base(path),
-					Path:         path,
-					RelativePath: filepath.Base(source.Directory) + "/" + filepath.ToSlash(rel),
-					Tags:         source.Tags,
-				})
-				return filepath.SkipDir
-			}
+		parent := newProject(source, mainPath)
+		project.Kind = KindWorktree
+		project.Branch, _, _ = repo.head()
+		project.Parent = &parent
+		return []Project{project}
+	}
+
+	projects := []Project{project}
+	for _, worktree := range repo.worktrees() {
+		wt := newProject(source, worktree.Path)
+		wt.Kind = KindWorktree
+		wt.Branch = worktree.Branch
+		wt.Parent = &project
+		projects = append(projects, wt)
+	}
+
+	if source.Submodules {
+		projects = append(projects, submoduleProjects(source, repo, &project)...)
+	}
+
+	return projects
+}
+
+// submoduleProjects returns the checked out submodules of the repository, nested submodules are included
+func submoduleProjects(source SourceDirectory, repo *gitRepository, parent *Project) []Project {
+	var projects []Project
+
+	for _, subPath := range repo.submodulePaths() {
+		path := filepath.Join(repo.Dir, subPath)
+		subRepo, err := openGitRepository(path)
+		if err != nil {
+			// not initialized
+			continue
 		}
 
-		return nil
-	})
+		submodule := newProject(source, path)
+		submodule.Kind = KindSubmodule
+		submodule.Parent = parent
+		projects = append(projects, submodule)
+		projects = append(projects, submoduleProjects(source, subRepo, &submodule)...)
+	}
 
-	return projects, err
+	return projects
 }
@@ -0,0 +1,89 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func createProject(t *testing.T, path string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Join(path, ".git"), 0755))
+}
+
+func projectNames(projects []Project) []string {
+	var names []string
+	for _, p := range projects {
+		names = append(names, p.Name)
+	}
+	return names
+}
+
+func TestSearchWithIgnoreFiles(t *testing.T) {
+	root := t.TempDir()
+	createProject(t, filepath.Join(root, "apps", "api"))
+	createProject(t, filepath.Join(root, "apps", "web", "node_modules", "left-pad"))
+	createProject(t, filepath.Join(root, "libs", "target", "generated"))
+	createProject(t, filepath.Join(root, "libs", "core"))
+	createProject(t, filepath.Join(root, "build", "keep"))
+	createProject(t, filepath.Join(root, "build", "tmp"))
+	require.NoError(t, os.WriteFile(filepath.Join(root, ".ignore"), []byte("# dependencies\nnode_modules/\n/libs/target\nbuild/*\n!build/keep\n"), 0644))
+
+	source := SourceDirectory{Directory: root, Depth: 4}
+	projects, err := searchInDirectory(source, []string{".git"}, defaultIgnoreFiles, nil)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"api", "core", "keep"}, projectNames(projects))
+}
+
+func TestSearchWithIndex(t *testing.T) {
+	root := t.TempDir()
+	createProject(t, filepath.Join(root, "a", "one"))
+	createProject(t, filepath.Join(root, "b", "two"))
+	indexFile := filepath.Join(t.TempDir(), "index.json")
+	source := SourceDirectory{Directory: root, Depth: 3}
+
+	index := LoadScanIndex(indexFile, []string{".git"})
+	projects, err := searchInDirectory(source, []string{".git"}, nil, index)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"one", "two"}, projectNames(projects))
+	require.NoError(t, index.Save())
+
+	// unchanged directories are served from the index, the mtime is reset to simulate an unchanged directory
+	index = LoadScanIndex(indexFile, []string{".git"})
+	dirA := filepath.Join(root, "a")
+	require.Contains(t, index.Directories, dirA)
+	modTime := index.Directories[dirA].ModTime
+	createProject(t, filepath.Join(dirA, "hidden"))
+	require.NoError(t, os.Chtimes(dirA, modTime, modTime))
+
+	// changed directories are read again
+	createProject(t, filepath.Join(root, "b", "three"))
+	require.NoError(t, os.RemoveAll(filepath.Join(root, "b", "two")))
+
+	projects, err = searchInDirectory(source, []string{".git"}, nil, index)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"one", "three"}, projectNames(projects))
+	require.NoError(t, index.Save())
+	assert.NotContains(t, index.Directories, filepath.Join(root, "b", "two"))
+
+	// changed checks discard the index
+	index = LoadScanIndex(indexFile, []string{".hg"})
+	assert.Empty(t, index.Directories)
+}
+
+func TestIgnorePatterns(t *testing.T) {
+	rules := parseIgnoreRules("/src", []byte("*.tmp\n/dist\ndocs/**/generated\n[Bb]in/\n"))
+	m := ignoreMatcher{rules: rules}
+
+	assert.True(t, m.ignored("/src/a/b/cache.tmp"))
+	assert.True(t, m.ignored("/src/dist"))
+	assert.False(t, m.ignored("/src/a/dist"))
+	assert.True(t, m.ignored("/src/docs/generated"))
+	assert.True(t, m.ignored("/src/docs/api/v1/generated"))
+	assert.True(t, m.ignored("/src/x/Bin"))
+	assert.False(t, m.ignored("/src/x/sbin"))
+	assert.False(t, m.ignored("/other/dist"))
+}
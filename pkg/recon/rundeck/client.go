@@ -3,8 +3,12 @@ package rundeck
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
 )
 
 type Job struct {
@@ -20,13 +24,31 @@ type Job struct {
 	Enabled         bool   `json:"enabled"`
 }
 
+type Project struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	URL         string `json:"url"`
+}
+
+// Node is a node of the project resource model, all attributes are returned as key-value pairs
+type Node map[string]interface{}
+
+const (
+	// minAPIVersion is the oldest api version supported by the client (Rundeck 2.6)
+	minAPIVersion = 14
+
+	// maxAPIVersion is the newest api version known to the client
+	maxAPIVersion = 47
+)
+
 // Client is a simple HTTP client to interact with the rundeck API
 type Client struct {
 	BaseURL    string
 	HTTPClient *http.Client
+	APIVersion int
 }
 
-// NewClient initializes a new API client
+// NewClient initializes a new API client, the api version defaults to the oldest supported version (see NegotiateAPIVersion)
 func NewClient(baseURL string, accessToken string) *Client {
 	return &Client{
 		BaseURL: baseURL,
@@ -36,29 +58,204 @@ func NewClient(baseURL string, accessToken string) *Client {
 				AuthToken: accessToken,
 			},
 		},
+		APIVersion: minAPIVersion,
 	}
 }
 
-// GetJobs fetches jobs for a given project
-func (c *Client) GetJobs(project string, queryParams map[string]string) ([]Job, error) {
-	endpoint := fmt.Sprintf("%s/api/14/project/%s/jobs", c.BaseURL, project)
-	reqURL, err := url.Parse(endpoint)
+// NegotiateAPIVersion queries the api version of the server and uses the highest version supported by both
+func (c *Client) NegotiateAPIVersion() (int, error) {
+	body, err := c.request("GET", c.endpoint("/system/info"), nil)
 	if err != nil {
-		return nil, fmt.Errorf("invalid URL: %v", err)
+		return c.APIVersion, err
+	}
+
+	var info struct {
+		System struct {
+			Rundeck struct {
+				APIVersion int `json:"apiversion"`
+			} `json:"rundeck"`
+		} `json:"system"`
+	}
+	if err = json.Unmarshal(body, &info); err != nil {
+		return c.APIVersion, fmt.Errorf("failed to decode response: %v", err)
+	}
+
+	version := info.System.Rundeck.APIVersion
+	if version > maxAPIVersion {
+		version = maxAPIVersion
+	}
+	if version >= minAPIVersion {
+		c.APIVersion = version
+	}
+
+	return c.APIVersion, nil
+}
+
+// GetProjects fetches all projects the user has access to
+func (c *Client) GetProjects() ([]Project, error) {
+	body, err := c.request("GET", c.endpoint("/projects"), nil)
+	if err != nil {
+		return nil, err
 	}
 
-	query := reqURL.Query()
+	var projects []Project
+	if err = json.Unmarshal(body, &projects); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %v", err)
+	}
+	return projects, nil
+}
+
+// GetJobs fetches jobs for a given project
+func (c *Client) GetJobs(project string, queryParams map[string]string) ([]Job, error) {
+	query := url.Values{}
 	for key, value := range queryParams {
 		if value != "" {
 			query.Add(key, value)
 		}
 	}
+
+	body, err := c.request("GET", c.endpoint("/project/%s/jobs", url.PathEscape(project)), query)
+	if err != nil {
+		return nil, err
+	}
+
+	var jobs []Job
+	if err = json.Unmarshal(body, &jobs); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %v", err)
+	}
+	return jobs, nil
+}
+
+// GetNodes fetches the node inventory (resource model) of a project
+func (c *Client) GetNodes(project string) (map[string]Node, error) {
+	body, err := c.request("GET", c.endpoint("/project/%s/resources", url.PathEscape(project)), url.Values{"format": {"json"}})
+	if err != nil {
+		return nil, err
+	}
+
+	var nodes map[string]Node
+	if err = json.Unmarshal(body, &nodes); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %v", err)
+	}
+	return nodes, nil
+}
+
+// JobOption is an input option of a job definition
+type JobOption struct {
+	Name        string   `yaml:"name"`
+	Description string   `yaml:"description"`
+	Required    bool     `yaml:"required"`
+	Value       string   `yaml:"value"`
+	Values      []string `yaml:"values"`
+	Enforced    bool     `yaml:"enforced"`
+	Secure      bool     `yaml:"secure"`
+}
+
+type Execution struct {
+	ID          int    `json:"id"`
+	Href        string `json:"href"`
+	Permalink   string `json:"permalink"`
+	Status      string `json:"status"`
+	User        string `json:"user"`
+	DateStarted struct {
+		Date string `json:"date"`
+	} `json:"date-started"`
+}
+
+type ExecutionOutput struct {
+	ID            string `json:"id"`
+	Offset        string `json:"offset"`
+	Completed     bool   `json:"completed"`
+	ExecCompleted bool   `json:"execCompleted"`
+	ExecState     string `json:"execState"`
+	Entries       []struct {
+		Log   string `json:"log"`
+		Time  string `json:"time"`
+		Level string `json:"level"`
+	} `json:"entries"`
+}
+
+// GetJobOptions fetches the option definitions of a job, the yaml export is used because the json export requires a recent api version
+func (c *Client) GetJobOptions(jobID string) ([]JobOption, error) {
+	body, err := c.request("GET", c.endpoint("/job/%s", url.PathEscape(jobID)), url.Values{"format": {"yaml"}})
+	if err != nil {
+		return nil, err
+	}
+
+	var definitions []struct {
+		Options []JobOption `yaml:"options"`
+	}
+	if err = yaml.Unmarshal(body, &definitions); err != nil {
+		return nil, fmt.Errorf("failed to decode job definition: %v", err)
+	}
+	if len(definitions) == 0 {
+		return nil, fmt.Errorf("job %s not found", jobID)
+	}
+
+	return definitions[0].Options, nil
+}
+
+// RunJob triggers a job execution with the given option values
+func (c *Client) RunJob(jobID string, values map[string]string) (Execution, error) {
+	var execution Execution
+	body, err := c.request("POST", c.endpoint("/job/%s/run", url.PathEscape(jobID)), url.Values{"argString": {ArgString(values)}})
+	if err != nil {
+		return execution, err
+	}
+
+	if err = json.Unmarshal(body, &execution); err != nil {
+		return execution, fmt.Errorf("failed to decode response: %v", err)
+	}
+	return execution, nil
+}
+
+// GetExecutions fetches the most recent executions of a job
+func (c *Client) GetExecutions(jobID string, max int) ([]Execution, error) {
+	body, err := c.request("GET", c.endpoint("/job/%s/executions", url.PathEscape(jobID)), url.Values{"max": {strconv.Itoa(max)}})
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Executions []Execution `json:"executions"`
+	}
+	if err = json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %v", err)
+	}
+	return response.Executions, nil
+}
+
+// GetExecutionOutput fetches the log entries of an execution after the given offset
+func (c *Client) GetExecutionOutput(executionID int, offset string) (ExecutionOutput, error) {
+	var output ExecutionOutput
+	body, err := c.request("GET", c.endpoint("/execution/%d/output", executionID), url.Values{"offset": {offset}})
+	if err != nil {
+		return output, err
+	}
+
+	if err = json.Unmarshal(body, &output); err != nil {
+		return output, fmt.Errorf("failed to decode response: %v", err)
+	}
+	return output, nil
+}
+
+// endpoint returns the url of the api path using the negotiated api version
+func (c *Client) endpoint(path string, args ...interface{}) string {
+	return fmt.Sprintf("%s/api/%d%s", c.BaseURL, c.APIVersion, fmt.Sprintf(path, args...))
+}
+
+func (c *Client) request(method string, endpoint string, query url.Values) ([]byte, error) {
+	reqURL, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL: %v", err)
+	}
 	reqURL.RawQuery = query.Encode()
 
-	req, err := http.NewRequest("GET", reqURL.String(), nil)
+	req, err := http.NewRequest(method, reqURL.String(), nil)
 	if err != nil {
 		return nil, fmt.Errorf("could not create request: %v", err)
 	}
+	req.Header.Set("Accept", "application/json")
 
 	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
@@ -66,14 +263,13 @@ func (c *Client) GetJobs(project string, queryParams map[string]string) ([]Job,
 	}
 	defer resp.Body.Close()
 
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %v", err)
+	}
 	if resp.StatusCode != http.StatusOK {
 		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
 	}
 
-	var jobs []Job
-	if err = json.NewDecoder(resp.Body).Decode(&jobs); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %v", err)
-	}
-
-	return jobs, nil
+	return body, nil
 }
@@ -0,0 +1,77 @@
+package rundeck
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+)
+
+// recentExecutions is the number of executions added to the context of a job
+const recentExecutions = 5
+
+// ArgString renders the option values as rundeck argString, e.g. -env "prod" -version "1.0"
+func ArgString(values map[string]string) string {
+	var keys []string
+	for k := range values {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	var args []string
+	for _, k := range keys {
+		args = append(args, fmt.Sprintf(`-%s "%s"`, k, strings.ReplaceAll(values[k], `"`, `\"`)))
+	}
+	return strings.Join(args, " ")
+}
+
+// FollowExecution writes the log of the execution until it's completed, returns the final state of the execution
+func FollowExecution(client *Client, executionID int, w io.Writer, interval time.Duration) (string, error) {
+	offset := "0"
+	for {
+		output, err := client.GetExecutionOutput(executionID, offset)
+		if err != nil {
+			return "", err
+		}
+
+		for _, entry := range output.Entries {
+			_, _ = fmt.Fprintf(w, "%s %s %s\n", entry.Time, entry.Level, entry.Log)
+		}
+		if output.Offset != "" {
+			offset = output.Offset
+		}
+		if output.Completed && output.ExecCompleted {
+			return output.ExecState, nil
+		}
+
+		time.Sleep(interval)
+	}
+}
+
+// executionContext adds the most recent executions of a job to the context
+func executionContext(executions []Execution, context map[string]string) {
+	if len(executions) == 0 {
+		return
+	}
+
+	last := executions[0]
+	context["lastExecution.id"] = fmt.Sprintf("%d", last.ID)
+	context["lastExecution.status"] = last.Status
+	context["lastExecution.startedAt"] = last.DateStarted.Date
+	context["lastExecution.user"] = last.User
+	context["lastExecution.web"] = last.Permalink
+
+	var recent []string
+	for _, e := range executions {
+		recent = append(recent, fmt.Sprintf("#%d %s (%s, %s)", e.ID, e.Status, e.DateStarted.Date, e.User))
+	}
+	context["recentExecutions"] = strings.Join(recent, "; ")
+
+	// remove empty values, placeholders without a value are not replaced
+	for k, v := range context {
+		if v == "" {
+			delete(context, k)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package rundeck
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/PhilippHeuer/fuzzmux/pkg/recon"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testJobDefinition = `- id: 7fdae203-1822-4cdc-bf90-6e8e5a0e074a
+  name: Deploy
+  options:
+  - name: env
+    description: target environment
+    required: true
+    enforced: true
+    values:
+    - dev
+    - prod
+  - name: version
+    value: latest
+  - name: password
+    secure: true
+`
+
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	outputCalls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Rundeck-Auth-Token") != "token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		switch r.Method + " " + r.URL.Path {
+		case "GET /api/14/job/7fdae203-1822-4cdc-bf90-6e8e5a0e074a":
+			assert.Equal(t, "yaml", r.URL.Query().Get("format"))
+			_, _ = w.Write([]byte(testJobDefinition))
+		case "POST /api/14/job/7fdae203-1822-4cdc-bf90-6e8e5a0e074a/run":
+			assert.Equal(t, `-env "prod" -version "1.0 \"beta\""`, r.URL.Query().Get("argString"))
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"id": 42, "status": "running", "permalink": "http://rundeck/execution/show/42"})
+		case "GET /api/14/job/7fdae203-1822-4cdc-bf90-6e8e5a0e074a/executions":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"executions": []map[string]interface{}{
+				{"id": 42, "status": "succeeded", "user": "admin", "permalink": "http://rundeck/execution/show/42", "date-started": map[string]string{"date": "2026-10-01T10:00:00Z"}},
+				{"id": 41, "status": "failed", "user": "admin", "date-started": map[string]string{"date": "2026-09-30T10:00:00Z"}},
+			}})
+		case "GET /api/14/execution/42/output":
+			outputCalls++
+			if outputCalls == 1 {
+				assert.Equal(t, "0", r.URL.Query().Get("offset"))
+				_ = json.NewEncoder(w).Encode(map[string]interface{}{"offset": "120", "completed": false, "entries": []map[string]string{{"time": "10:00:01", "level": "NORMAL", "log": "deploying"}}})
+			} else {
+				assert.Equal(t, "120", r.URL.Query().Get("offset"))
+				_ = json.NewEncoder(w).Encode(map[string]interface{}{"offset": "200", "completed": true, "execCompleted": true, "execState": "succeeded", "entries": []map[string]string{{"time": "10:00:02", "level": "NORMAL", "log": "done"}}})
+			}
+		default:
+			t.Logf("unexpected request: %s %s", r.Method, r.URL.String())
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestRunJob(t *testing.T) {
+	server := newTestServer(t)
+	client := NewClient(server.URL, "token")
+
+	options, err := client.GetJobOptions("7fdae203-1822-4cdc-bf90-6e8e5a0e074a")
+	require.NoError(t, err)
+	require.Len(t, options, 3)
+	assert.Equal(t, JobOption{Name: "env", Description: "target environment", Required: true, Enforced: true, Values: []string{"dev", "prod"}}, options[0])
+	assert.Equal(t, "latest", options[1].Value)
+	assert.True(t, options[2].Secure)
+
+	execution, err := client.RunJob("7fdae203-1822-4cdc-bf90-6e8e5a0e074a", map[string]string{"version": `1.0 "beta"`, "env": "prod"})
+	require.NoError(t, err)
+	assert.Equal(t, 42, execution.ID)
+
+	var out bytes.Buffer
+	state, err := FollowExecution(client, execution.ID, &out, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "succeeded", state)
+	assert.Equal(t, "10:00:01 NORMAL deploying\n10:00:02 NORMAL done\n", out.String())
+}
+
+func TestSelectOptionExecutions(t *testing.T) {
+	server := newTestServer(t)
+	module := NewModule(ModuleConfig{Host: server.URL, AccessToken: "token"})
+
+	option := recon.Option{Id: "7fdae203-1822-4cdc-bf90-6e8e5a0e074a", StartDirectory: t.TempDir()}
+	require.NoError(t, module.SelectOption(&option))
+	assert.Equal(t, "42", option.Context["lastExecution.id"])
+	assert.Equal(t, "succeeded", option.Context["lastExecution.status"])
+	assert.Equal(t, "#42 succeeded (2026-10-01T10:00:00Z, admin); #41 failed (2026-09-30T10:00:00Z, admin)", option.Context["recentExecutions"])
+}
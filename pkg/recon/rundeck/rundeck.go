@@ -2,6 +2,9 @@ package rundeck
 
 import (
 	"fmt"
+	"net/url"
+	"slices"
+	"strings"
 
 	"github.com/PhilippHeuer/fuzzmux/pkg/recon"
 	"github.com/PhilippHeuer/fuzzmux/pkg/types"
@@ -11,6 +14,14 @@ import (
 
 const moduleType = "rundeck"
 
+// secretToken is the environment variable of the api token, it's read by the rundeck cli and tmx util rundeck-run
+const secretToken = "RD_TOKEN"
+
+const (
+	queryJob  = "job"
+	queryNode = "node"
+)
+
 type Module struct {
 	Config ModuleConfig
 }
@@ -25,7 +36,7 @@ type ModuleConfig struct {
 	// StartDirectory is a template string that defines the start directory
 	StartDirectory string `yaml:"start-directory"`
 
-	// Host is the Backstage hostname or IP address
+	// Host is the Rundeck url
 	Host string `yaml:"host"`
 
 	// AccessToken is the token used to authenticate against the Rundeck API
@@ -34,13 +45,15 @@ type ModuleConfig struct {
 	// AttributeMapping is a list of field mappings used to map additional attributes to context fields
 	AttributeMapping []types.FieldMapping `yaml:"attribute-mapping"`
 
-	//Projects is a list of projects to query
-	Projects []string
+	// Projects is a list of projects to query, all projects are queried if empty
+	Projects []string `yaml:"projects"`
+
+	// Query is a list of content types that should be queried (job, node), defaults to job
+	Query []string `yaml:"query"`
 }
 
-func (c *ModuleConfig) DecodeConfig() {
-	c.Host = util.ResolveCredentialValue(c.Host)
-	c.AccessToken = util.ResolveCredentialValue(c.AccessToken)
+func (c *ModuleConfig) DecodeConfig() error {
+	return util.ResolveCredentialValues(&c.Host, &c.AccessToken)
 }
 
 func (p Module) Name() string {
@@ -55,73 +68,224 @@ func (p Module) Type() string {
 }
 
 func (p Module) Options() ([]recon.Option, error) {
-	p.Config.DecodeConfig()
+	if err := p.Config.DecodeConfig(); err != nil {
+		return nil, fmt.Errorf("failed to resolve credentials: %w", err)
+	}
 	var result []recon.Option
 
 	// setup client
 	log.Debug().Str("host", p.Config.Host).Msg("connecting to rundeck")
 	client := NewClient(p.Config.Host, p.Config.AccessToken)
+	apiVersion, err := client.NegotiateAPIVersion()
+	if err != nil {
+		log.Warn().Err(err).Str("host", p.Config.Host).Int("api-version", apiVersion).Msg("failed to query rundeck api version, using the oldest supported version")
+	}
 
-	// query
-	for _, project := range p.Config.Projects {
-		log.Debug().Str("host", p.Config.Host).Str("project", project).Msg("querying rundeck jobs")
-		jobs, err := client.GetJobs(project, nil)
+	// projects
+	projects := p.Config.Projects
+	if len(projects) == 0 {
+		all, err := client.GetProjects()
 		if err != nil {
-			return nil, err
+			return nil, fmt.Errorf("failed to query rundeck projects: %w", err)
 		}
+		for _, project := range all {
+			projects = append(projects, project.Name)
+		}
+	}
 
-		for _, job := range jobs {
-			jobPath := job.Name
-			if job.Group != "" {
-				jobPath = job.Group + "/" + job.Name
+	// query
+	for _, project := range projects {
+		if slices.Contains(p.Config.Query, queryJob) {
+			options, err := p.jobOptions(client, project)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, options...)
+		}
+		if slices.Contains(p.Config.Query, queryNode) {
+			options, err := p.nodeOptions(client, project)
+			if err != nil {
+				return nil, err
 			}
+			result = append(result, options...)
+		}
+	}
+
+	return result, nil
+}
+
+func (p Module) jobOptions(client *Client, project string) ([]recon.Option, error) {
+	var result []recon.Option
+
+	log.Debug().Str("host", p.Config.Host).Str("project", project).Msg("querying rundeck jobs")
+	jobs, err := client.GetJobs(project, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, job := range jobs {
+		jobPath := job.Name
+		if job.Group != "" {
+			jobPath = job.Group + "/" + job.Name
+		}
+
+		entryAttributes := map[string]interface{}{
+			"job.id":          job.ID,
+			"job.name":        job.Name,
+			"job.path":        jobPath,
+			"job.group":       job.Group,
+			"job.project":     job.Project,
+			"job.description": job.Description,
+			"job.enabled":     job.Enabled,
+			"job.scheduled":   job.Scheduled,
+		}
+		context := recon.AttributeMapping(entryAttributes, p.Config.AttributeMapping)
+
+		opt := recon.Option{
+			ProviderName:   p.Name(),
+			ProviderType:   p.Type(),
+			Id:             job.ID,
+			DisplayName:    fmt.Sprintf("%s [%s] - %s", jobPath, job.Project, job.Description),
+			Name:           jobPath,
+			Description:    job.Description,
+			Web:            job.Permalink,
+			StartDirectory: "~",
+			Tags:           []string{"rundeck", "job"},
+			Context:        context,
+			ModuleContext: map[string]string{
+				"rundeckHost": p.Config.Host,
+				"rundeckKind": queryJob,
+			},
+			Secrets: []string{secretToken},
+		}
+		opt.ProcessUserTemplateStrings(p.Config.DisplayName, p.Config.StartDirectory)
+		result = append(result, opt)
+	}
+
+	return result, nil
+}
+
+func (p Module) nodeOptions(client *Client, project string) ([]recon.Option, error) {
+	var result []recon.Option
+
+	log.Debug().Str("host", p.Config.Host).Str("project", project).Msg("querying rundeck nodes")
+	nodes, err := client.GetNodes(project)
+	if err != nil {
+		return nil, err
+	}
 
-			entryAttributes := map[string]interface{}{
-				"job.id":          job.ID,
-				"job.name":        job.Name,
-				"job.path":        jobPath,
-				"job.group":       job.Group,
-				"job.project":     job.Project,
-				"job.description": job.Description,
-				"job.enabled":     job.Enabled,
-				"job.scheduled":   job.Scheduled,
+	for name, node := range nodes {
+		entryAttributes := map[string]interface{}{
+			"node.project": project,
+		}
+		for key, value := range node {
+			if str := nodeAttributeString(value); str != "" {
+				entryAttributes["node."+key] = str
 			}
-			context := recon.AttributeMapping(entryAttributes, p.Config.AttributeMapping)
-
-			opt := recon.Option{
-				ProviderName:   p.Name(),
-				ProviderType:   p.Type(),
-				Id:             job.ID,
-				DisplayName:    fmt.Sprintf("%s [%s] - %s", jobPath, job.Project, job.Description),
-				Name:           jobPath,
-				Description:    job.Description,
-				Web:            job.Permalink,
-				StartDirectory: "~",
-				Tags:           []string{"rundeck", "job"},
-				Context:        context,
-				ModuleContext: map[string]string{
-					"rundeckHost":  p.Config.Host,
-					"rundeckToken": p.Config.AccessToken,
-				},
+		}
+		context := recon.AttributeMapping(entryAttributes, p.Config.AttributeMapping)
+
+		hostname := nodeAttributeString(node["hostname"])
+		sshTarget := hostname
+		if username := nodeAttributeString(node["username"]); username != "" {
+			sshTarget = username + "@" + hostname
+		}
+		tags := []string{"rundeck", "node"}
+		for _, tag := range strings.Split(nodeAttributeString(node["tags"]), ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				tags = append(tags, tag)
 			}
-			opt.ProcessUserTemplateStrings(p.Config.DisplayName, p.Config.StartDirectory)
-			result = append(result, opt)
 		}
+
+		opt := recon.Option{
+			ProviderName:   p.Name(),
+			ProviderType:   p.Type(),
+			Id:             fmt.Sprintf("%s/%s", project, name),
+			DisplayName:    fmt.Sprintf("%s [%s] - %s", name, project, hostname),
+			Name:           name,
+			Description:    nodeAttributeString(node["description"]),
+			Web:            fmt.Sprintf("%s/project/%s/nodes?filter=%s", p.Config.Host, url.PathEscape(project), url.QueryEscape("name: "+name)),
+			StartDirectory: "~",
+			Tags:           tags,
+			Context:        context,
+			ModuleContext: map[string]string{
+				"rundeckHost":     p.Config.Host,
+				"rundeckKind":     queryNode,
+				"rundeckNodeHost": hostname,
+				"rundeckNodeSSH":  sshTarget,
+			},
+			Secrets: []string{secretToken},
+		}
+		opt.ProcessUserTemplateStrings(p.Config.DisplayName, p.Config.StartDirectory)
+		result = append(result, opt)
 	}
 
+	// nodes are returned as map
+	slices.SortFunc(result, func(a, b recon.Option) int {
+		return strings.Compare(a.Id, b.Id)
+	})
+
 	return result, nil
 }
 
+// nodeAttributeString converts a node attribute to a string, attributes are usually strings but some sources return lists
+func nodeAttributeString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case []interface{}:
+		var values []string
+		for _, item := range v {
+			values = append(values, fmt.Sprint(item))
+		}
+		return strings.Join(values, ",")
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
 func (p Module) OptionsOrCache(maxAge float64) ([]recon.Option, error) {
 	return recon.OptionsOrCache(p, maxAge)
 }
 
+// ResolveSecret resolves the api token when the option is launched
+func (p Module) ResolveSecret(option *recon.Option, name string) (string, error) {
+	if name != secretToken {
+		return "", fmt.Errorf("unknown secret %s", name)
+	}
+	if err := p.Config.DecodeConfig(); err != nil {
+		return "", err
+	}
+
+	return p.Config.AccessToken, nil
+}
+
+// SelectOption adds the recent executions of the job to the context
 func (p Module) SelectOption(option *recon.Option) error {
 	err := option.CreateStartDirectoryIfMissing()
 	if err != nil {
 		return err
 	}
 
+	if option.ModuleContext["rundeckKind"] == queryNode {
+		return nil
+	}
+
+	if err := p.Config.DecodeConfig(); err != nil {
+		return fmt.Errorf("failed to resolve credentials: %w", err)
+	}
+	client := NewClient(p.Config.Host, p.Config.AccessToken)
+	executions, err := client.GetExecutions(option.Id, recentExecutions)
+	if err != nil {
+		return fmt.Errorf("failed to query executions of job %s: %w", option.Id, err)
+	}
+	if option.Context == nil {
+		option.Context = make(map[string]string)
+	}
+	executionContext(executions, option.Context)
+
 	return nil
 }
 
@@ -130,6 +294,10 @@ func (p Module) Columns() []recon.Column {
 }
 
 func NewModule(config ModuleConfig) Module {
+	if len(config.Query) == 0 {
+		config.Query = []string{queryJob}
+	}
+
 	return Module{
 		Config: config,
 	}
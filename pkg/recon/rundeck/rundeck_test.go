@@ -2,9 +2,14 @@ package rundeck
 
 import (
 	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
 
+	"github.com/PhilippHeuer/fuzzmux/pkg/recon"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/testcontainers/testcontainers-go"
 	"github.com/testcontainers/testcontainers-go/wait"
@@ -65,3 +70,50 @@ func TestSearchJobs(t *testing.T) {
 	require.Equal(t, "7fdae203-1822-4cdc-bf90-6e8e5a0e074a", options[0].Id)
 	require.Equal(t, "Awesome Job", options[0].Name)
 }
+
+func TestProjectDiscoveryAndNodes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/14/system/info":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"system": map[string]interface{}{"rundeck": map[string]interface{}{"apiversion": 99}}})
+		case "/api/47/projects":
+			_ = json.NewEncoder(w).Encode([]map[string]string{{"name": "example"}})
+		case "/api/47/project/example/jobs":
+			_ = json.NewEncoder(w).Encode([]map[string]interface{}{{"id": "1", "name": "Deploy", "group": "ops", "project": "example"}})
+		case "/api/47/project/example/resources":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"web01": map[string]interface{}{"nodename": "web01", "hostname": "web01.example.com", "username": "deploy", "osFamily": "unix", "tags": "web, prod"},
+				"db01":  map[string]interface{}{"nodename": "db01", "hostname": "db01.example.com"},
+			})
+		default:
+			t.Logf("unexpected request: %s", r.URL.String())
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	module := NewModule(ModuleConfig{Host: server.URL, AccessToken: "token", Query: []string{"job", "node"}})
+	options, err := module.Options()
+	require.NoError(t, err)
+	require.Len(t, options, 3)
+
+	assert.Equal(t, "ops/Deploy", options[0].Name)
+	assert.Equal(t, "example/db01", options[1].Id)
+
+	node := options[2]
+	assert.Equal(t, "example/web01", node.Id)
+	assert.Equal(t, "web01 [example] - web01.example.com", node.DisplayName)
+	assert.Equal(t, []string{"rundeck", "node", "web", "prod"}, node.Tags)
+	assert.Equal(t, "unix", node.Context["node.osFamily"])
+	assert.Equal(t, "deploy@web01.example.com", node.ModuleContext["rundeckNodeSSH"])
+
+	// the token is only referenced by name
+	assert.NotContains(t, node.ModuleContext, "rundeckToken")
+	assert.Equal(t, []string{"RD_TOKEN"}, node.Secrets)
+	token, err := module.ResolveSecret(&node, "RD_TOKEN")
+	require.NoError(t, err)
+	assert.Equal(t, "token", token)
+
+	// nodes don't have executions
+	require.NoError(t, module.SelectOption(&recon.Option{ModuleContext: node.ModuleContext, StartDirectory: t.TempDir()}))
+}
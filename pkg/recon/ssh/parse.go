@@ -1,76 +1,435 @@
 package ssh
 
 import (
+	"bufio"
+	"bytes"
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
 	"strings"
 
-	"github.com/kevinburke/ssh_config"
+	"github.com/PhilippHeuer/fuzzmux/pkg/util"
 )
 
-// ParseFile parses an ssh config file
-// NOTE: This manually resolves Include statements due to limitations in the ssh_config library
-func ParseFile(path string) (*ssh_config.Config, error) {
-	// read file
-	configBytes, err := os.ReadFile(path)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read SSH config file: %w", err)
+// maxIncludeDepth limits nested Include directives, same limit as OpenSSH
+const maxIncludeDepth = 16
+
+// multiValueKeys are keywords that accumulate values instead of using the first obtained value
+var multiValueKeys = []string{"identityfile", "certificatefile", "localforward", "remoteforward", "dynamicforward", "sendenv", "setenv"}
+
+// Config is a parsed ssh config, including all included files
+type Config struct {
+	Blocks []*Block
+}
+
+// Block is a Host or Match section, settings before the first section are stored in an implicit block that always matches
+type Block struct {
+	Patterns []string         // Patterns of a Host block
+	Criteria []MatchCriterion // Criteria of a Match block
+	IsMatch  bool             // IsMatch is true for Match blocks
+	Parent   *Block           // Parent is the enclosing block for conditional includes
+	Settings []Setting        // Settings in order of appearance
+	Tags     []string         // Tags are parsed from "# tag: <name>" comments
+}
+
+type MatchCriterion struct {
+	Negate  bool
+	Keyword string
+	Value   string
+}
+
+type Setting struct {
+	Key   string // Key is the lower-case keyword
+	Value string
+}
+
+// EffectiveConfig holds the evaluated settings for a single host alias, similar to the output of `ssh -G`
+type EffectiveConfig struct {
+	Alias    string
+	Settings map[string][]string
+	Tags     []string
+}
+
+// Get returns the first value of the given keyword
+func (e EffectiveConfig) Get(key string) string {
+	values := e.Settings[strings.ToLower(key)]
+	if len(values) == 0 {
+		return ""
 	}
+	return values[0]
+}
+
+// GetAll returns all values of the given keyword
+func (e EffectiveConfig) GetAll(key string) []string {
+	return e.Settings[strings.ToLower(key)]
+}
+
+// ParseFile parses an ssh config file, resolving Include statements relative to the directory of the file
+func ParseFile(path string) (*Config, error) {
+	config := &Config{}
+	root := &Block{Patterns: []string{"*"}}
+	config.Blocks = append(config.Blocks, root)
 
-	// resolve includes
-	configBytes, err = resolveIncludes(path, configBytes)
+	err := config.parseFile(path, filepath.Dir(path), root, 0)
 	if err != nil {
-		return nil, fmt.Errorf("failed to resolve includes: %w", err)
+		return nil, err
+	}
+
+	return config, nil
+}
+
+func (c *Config) parseFile(path string, baseDir string, current *Block, depth int) error {
+	if depth > maxIncludeDepth {
+		return fmt.Errorf("maximum include depth exceeded in '%s'", path)
 	}
 
-	// parse
-	sshConfig, err := ssh_config.DecodeBytes(configBytes)
+	content, err := os.ReadFile(path)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse ssh config: %w", err)
+		return fmt.Errorf("failed to read SSH config file: %w", err)
+	}
+
+	// blocks started in an included file inherit the condition of the block containing the Include
+	parent := current.Parent
+	if depth > 0 {
+		parent = current
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+
+		// comments
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "#") {
+			comment := strings.TrimSpace(strings.TrimPrefix(line, "#"))
+			if strings.HasPrefix(comment, "tag:") {
+				current.Tags = append(current.Tags, strings.TrimSpace(strings.TrimPrefix(comment, "tag:")))
+			}
+			continue
+		}
+
+		key, value := splitKeyValue(line)
+		switch key {
+		case "host":
+			current = &Block{Patterns: splitArgs(value), Parent: parent}
+			c.Blocks = append(c.Blocks, current)
+		case "match":
+			criteria, err := parseMatchCriteria(value)
+			if err != nil {
+				return fmt.Errorf("%s:%d: %w", path, lineNumber, err)
+			}
+			current = &Block{Criteria: criteria, IsMatch: true, Parent: parent}
+			c.Blocks = append(c.Blocks, current)
+		case "include":
+			files, err := resolveIncludes(baseDir, value)
+			if err != nil {
+				return fmt.Errorf("%s:%d: %w", path, lineNumber, err)
+			}
+			for _, file := range files {
+				err = c.parseFile(file, baseDir, current, depth+1)
+				if err != nil {
+					return err
+				}
+			}
+
+			// continue the current block after the include, keeping the order of settings
+			current = &Block{Patterns: current.Patterns, Criteria: current.Criteria, IsMatch: current.IsMatch, Parent: current.Parent}
+			c.Blocks = append(c.Blocks, current)
+		default:
+			current.Settings = append(current.Settings, Setting{Key: key, Value: unquote(value)})
+		}
 	}
 
-	return sshConfig, nil
+	return scanner.Err()
 }
 
-// resolveIncludes resolves Include statements in the SSH configuration file
-func resolveIncludes(rootFile string, configBytes []byte) ([]byte, error) {
-	// Split the configuration content into lines
-	lines := strings.Split(string(configBytes), "\n")
-	var resolvedConfig []string
+// resolveIncludes expands the (possibly globbed) paths of an Include directive, relative paths are resolved against baseDir
+func resolveIncludes(baseDir string, value string) ([]string, error) {
+	var files []string
 
-	for _, line := range lines {
-		if strings.HasPrefix(line, "Include") {
-			// Extract the file path from the Include statement
-			includePath := strings.TrimPrefix(line, "Include")
-			includePath = strings.TrimSpace(includePath)
-			if includePath == "" {
-				return nil, fmt.Errorf("include statement is missing a file path")
+	patterns := splitArgs(value)
+	if len(patterns) == 0 {
+		return nil, fmt.Errorf("include statement is missing a file path")
+	}
+
+	for _, pattern := range patterns {
+		pattern = strings.Replace(pattern, "~", os.Getenv("HOME"), 1)
+		pattern = os.ExpandEnv(pattern)
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(baseDir, pattern)
+		}
+
+		// missing files are ignored, like in OpenSSH
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid include pattern '%s': %w", pattern, err)
+		}
+		for _, match := range matches {
+			if info, err := os.Stat(match); err == nil && !info.IsDir() {
+				files = append(files, match)
 			}
+		}
+	}
+
+	return files, nil
+}
+
+// Aliases returns all concrete host aliases (without wildcards or negations) in order of appearance
+func (c *Config) Aliases() []string {
+	var aliases []string
+	seen := make(map[string]bool)
 
-			// resolve relative paths
-			includePath = strings.Replace(includePath, "~", os.Getenv("HOME"), 1)
-			includePath = os.ExpandEnv(includePath)
-			if !filepath.IsAbs(includePath) {
-				includePath = filepath.Join(filepath.Dir(rootFile), includePath)
+	for _, block := range c.Blocks {
+		if block.IsMatch {
+			continue
+		}
+		for _, pattern := range block.Patterns {
+			if strings.ContainsAny(pattern, "*?!") || seen[pattern] {
+				continue
 			}
+			seen[pattern] = true
+			aliases = append(aliases, pattern)
+		}
+	}
 
-			// Read the included file content
-			includeContent, err := os.ReadFile(includePath)
-			if err != nil {
-				return nil, fmt.Errorf("failed to read included file '%s': %w", includePath, err)
+	return aliases
+}
+
+// Resolve computes the effective configuration for the given alias, for each keyword the first obtained value is used
+func (c *Config) Resolve(alias string, localUser string) EffectiveConfig {
+	result := EffectiveConfig{
+		Alias:    alias,
+		Settings: make(map[string][]string),
+	}
+
+	matched := make(map[*Block]bool)
+	for _, block := range c.Blocks {
+		if !c.blockMatches(block, alias, localUser, result, matched) {
+			continue
+		}
+
+		for _, setting := range block.Settings {
+			if _, exists := result.Settings[setting.Key]; exists && !slices.Contains(multiValueKeys, setting.Key) {
+				continue
+			}
+			result.Settings[setting.Key] = append(result.Settings[setting.Key], setting.Value)
+		}
+		for _, tag := range block.Tags {
+			result.Tags = util.AddToSet(result.Tags, tag)
+		}
+	}
+
+	// defaults
+	if result.Get("hostname") == "" {
+		result.Settings["hostname"] = []string{alias}
+	}
+	result.Settings["hostname"] = []string{expandTokens(result.Get("hostname"), alias, alias, "", localUser)}
+	if result.Get("user") == "" {
+		result.Settings["user"] = []string{localUser}
+	}
+	if result.Get("port") == "" {
+		result.Settings["port"] = []string{"22"}
+	}
+	for i, file := range result.Settings["identityfile"] {
+		result.Settings["identityfile"][i] = expandTokens(file, result.Get("hostname"), alias, result.Get("user"), localUser)
+	}
+
+	return result
+}
+
+func (c *Config) blockMatches(block *Block, alias string, localUser string, state EffectiveConfig, matched map[*Block]bool) bool {
+	if block.Parent != nil && !matched[block.Parent] {
+		return false
+	}
+
+	var result bool
+	if block.IsMatch {
+		result = matchCriteria(block.Criteria, alias, localUser, state)
+	} else {
+		result = matchHostPatterns(block.Patterns, alias)
+	}
+	matched[block] = result
+
+	return result
+}
+
+// matchHostPatterns checks a Host line, a negated match excludes the block regardless of other patterns
+func matchHostPatterns(patterns []string, alias string) bool {
+	found := false
+	for _, pattern := range patterns {
+		negate := strings.HasPrefix(pattern, "!")
+		if matchPattern(strings.TrimPrefix(pattern, "!"), alias) {
+			if negate {
+				return false
+			}
+			found = true
+		}
+	}
+	return found
+}
+
+// matchPatternList checks a comma-separated pattern list, as used in Match criteria
+func matchPatternList(list string, value string) bool {
+	return matchHostPatterns(strings.Split(list, ","), value)
+}
+
+// matchCriteria evaluates the criteria of a Match block, all criteria must match
+// exec is never executed and treated as not matching, canonical is not supported and final is always true (evaluation of `ssh -G`)
+func matchCriteria(criteria []MatchCriterion, alias string, localUser string, state EffectiveConfig) bool {
+	hostname := state.Get("hostname")
+	if hostname == "" {
+		hostname = alias
+	}
+	user := state.Get("user")
+	if user == "" {
+		user = localUser
+	}
+
+	for _, criterion := range criteria {
+		var result bool
+		switch criterion.Keyword {
+		case "all", "final":
+			result = true
+		case "canonical", "exec":
+			result = false
+		case "host":
+			result = matchPatternList(criterion.Value, expandTokens(hostname, alias, alias, user, localUser))
+		case "originalhost":
+			result = matchPatternList(criterion.Value, alias)
+		case "user":
+			result = matchPatternList(criterion.Value, user)
+		case "localuser":
+			result = matchPatternList(criterion.Value, localUser)
+		default:
+			result = false
+		}
+
+		if criterion.Negate {
+			result = !result
+		}
+		if !result {
+			return false
+		}
+	}
+
+	return true
+}
+
+func parseMatchCriteria(value string) ([]MatchCriterion, error) {
+	var criteria []MatchCriterion
+
+	args := splitArgs(value)
+	for i := 0; i < len(args); i++ {
+		criterion := MatchCriterion{Keyword: strings.ToLower(args[i])}
+		if strings.HasPrefix(criterion.Keyword, "!") {
+			criterion.Negate = true
+			criterion.Keyword = strings.TrimPrefix(criterion.Keyword, "!")
+		}
+
+		switch criterion.Keyword {
+		case "all", "canonical", "final":
+			// no argument
+		default:
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("match criterion '%s' requires an argument", criterion.Keyword)
 			}
+			i++
+			criterion.Value = args[i]
+		}
+		criteria = append(criteria, criterion)
+	}
+
+	if len(criteria) == 0 {
+		return nil, fmt.Errorf("match directive requires at least one criterion")
+	}
+
+	return criteria, nil
+}
+
+// expandTokens expands the supported ssh_config tokens, see TOKENS in ssh_config(5)
+func expandTokens(value string, hostname string, alias string, remoteUser string, localUser string) string {
+	value = strings.Replace(value, "~", os.Getenv("HOME"), 1)
+
+	replacer := strings.NewReplacer(
+		"%%", "%",
+		"%h", hostname,
+		"%n", alias,
+		"%r", remoteUser,
+		"%u", localUser,
+		"%d", os.Getenv("HOME"),
+	)
+	return replacer.Replace(value)
+}
 
-			// Insert the included content into the resolved configuration
-			resolvedConfig = append(resolvedConfig, string(includeContent))
-		} else {
-			// Preserve other lines as they are
-			resolvedConfig = append(resolvedConfig, line)
+// matchPattern matches a single ssh pattern, supporting the wildcards * and ?
+func matchPattern(pattern string, value string) bool {
+	var expr strings.Builder
+	expr.WriteString("^")
+	for _, r := range pattern {
+		switch r {
+		case '*':
+			expr.WriteString(".*")
+		case '?':
+			expr.WriteString(".")
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(r)))
 		}
 	}
+	expr.WriteString("$")
 
-	// Join the resolved lines and convert them back to bytes
-	resolvedBytes := []byte(strings.Join(resolvedConfig, "\n"))
+	matched, err := regexp.MatchString(expr.String(), value)
+	return err == nil && matched
+}
+
+// splitKeyValue splits a config line into the lower-case keyword and the value, supporting "Key Value" and "Key=Value"
+func splitKeyValue(line string) (string, string) {
+	idx := strings.IndexAny(line, " \t=")
+	if idx == -1 {
+		return strings.ToLower(line), ""
+	}
+
+	key := strings.ToLower(line[:idx])
+	value := strings.TrimSpace(line[idx:])
+	value = strings.TrimSpace(strings.TrimPrefix(value, "="))
 
-	return resolvedBytes, nil
+	return key, value
+}
+
+// splitArgs splits a value by whitespace, respecting double quotes
+func splitArgs(value string) []string {
+	var args []string
+	var current strings.Builder
+	inQuotes := false
+
+	for _, r := range value {
+		switch {
+		case r == '"':
+			inQuotes = !inQuotes
+		case (r == ' ' || r == '\t') && !inQuotes:
+			if current.Len() > 0 {
+				args = append(args, current.String())
+				current.Reset()
+			}
+		default:
+			current.WriteRune(r)
+		}
+	}
+	if current.Len() > 0 {
+		args = append(args, current.String())
+	}
+
+	return args
+}
+
+func unquote(value string) string {
+	if len(value) >= 2 && strings.HasPrefix(value, `"`) && strings.HasSuffix(value, `"`) {
+		return value[1 : len(value)-1]
+	}
+	return value
 }
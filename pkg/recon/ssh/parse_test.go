@@ -0,0 +1,104 @@
+package ssh
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFile(t *testing.T) {
+	t.Setenv("HOME", "/home/test")
+	configFile, _ := filepath.Abs("testdata/config")
+
+	config, err := ParseFile(configFile)
+	require.NoError(t, err)
+	require.Equal(t, []string{"lab", "github.com", "bastion", "web01", "web02", "db"}, config.Aliases())
+
+	tests := []struct {
+		alias        string
+		hostname     string
+		user         string
+		port         string
+		proxyJump    string
+		identityFile []string
+		tags         []string
+	}{
+		{
+			alias:        "bastion",
+			hostname:     "bastion.example.com",
+			user:         "admin",
+			port:         "22",
+			identityFile: []string{"/home/test/.ssh/id_ed25519"},
+		},
+		{
+			alias:        "web01",
+			hostname:     "web01.internal.example.com",
+			user:         "local",
+			port:         "22",
+			proxyJump:    "bastion",
+			identityFile: []string{"/home/test/.ssh/id_ed25519"},
+			tags:         []string{"production"},
+		},
+		{
+			alias:        "db",
+			hostname:     "db.internal.example.com",
+			user:         "dba",
+			port:         "2222",
+			identityFile: []string{"/home/test/.ssh/id_db", "/home/test/.ssh/id_ed25519"},
+		},
+		{
+			alias:        "lab",
+			hostname:     "10.0.0.5",
+			user:         "lab user",
+			port:         "22",
+			identityFile: []string{"/home/test/.ssh/id_ed25519"},
+		},
+		{
+			alias:        "github.com",
+			hostname:     "github.com",
+			user:         "git",
+			port:         "22",
+			identityFile: []string{"/home/test/.ssh/id_ed25519"},
+			tags:         []string{"hidden"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.alias, func(t *testing.T) {
+			conf := config.Resolve(tt.alias, "local")
+			require.Equal(t, tt.hostname, conf.Get("hostname"))
+			require.Equal(t, tt.user, conf.Get("user"))
+			require.Equal(t, tt.port, conf.Get("port"))
+			require.Equal(t, tt.proxyJump, conf.Get("proxyjump"))
+			require.Equal(t, tt.identityFile, conf.GetAll("identityfile"))
+			require.Equal(t, tt.tags, conf.Tags)
+		})
+	}
+}
+
+func TestParseFileConditionalInclude(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "config"), []byte("Host prod-*\n    Include prod.conf\n    Port 2200\n\nHost prod-db dev-db\n"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "prod.conf"), []byte("User ops\n"), 0600))
+
+	config, err := ParseFile(filepath.Join(dir, "config"))
+	require.NoError(t, err)
+
+	prod := config.Resolve("prod-db", "local")
+	require.Equal(t, "ops", prod.Get("user"))
+	require.Equal(t, "2200", prod.Get("port"))
+
+	dev := config.Resolve("dev-db", "local")
+	require.Equal(t, "local", dev.Get("user"))
+	require.Equal(t, "22", dev.Get("port"))
+}
+
+func TestParseFileIncludeDepth(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "config"), []byte("Include config\n"), 0600))
+
+	_, err := ParseFile(filepath.Join(dir, "config"))
+	require.Error(t, err)
+}
@@ -0,0 +1,192 @@
+package ssh
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// Destination is a host found in known_hosts or shell history files
+type Destination struct {
+	User string
+	Host string
+	Port string
+}
+
+// Key returns the deduplication key, the port is only included if it is not the default port
+func (d Destination) Key() string {
+	if d.Port == "" || d.Port == "22" {
+		return d.Host
+	}
+	return net.JoinHostPort(d.Host, d.Port)
+}
+
+// Target returns the destination in a format accepted by ssh, ssh:// is used if a custom port is required
+func (d Destination) Target() string {
+	target := d.Host
+	if d.User != "" {
+		target = d.User + "@" + d.Host
+	}
+	if d.Port != "" && d.Port != "22" {
+		return "ssh://" + target + ":" + d.Port
+	}
+	return target
+}
+
+// sshFlagsWithArgument are the ssh options that consume the next argument
+const sshFlagsWithArgument = "BbcDEeFIiJLlmOopQRSWw"
+
+// ParseKnownHosts parses the hosts of a known_hosts file, hashed entries, wildcards and revoked keys are skipped
+func ParseKnownHosts(path string) ([]Destination, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read known_hosts file: %w", err)
+	}
+
+	var result []Destination
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+
+		// markers, e.g. @cert-authority or @revoked
+		if strings.HasPrefix(fields[0], "@") {
+			continue
+		}
+
+		for _, entry := range strings.Split(fields[0], ",") {
+			if strings.HasPrefix(entry, "|") || strings.ContainsAny(entry, "*?!") {
+				continue
+			}
+
+			dest := Destination{Host: entry}
+			if strings.HasPrefix(entry, "[") {
+				host, port, err := net.SplitHostPort(entry)
+				if err != nil {
+					continue
+				}
+				dest = Destination{Host: strings.Trim(host, "[]"), Port: port}
+			}
+			result = append(result, dest)
+		}
+	}
+
+	return result, scanner.Err()
+}
+
+// ParseHistoryFile extracts ssh destinations from a bash, zsh or fish history file
+func ParseHistoryFile(path string) ([]Destination, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history file: %w", err)
+	}
+
+	var result []Destination
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := historyCommand(scanner.Text())
+
+		// split command chains
+		for _, segment := range strings.FieldsFunc(line, func(r rune) bool { return r == ';' || r == '&' || r == '|' }) {
+			if dest, ok := parseSSHCommand(segment); ok {
+				result = append(result, dest)
+			}
+		}
+	}
+
+	return result, scanner.Err()
+}
+
+// historyCommand strips the history format specific prefixes, e.g. zsh extended history or fish yaml
+func historyCommand(line string) string {
+	line = strings.TrimSpace(line)
+
+	// zsh extended history: ": 1700000000:0;ssh host"
+	if strings.HasPrefix(line, ": ") {
+		if idx := strings.Index(line, ";"); idx != -1 {
+			return line[idx+1:]
+		}
+	}
+
+	// fish: "- cmd: ssh host"
+	if strings.HasPrefix(line, "- cmd: ") {
+		return strings.TrimPrefix(line, "- cmd: ")
+	}
+
+	return line
+}
+
+// parseSSHCommand parses a single ssh invocation, e.g. "ssh -p 2222 -l admin host"
+func parseSSHCommand(command string) (Destination, bool) {
+	args := strings.Fields(command)
+	for len(args) > 0 && (args[0] == "exec" || args[0] == "sudo" || args[0] == "command") {
+		args = args[1:]
+	}
+	if len(args) < 2 || args[0] != "ssh" {
+		return Destination{}, false
+	}
+
+	var dest Destination
+	for i := 1; i < len(args); i++ {
+		arg := args[i]
+		if strings.HasPrefix(arg, "-") && len(arg) > 1 {
+			// combined flags (-vp 2222), the value can be attached (-p2222) or the next argument (-p 2222)
+			for j := 1; j < len(arg); j++ {
+				if !strings.ContainsRune(sshFlagsWithArgument, rune(arg[j])) {
+					continue
+				}
+
+				value := arg[j+1:]
+				if value == "" && i+1 < len(args) {
+					i++
+					value = args[i]
+				}
+
+				switch arg[j] {
+				case 'p':
+					dest.Port = value
+				case 'l':
+					dest.User = value
+				}
+				break
+			}
+			continue
+		}
+
+		// destination: [user@]host or ssh://[user@]host[:port]
+		if strings.HasPrefix(arg, "ssh://") {
+			u, err := url.Parse(arg)
+			if err != nil || u.Hostname() == "" {
+				return Destination{}, false
+			}
+			dest.Host = u.Hostname()
+			if u.Port() != "" {
+				dest.Port = u.Port()
+			}
+			if u.User != nil {
+				dest.User = u.User.Username()
+			}
+		} else {
+			if user, host, found := strings.Cut(arg, "@"); found {
+				dest.User = user
+				arg = host
+			}
+			dest.Host = arg
+		}
+		break
+	}
+
+	// skip variables, substitutions and other non-literal hosts
+	if dest.Host == "" || strings.ContainsAny(dest.Host, "$`'\"(){}<>*?") {
+		return Destination{}, false
+	}
+
+	return dest, true
+}
@@ -0,0 +1,83 @@
+package ssh
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseKnownHosts(t *testing.T) {
+	destinations, err := ParseKnownHosts("testdata/known_hosts")
+	require.NoError(t, err)
+
+	require.Equal(t, []Destination{
+		{Host: "bastion.example.com"},
+		{Host: "git.example.com"},
+		{Host: "192.168.1.10"},
+		{Host: "build.example.com", Port: "2222"},
+	}, destinations)
+}
+
+func TestParseHistoryFile(t *testing.T) {
+	tests := []struct {
+		file     string
+		expected []Destination
+	}{
+		{
+			file: "testdata/zsh_history",
+			expected: []Destination{
+				{User: "admin", Host: "web01.example.com"},
+				{User: "deploy", Host: "app.example.com", Port: "2222"},
+				{User: "ops", Host: "db.example.com", Port: "2200"},
+			},
+		},
+		{
+			file: "testdata/fish_history",
+			expected: []Destination{
+				{Host: "lab.example.com", Port: "2200"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			destinations, err := ParseHistoryFile(tt.file)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, destinations)
+		})
+	}
+}
+
+func TestDestinationTarget(t *testing.T) {
+	require.Equal(t, "host", Destination{Host: "host"}.Target())
+	require.Equal(t, "admin@host", Destination{User: "admin", Host: "host", Port: "22"}.Target())
+	require.Equal(t, "ssh://admin@host:2222", Destination{User: "admin", Host: "host", Port: "2222"}.Target())
+}
+
+func TestOptionsWithAdditionalSources(t *testing.T) {
+	t.Setenv("HOME", "/home/test")
+
+	sshModule := NewModule(ModuleConfig{
+		ConfigFile:     "testdata/config",
+		KnownHosts:     true,
+		KnownHostsFile: "testdata/known_hosts",
+		History:        true,
+		HistoryFiles:   []string{"testdata/zsh_history", "testdata/known_hosts_missing"},
+	})
+	options, err := sshModule.Options()
+	require.NoError(t, err)
+
+	var names []string
+	for _, o := range options {
+		names = append(names, o.Name)
+	}
+
+	// bastion.example.com is deduplicated against the bastion config alias
+	require.Equal(t, []string{
+		"lab", "github.com", "bastion", "web01", "web02", "db",
+		"git.example.com", "192.168.1.10", "ssh://build.example.com:2222",
+		"admin@web01.example.com", "ssh://deploy@app.example.com:2222", "ssh://ops@db.example.com:2200",
+	}, names)
+	require.Contains(t, options[6].Tags, "known-hosts")
+	require.Contains(t, options[9].Tags, "history")
+}
@@ -2,15 +2,25 @@ package ssh
 
 import (
 	"fmt"
-	"github.com/PhilippHeuer/fuzzmux/pkg/recon"
 	"os"
+	"os/user"
 	"path/filepath"
+	"slices"
 	"strings"
+
+	"github.com/PhilippHeuer/fuzzmux/pkg/recon"
+	"github.com/rs/zerolog/log"
 )
 
 const moduleType = "ssh"
 
 var DefaultPath = filepath.Join(os.Getenv("HOME"), ".ssh", "config")
+var DefaultKnownHostsPath = filepath.Join(os.Getenv("HOME"), ".ssh", "known_hosts")
+var DefaultHistoryPaths = []string{
+	filepath.Join(os.Getenv("HOME"), ".bash_history"),
+	filepath.Join(os.Getenv("HOME"), ".zsh_history"),
+	filepath.Join(os.Getenv("HOME"), ".local", "share", "fish", "fish_history"),
+}
 
 type Module struct {
 	Config ModuleConfig
@@ -31,6 +41,18 @@ type ModuleConfig struct {
 
 	// Mode controls how sessions or windows are created for SSH connections
 	Mode SSHMode `yaml:"mode"`
+
+	// KnownHosts imports hosts from the known_hosts file (default: false)
+	KnownHosts bool `yaml:"known-hosts"`
+
+	// KnownHostsFile is used in case your known_hosts file is not in the default location
+	KnownHostsFile string `yaml:"known-hosts-file"`
+
+	// History imports hosts from ssh invocations in shell history files (default: false)
+	History bool `yaml:"history"`
+
+	// HistoryFiles is a list of bash, zsh or fish history files, defaults to the common history locations
+	HistoryFiles []string `yaml:"history-files"`
 }
 
 type SSHMode string
@@ -40,6 +62,12 @@ const (
 	SSHWindowMode  SSHMode = "window"
 )
 
+const (
+	sourceConfig     = "ssh-config"
+	sourceKnownHosts = "known-hosts"
+	sourceHistory    = "history"
+)
+
 func (p Module) Name() string {
 	if p.Config.Name != "" {
 		return p.Config.Name
@@ -59,54 +87,108 @@ func (p Module) Options() ([]recon.Option, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse ssh config: %w", err)
 	}
-	for _, host := range sshConfig.Hosts {
-		for _, pattern := range host.Patterns {
-			// skip wildcards
-			if strings.Contains(pattern.String(), "*") || strings.Contains(pattern.String(), "?") {
-				continue
-			}
 
-			// parse
-			name := pattern.String()
-			hostname := ""
-			user := "root@"
-			var tags []string
-			for _, node := range host.Nodes {
-				line := strings.TrimSpace(node.String())
-				if strings.HasPrefix(line, "Hostname ") {
-					hostname = strings.TrimSpace(strings.TrimPrefix(line, "Hostname "))
-				}
-				if strings.HasPrefix(line, "User ") {
-					user = strings.TrimSpace(strings.TrimPrefix(line, "User ")) + "@"
-				}
-
-				if strings.HasPrefix(line, "# tag: ") {
-					tags = append(tags, strings.TrimSpace(strings.TrimPrefix(line, "# tag: ")))
-				}
-			}
+	localUser := currentUser()
+	seen := make(map[string]bool)
+	for _, alias := range sshConfig.Aliases() {
+		// effective config, equivalent to ssh -G
+		conf := sshConfig.Resolve(alias, localUser)
+		hostname := conf.Get("hostname")
+		username := conf.Get("user")
+		port := conf.Get("port")
+
+		target := fmt.Sprintf("%s@%s", username, hostname)
+		if port != "22" {
+			target = fmt.Sprintf("%s:%s", target, port)
+		}
+
+		// result
+		opt := recon.Option{
+			ProviderName:   p.Name(),
+			ProviderType:   p.Type(),
+			Id:             alias,
+			DisplayName:    fmt.Sprintf("%s [%s]", alias, target),
+			Name:           alias,
+			StartDirectory: p.Config.StartDirectory,
+			Tags:           append(conf.Tags, sourceConfig),
+			Context: map[string]string{
+				"host":         hostname,
+				"user":         username,
+				"port":         port,
+				"proxyJump":    conf.Get("proxyjump"),
+				"proxyCommand": conf.Get("proxycommand"),
+				"identityFile": strings.Join(conf.GetAll("identityfile"), ", "),
+				"source":       sourceConfig,
+			},
+		}
+		opt.ProcessUserTemplateStrings(p.Config.DisplayName, p.Config.StartDirectory)
+		result = append(result, opt)
+
+		seen[alias] = true
+		seen[Destination{Host: hostname, Port: port}.Key()] = true
+	}
+
+	// known_hosts
+	if p.Config.KnownHosts {
+		destinations, err := ParseKnownHosts(p.Config.KnownHostsFile)
+		if err != nil {
+			log.Warn().Err(err).Str("file", p.Config.KnownHostsFile).Msg("failed to parse known_hosts")
+		}
+		result = append(result, p.destinationOptions(destinations, sourceKnownHosts, seen)...)
+	}
 
-			// result
-			opt := recon.Option{
-				ProviderName:   p.Name(),
-				ProviderType:   p.Type(),
-				Id:             name,
-				DisplayName:    fmt.Sprintf("%s [%s%s]", name, user, hostname),
-				Name:           name,
-				StartDirectory: p.Config.StartDirectory,
-				Tags:           tags,
-				Context: map[string]string{
-					"host": hostname,
-					"user": strings.TrimRight(user, "@"),
-				},
+	// shell history
+	if p.Config.History {
+		for _, file := range p.Config.HistoryFiles {
+			destinations, err := ParseHistoryFile(file)
+			if err != nil {
+				log.Debug().Err(err).Str("file", file).Msg("skipping history file")
+				continue
 			}
-			opt.ProcessUserTemplateStrings(p.Config.DisplayName, p.Config.StartDirectory)
-			result = append(result, opt)
+			result = append(result, p.destinationOptions(destinations, sourceHistory, seen)...)
 		}
 	}
 
 	return result, nil
 }
 
+// destinationOptions converts hosts from additional sources into options, skipping hosts that were already seen
+func (p Module) destinationOptions(destinations []Destination, source string, seen map[string]bool) []recon.Option {
+	var result []recon.Option
+
+	for _, dest := range destinations {
+		if seen[dest.Key()] {
+			continue
+		}
+		seen[dest.Key()] = true
+
+		port := dest.Port
+		if port == "" {
+			port = "22"
+		}
+
+		opt := recon.Option{
+			ProviderName:   p.Name(),
+			ProviderType:   p.Type(),
+			Id:             source + ":" + dest.Target(),
+			DisplayName:    fmt.Sprintf("%s [%s]", dest.Target(), source),
+			Name:           dest.Target(),
+			StartDirectory: p.Config.StartDirectory,
+			Tags:           []string{source},
+			Context: map[string]string{
+				"host":   dest.Host,
+				"user":   dest.User,
+				"port":   port,
+				"source": source,
+			},
+		}
+		opt.ProcessUserTemplateStrings(p.Config.DisplayName, p.Config.StartDirectory)
+		result = append(result, opt)
+	}
+
+	return result
+}
+
 func (p Module) OptionsOrCache(maxAge float64) ([]recon.Option, error) {
 	return recon.OptionsOrCache(p, maxAge)
 }
@@ -124,6 +206,8 @@ func (p Module) Columns() []recon.Column {
 	return append(recon.DefaultColumns(),
 		recon.Column{Key: "host", Name: "Host"},
 		recon.Column{Key: "user", Name: "User"},
+		recon.Column{Key: "port", Name: "Port", Hidden: true},
+		recon.Column{Key: "proxyJump", Name: "Proxy Jump", Hidden: true},
 	)
 }
 
@@ -131,8 +215,25 @@ func NewModule(config ModuleConfig) Module {
 	if config.ConfigFile == "" {
 		config.ConfigFile = DefaultPath
 	}
+	if config.KnownHostsFile == "" {
+		config.KnownHostsFile = DefaultKnownHostsPath
+	}
+	if len(config.HistoryFiles) == 0 {
+		config.HistoryFiles = DefaultHistoryPaths
+		if histFile := os.Getenv("HISTFILE"); histFile != "" && !slices.Contains(config.HistoryFiles, histFile) {
+			config.HistoryFiles = append(config.HistoryFiles, histFile)
+		}
+	}
 
 	return Module{
 		Config: config,
 	}
 }
+
+// currentUser returns the local username, which is used by ssh if no User is configured
+func currentUser() string {
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return os.Getenv("USER")
+}
@@ -3,10 +3,10 @@ package usql
 import (
 	"fmt"
 	"github.com/PhilippHeuer/fuzzmux/pkg/recon"
-	"github.com/PhilippHeuer/fuzzmux/pkg/util"
 	"os"
 	"path/filepath"
 	"strings"
+	"unicode"
 
 	"github.com/rs/zerolog/log"
 )
@@ -54,9 +54,9 @@ func (p Module) Options() ([]recon.Option, error) {
 	}
 
 	for key, conn := range conf.Connections {
-		// key needs to be alphanumeric
-		if !util.IsAlphanumeric(key) {
-			log.Warn().Str("key", key).Msg("skipping non-alphanumeric key")
+		// key is passed to usql as argument
+		if !validKey(key) {
+			log.Warn().Str("key", key).Msg("skipping key with characters other than letters, digits, dashes or underscores")
 			continue
 		}
 
@@ -65,29 +65,32 @@ func (p Module) Options() ([]recon.Option, error) {
 			ProviderName:   p.Name(),
 			ProviderType:   p.Type(),
 			Id:             "usql-" + key,
-			DisplayName:    fmt.Sprintf("%s @ %s:%d", conn.Username, conn.Hostname, conn.Port),
+			DisplayName:    conn.Target(),
 			Name:           key,
 			StartDirectory: p.Config.StartDirectory,
 			Context: map[string]string{
 				"name":     conn.Name,
+				"protocol": conn.Protocol,
 				"hostname": conn.Hostname,
-				"port":     fmt.Sprintf("%d", conn.Port),
 				"username": conn.Username,
 				"instance": conn.Instance,
 				"database": conn.Database,
+				"path":     conn.Path,
+				"dsn":      conn.RedactedDSN(),
 			},
 		}
-
-		// additional info (SID, instance, database, ...)
-		var additionalInfo []string
-		if conn.Instance != "" {
-			additionalInfo = append(additionalInfo, conn.Instance)
+		if conn.Port != 0 {
+			opt.Context["port"] = fmt.Sprintf("%d", conn.Port)
 		}
-		if conn.Database != "" {
-			additionalInfo = append(additionalInfo, conn.Database)
+		for k, v := range conn.Params {
+			opt.Context["params."+k] = v
 		}
-		if len(additionalInfo) > 0 {
-			opt.DisplayName = fmt.Sprintf("%s @ %s:%d [%s]", conn.Username, conn.Hostname, conn.Port, strings.Join(additionalInfo, ":"))
+
+		// remove empty values, placeholders without a value are not replaced
+		for k, v := range opt.Context {
+			if v == "" {
+				delete(opt.Context, k)
+			}
 		}
 
 		// named connections
@@ -115,6 +118,20 @@ func (p Module) SelectOption(option *recon.Option) error {
 	return nil
 }
 
+// Preview shows the connection details without credentials
+func (p Module) Preview(option *recon.Option) string {
+	var builder strings.Builder
+	recon.PreviewField(&builder, "Name", option.Context["name"])
+	recon.PreviewField(&builder, "DB Host", option.Context["hostname"])
+	recon.PreviewField(&builder, "DB Port", option.Context["port"])
+	recon.PreviewField(&builder, "DB Username", option.Context["username"])
+	recon.PreviewField(&builder, "DB Instance/SID", option.Context["instance"])
+	recon.PreviewField(&builder, "DB Database", option.Context["database"])
+	recon.PreviewField(&builder, "DB Path", option.Context["path"])
+	recon.PreviewField(&builder, "DSN", option.Context["dsn"])
+	return builder.String()
+}
+
 func (p Module) Columns() []recon.Column {
 	return append(recon.DefaultColumns(),
 		recon.Column{Key: "host", Name: "Host"},
@@ -122,6 +139,20 @@ func (p Module) Columns() []recon.Column {
 	)
 }
 
+// validKey checks that the connection name only contains letters, digits, dashes or underscores
+func validKey(key string) bool {
+	if key == "" {
+		return false
+	}
+
+	for _, r := range key {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' && r != '_' {
+			return false
+		}
+	}
+	return true
+}
+
 func NewModule(config ModuleConfig) Module {
 	if config.ConfigFile == "" {
 		config.ConfigFile = USQLConfigDefaultPath
@@ -0,0 +1,29 @@
+package secret
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// bitwardenFields are the fields supported by bw get
+var bitwardenFields = []string{"password", "username", "totp", "notes", "uri"}
+
+// bitwardenResolver reads an item using the Bitwarden cli, the session is taken from BW_SESSION, e.g. bw:jira or bw:jira#username
+type bitwardenResolver struct{}
+
+func (bitwardenResolver) Prefix() string {
+	return "bw"
+}
+
+func (bitwardenResolver) Resolve(reference string) (string, error) {
+	item, field, found := strings.Cut(reference, "#")
+	if !found {
+		field = "password"
+	}
+	if !slices.Contains(bitwardenFields, field) {
+		return "", fmt.Errorf("unsupported bitwarden field %q, supported: %s", field, strings.Join(bitwardenFields, ", "))
+	}
+
+	return runCommand("bw", "get", field, item)
+}
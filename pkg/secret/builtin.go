@@ -0,0 +1,72 @@
+package secret
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// envResolver reads environment variables, e.g. env:JIRA_TOKEN
+type envResolver struct{}
+
+func (envResolver) Prefix() string {
+	return "env"
+}
+
+func (envResolver) Resolve(reference string) (string, error) {
+	return os.Getenv(reference), nil
+}
+
+// fileResolver reads the content of a file without the trailing newline, e.g. file:~/.config/jira/token
+type fileResolver struct{}
+
+func (fileResolver) Prefix() string {
+	return "file"
+}
+
+func (fileResolver) Resolve(reference string) (string, error) {
+	file := strings.Replace(reference, "~", os.Getenv("HOME"), 1)
+	file = os.ExpandEnv(file)
+
+	bytes, err := os.ReadFile(file)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(bytes), "\r\n"), nil
+}
+
+// passResolver reads the first line of a pass entry, e.g. pass:work/jira
+type passResolver struct{}
+
+func (passResolver) Prefix() string {
+	return "pass"
+}
+
+func (passResolver) Resolve(reference string) (string, error) {
+	out, err := runCommand("pass", "show", os.ExpandEnv(reference))
+	if err != nil {
+		return "", err
+	}
+
+	line, _, _ := strings.Cut(out, "\n")
+	return strings.TrimSpace(line), nil
+}
+
+// commandResolver runs a shell command, requires FUZZMUX_ALLOW_COMMANDS=true, e.g. cmd:gopass show -o jira
+type commandResolver struct{}
+
+func (commandResolver) Prefix() string {
+	return "cmd"
+}
+
+func (commandResolver) Resolve(reference string) (string, error) {
+	if os.Getenv("FUZZMUX_ALLOW_COMMANDS") != "true" {
+		return "", fmt.Errorf("commands are disabled, set FUZZMUX_ALLOW_COMMANDS=true to allow them")
+	}
+
+	out, err := runCommand("sh", "-c", reference)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out), nil
+}
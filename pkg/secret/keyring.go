@@ -0,0 +1,39 @@
+package secret
+
+import (
+	"fmt"
+	"strings"
+)
+
+// keyringResolver reads a secret of the freedesktop secret service (e.g. gnome-keyring, kwallet) using secret-tool
+// the reference is either service/account (e.g. keyring:jira/jane) or a list of attributes (e.g. keyring:service=jira,username=jane)
+type keyringResolver struct{}
+
+func (keyringResolver) Prefix() string {
+	return "keyring"
+}
+
+func (keyringResolver) Resolve(reference string) (string, error) {
+	args := []string{"lookup"}
+	if strings.Contains(reference, "=") {
+		for _, attribute := range strings.Split(reference, ",") {
+			key, value, _ := strings.Cut(attribute, "=")
+			args = append(args, strings.TrimSpace(key), strings.TrimSpace(value))
+		}
+	} else {
+		service, account, found := strings.Cut(reference, "/")
+		if !found {
+			return "", fmt.Errorf("invalid keyring reference %q, expected service/account or attribute=value pairs", reference)
+		}
+		args = append(args, "service", service, "username", account)
+	}
+
+	out, err := runCommand("secret-tool", args...)
+	if err != nil {
+		return "", err
+	}
+	if out == "" {
+		return "", fmt.Errorf("no keyring entry found for %q", reference)
+	}
+	return out, nil
+}
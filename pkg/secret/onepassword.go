@@ -0,0 +1,20 @@
+package secret
+
+import (
+	"strings"
+)
+
+// onePasswordResolver reads a secret reference using the 1Password cli, e.g. op:op://Private/Jira/token or op:Private/Jira/token
+type onePasswordResolver struct{}
+
+func (onePasswordResolver) Prefix() string {
+	return "op"
+}
+
+func (onePasswordResolver) Resolve(reference string) (string, error) {
+	if !strings.HasPrefix(reference, "op://") {
+		reference = "op://" + reference
+	}
+
+	return runCommand("op", "read", "--no-newline", reference)
+}
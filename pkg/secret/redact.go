@@ -0,0 +1,128 @@
+package secret
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"slices"
+	"strings"
+)
+
+// Redacted replaces secret values in exports and logs
+const Redacted = "********"
+
+// minRedactLength avoids replacing short values, which would make unrelated output unreadable
+const minRedactLength = 4
+
+// sensitiveKeys are parts of keys that hold secrets, e.g. bindPassword, RD_TOKEN or api-key
+var sensitiveKeys = []string{"password", "passwd", "token", "secret", "apikey", "api-key", "api_key", "credential"}
+
+var sensitiveValues []string
+
+// MarkSensitive registers values that are redacted in addition to the resolved secret references
+func MarkSensitive(values ...string) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	for _, v := range values {
+		if len(v) >= minRedactLength && !slices.Contains(sensitiveValues, v) {
+			sensitiveValues = append(sensitiveValues, v)
+		}
+	}
+}
+
+// IsSensitiveKey checks if the key of a map or struct field holds a secret
+func IsSensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, k := range sensitiveKeys {
+		if strings.Contains(key, k) {
+			return true
+		}
+	}
+	return false
+}
+
+// Redact replaces all secrets that have been resolved or marked as sensitive in the current run
+func Redact(value string) string {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	for _, v := range cache {
+		if len(v) >= minRedactLength {
+			value = strings.ReplaceAll(value, v, Redacted)
+		}
+	}
+	for _, v := range sensitiveValues {
+		value = strings.ReplaceAll(value, v, Redacted)
+	}
+	return value
+}
+
+// RedactValue redacts the value of a sensitive key entirely, other values are passed to Redact
+func RedactValue(key string, value string) string {
+	if value != "" && IsSensitiveKey(key) {
+		return Redacted
+	}
+	return Redact(value)
+}
+
+// RedactMap returns a copy of the map with all sensitive values redacted
+func RedactMap(values map[string]string) map[string]string {
+	if values == nil {
+		return nil
+	}
+
+	result := make(map[string]string, len(values))
+	for k, v := range values {
+		result[k] = RedactValue(k, v)
+	}
+	return result
+}
+
+// NewRedactWriter returns a writer for zerolog, which redacts sensitive fields and secret values of json log events
+func NewRedactWriter(out io.Writer) io.Writer {
+	return redactWriter{out: out}
+}
+
+type redactWriter struct {
+	out io.Writer
+}
+
+func (w redactWriter) Write(p []byte) (int, error) {
+	var event map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(p))
+	decoder.UseNumber()
+	if err := decoder.Decode(&event); err != nil {
+		if _, err = w.out.Write([]byte(Redact(string(p)))); err != nil {
+			return 0, err
+		}
+		return len(p), nil
+	}
+
+	data, err := json.Marshal(redactJSON("", event))
+	if err != nil {
+		return 0, err
+	}
+	if _, err = w.out.Write(append(data, '\n')); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// redactJSON walks a decoded json value and redacts all sensitive fields
+func redactJSON(key string, value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for k, child := range v {
+			v[k] = redactJSON(k, child)
+		}
+		return v
+	case []interface{}:
+		for i, child := range v {
+			v[i] = redactJSON(key, child)
+		}
+		return v
+	case string:
+		return RedactValue(key, v)
+	}
+	return value
+}
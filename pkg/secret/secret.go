@@ -0,0 +1,102 @@
+package secret
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+// Resolver resolves secret references with a specific prefix, e.g. op:op://vault/item/field
+type Resolver interface {
+	Prefix() string                           // Prefix identifies the backend, the reference is passed to Resolve without it
+	Resolve(reference string) (string, error) // Resolve returns the secret value
+}
+
+var (
+	resolvers = map[string]Resolver{}
+	cache     = map[string]string{}
+	mutex     sync.Mutex
+)
+
+func init() {
+	Register(envResolver{})
+	Register(fileResolver{})
+	Register(passResolver{})
+	Register(commandResolver{})
+	Register(keyringResolver{})
+	Register(onePasswordResolver{})
+	Register(bitwardenResolver{})
+	Register(vaultResolver{})
+}
+
+// Register adds a resolver, an existing resolver with the same prefix is replaced
+func Register(r Resolver) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	resolvers[r.Prefix()] = r
+}
+
+// Resolve resolves a secret reference (e.g. env:TOKEN or vault:secret/app#password), values without a known prefix are returned as-is
+// resolved values are cached for the current run, so every secret is only requested once
+func Resolve(value string) (string, error) {
+	prefix, reference, found := strings.Cut(value, ":")
+	if !found {
+		return value, nil
+	}
+
+	mutex.Lock()
+	r, ok := resolvers[prefix]
+	cached, isCached := cache[value]
+	mutex.Unlock()
+	if !ok {
+		return value, nil
+	}
+	if isCached {
+		return cached, nil
+	}
+
+	secret, err := r.Resolve(reference)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s secret: %w", prefix, err)
+	}
+
+	mutex.Lock()
+	cache[value] = secret
+	mutex.Unlock()
+	return secret, nil
+}
+
+// IsReference checks if the value is resolved by one of the resolvers
+func IsReference(value string) bool {
+	prefix, _, found := strings.Cut(value, ":")
+	if !found {
+		return false
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	_, ok := resolvers[prefix]
+	return ok
+}
+
+// ResetCache removes all cached values
+func ResetCache() {
+	mutex.Lock()
+	defer mutex.Unlock()
+	cache = map[string]string{}
+}
+
+// runCommand runs a secret cli, the stdin is passed to allow interactive unlocking (e.g. gpg pinentry)
+func runCommand(name string, args ...string) (string, error) {
+	cmd := exec.Command(name, args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to execute %s: %w", name, err)
+	}
+
+	return strings.TrimRight(string(out), "\r\n"), nil
+}
@@ -0,0 +1,155 @@
+package secret
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeCommand creates an executable script that prints its arguments, the directory is prepended to PATH
+func fakeCommand(t *testing.T, name string, script string) {
+	t.Helper()
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"+script+"\n"), 0o700))
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func TestResolvePlainValue(t *testing.T) {
+	for _, value := range []string{"secret", "https://example.com", "unknown:value"} {
+		resolved, err := Resolve(value)
+		require.NoError(t, err)
+		assert.Equal(t, value, resolved)
+		assert.False(t, IsReference(value))
+	}
+}
+
+func TestResolveFileAndCache(t *testing.T) {
+	ResetCache()
+	file := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(file, []byte("first\n"), 0o600))
+
+	value, err := Resolve("file:" + file)
+	require.NoError(t, err)
+	assert.Equal(t, "first", value)
+
+	// the value is only read once per run
+	require.NoError(t, os.WriteFile(file, []byte("second\n"), 0o600))
+	value, err = Resolve("file:" + file)
+	require.NoError(t, err)
+	assert.Equal(t, "first", value)
+
+	_, err = Resolve("file:" + filepath.Join(t.TempDir(), "missing"))
+	assert.Error(t, err)
+}
+
+func TestResolveCommand(t *testing.T) {
+	ResetCache()
+	t.Setenv("FUZZMUX_ALLOW_COMMANDS", "")
+	_, err := Resolve("cmd:echo secret")
+	assert.Error(t, err)
+
+	t.Setenv("FUZZMUX_ALLOW_COMMANDS", "true")
+	value, err := Resolve("cmd:echo secret")
+	require.NoError(t, err)
+	assert.Equal(t, "secret", value)
+}
+
+func TestResolveCLIBackends(t *testing.T) {
+	ResetCache()
+	fakeCommand(t, "secret-tool", `echo "keyring $*"`)
+	fakeCommand(t, "op", `printf "op $*"`)
+	fakeCommand(t, "bw", `echo "bw $*"`)
+	fakeCommand(t, "pass", `printf "pass $*\nuser: jane\n"`)
+
+	tests := map[string]string{
+		"keyring:jira/jane":                  "keyring lookup service jira username jane",
+		"keyring:service=jira, host=example": "keyring lookup service jira host example",
+		"op:Private/Jira/token":              "op read --no-newline op://Private/Jira/token",
+		"op:op://Private/Jira/token":         "op read --no-newline op://Private/Jira/token",
+		"bw:jira":                            "bw get password jira",
+		"bw:jira#username":                   "bw get username jira",
+		"pass:work/jira":                     "pass show work/jira",
+	}
+	for reference, expected := range tests {
+		value, err := Resolve(reference)
+		require.NoError(t, err, reference)
+		assert.Equal(t, expected, value, reference)
+		assert.True(t, IsReference(reference))
+	}
+
+	_, err := Resolve("bw:jira#password2")
+	assert.Error(t, err)
+	_, err = Resolve("keyring:jira")
+	assert.Error(t, err)
+}
+
+func TestResolveVault(t *testing.T) {
+	ResetCache()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "root" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/v1/secret/data/jira":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]interface{}{"data": map[string]string{"token": "kv2-secret"}}})
+		case "/v1/kv1/jira":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]string{"token": "kv1-secret"}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	t.Setenv("VAULT_ADDR", server.URL)
+	t.Setenv("VAULT_TOKEN", "root")
+
+	value, err := Resolve("vault:secret/jira#token")
+	require.NoError(t, err)
+	assert.Equal(t, "kv2-secret", value)
+
+	value, err = Resolve("vault:kv1/jira#token")
+	require.NoError(t, err)
+	assert.Equal(t, "kv1-secret", value)
+
+	_, err = Resolve("vault:secret/jira#missing")
+	assert.Error(t, err)
+	_, err = Resolve("vault:secret/jira")
+	assert.Error(t, err)
+}
+
+func TestRedact(t *testing.T) {
+	ResetCache()
+	t.Setenv("FUZZMUX_TEST_TOKEN", "s3cr3t-value")
+	_, err := Resolve("env:FUZZMUX_TEST_TOKEN")
+	require.NoError(t, err)
+	MarkSensitive("marked-value", "abc")
+
+	assert.Equal(t, "token="+Redacted+" other="+Redacted+" abc", Redact("token=s3cr3t-value other=marked-value abc"))
+	assert.Equal(t, Redacted, RedactValue("bindPassword", "plain"))
+	assert.Equal(t, "", RedactValue("bindPassword", ""))
+	assert.Equal(t, map[string]string{"RD_TOKEN": Redacted, "host": "example.com"}, RedactMap(map[string]string{"RD_TOKEN": "plain", "host": "example.com"}))
+}
+
+func TestRedactWriter(t *testing.T) {
+	ResetCache()
+	MarkSensitive("s3cr3t-value")
+
+	var out bytes.Buffer
+	w := NewRedactWriter(&out)
+	_, err := w.Write([]byte(`{"level":"debug","module":{"Host":"example.com","BindPassword":"plain"},"command":"export TOKEN=s3cr3t-value","count":5}` + "\n"))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"level":"debug","module":{"Host":"example.com","BindPassword":"`+Redacted+`"},"command":"export TOKEN=`+Redacted+`","count":5}`, out.String())
+
+	out.Reset()
+	_, err = w.Write([]byte("plain text s3cr3t-value\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "plain text "+Redacted+"\n", out.String())
+}
@@ -0,0 +1,92 @@
+package secret
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// vaultResolver reads a field of a HashiCorp Vault KV secret, e.g. vault:secret/jira#token
+// the address and token are taken from VAULT_ADDR and VAULT_TOKEN (or ~/.vault-token), KV v2 and v1 mounts are supported
+type vaultResolver struct{}
+
+func (vaultResolver) Prefix() string {
+	return "vault"
+}
+
+func (vaultResolver) Resolve(reference string) (string, error) {
+	path, field, found := strings.Cut(reference, "#")
+	if !found || field == "" {
+		return "", fmt.Errorf("invalid vault reference %q, expected mount/path#field", reference)
+	}
+	mount, secretPath, found := strings.Cut(strings.Trim(path, "/"), "/")
+	if !found {
+		return "", fmt.Errorf("invalid vault reference %q, expected mount/path#field", reference)
+	}
+
+	addr := strings.TrimSuffix(os.Getenv("VAULT_ADDR"), "/")
+	if addr == "" {
+		return "", fmt.Errorf("VAULT_ADDR is not set")
+	}
+	token := os.Getenv("VAULT_TOKEN")
+	if token == "" {
+		content, err := os.ReadFile(filepath.Join(os.Getenv("HOME"), ".vault-token"))
+		if err != nil {
+			return "", fmt.Errorf("VAULT_TOKEN is not set and ~/.vault-token can't be read: %w", err)
+		}
+		token = strings.TrimSpace(string(content))
+	}
+
+	// KV v2 stores the secret in data.data, KV v1 in data
+	data, err := vaultRead(addr, token, fmt.Sprintf("%s/data/%s", mount, secretPath))
+	if err == nil {
+		if nested, ok := data["data"].(map[string]interface{}); ok {
+			data = nested
+		}
+	} else {
+		data, err = vaultRead(addr, token, fmt.Sprintf("%s/%s", mount, secretPath))
+		if err != nil {
+			return "", err
+		}
+	}
+
+	value, ok := data[field]
+	if !ok {
+		return "", fmt.Errorf("field %q not found in vault secret %s", field, path)
+	}
+	return fmt.Sprintf("%v", value), nil
+}
+
+func vaultRead(addr string, token string, path string) (map[string]interface{}, error) {
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/v1/%s", addr, path), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Vault-Token", token)
+	if namespace := os.Getenv("VAULT_NAMESPACE"); namespace != "" {
+		req.Header.Set("X-Vault-Namespace", namespace)
+	}
+
+	client := http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("vault request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("vault returned status code %d for %s", resp.StatusCode, path)
+	}
+
+	var response struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to decode vault response: %w", err)
+	}
+	return response.Data, nil
+}
@@ -1,49 +1,23 @@
 package util
 
 import (
-	"os"
-	"os/exec"
-	"strings"
-
-	"github.com/rs/zerolog/log"
+	"github.com/PhilippHeuer/fuzzmux/pkg/secret"
 )
 
-func ResolveCredentialValue(value string) string {
-	if strings.HasPrefix(value, "env:") {
-		return os.Getenv(strings.TrimPrefix(value, "env:"))
-	} else if strings.HasPrefix(value, "file:") {
-		file := strings.TrimPrefix(value, "file:")
-		file = strings.Replace(file, "~", os.Getenv("HOME"), 1)
-		file = os.ExpandEnv(file)
-
-		bytes, err := os.ReadFile(file)
-		if err != nil {
-			return ""
-		}
-		return string(bytes)
-	} else if strings.HasPrefix(value, "pass:") {
-		secretPath := os.ExpandEnv(strings.TrimPrefix(value, "pass:"))
-
-		cmd := exec.Command("pass", "show", secretPath)
-		cmd.Stdin = os.Stdin
-		cmd.Stderr = os.Stderr
-		out, err := cmd.Output()
-		if err != nil {
-			log.Fatal().Err(err).Str("secretPath", secretPath).Msg("failed to execute pass command")
-		}
+// ResolveCredentialValue resolves a secret reference (e.g. env:, file:, pass:, keyring:, op:, bw:, vault:), plain values are returned as-is
+func ResolveCredentialValue(value string) (string, error) {
+	return secret.Resolve(value)
+}
 
-		return strings.TrimSpace(string(out))
-	} else if strings.HasPrefix(value, "cmd:") && os.Getenv("FUZZMUX_ALLOW_COMMANDS") == "true" {
-		cmd := exec.Command("sh", "-c", strings.TrimPrefix(value, "cmd:"))
-		cmd.Stdin = os.Stdin
-		cmd.Stderr = os.Stderr
-		out, err := cmd.Output()
+// ResolveCredentialValues resolves all values in place, stops at the first error
+func ResolveCredentialValues(values ...*string) error {
+	for _, v := range values {
+		resolved, err := secret.Resolve(*v)
 		if err != nil {
-			log.Fatal().Err(err).Str("command", value).Msg("failed to execute password command")
+			return err
 		}
-
-		return strings.TrimSpace(string(out))
+		*v = resolved
 	}
 
-	return value
+	return nil
 }
@@ -2,13 +2,33 @@ package util
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestResolveCredentialValue(t *testing.T) {
-	assert.Equal(t, "test", ResolveCredentialValue("test"))
-	_ = os.Setenv("TEST", "hello mum")
-	assert.Equal(t, "hello mum", ResolveCredentialValue("env:TEST"))
+	value, err := ResolveCredentialValue("test")
+	require.NoError(t, err)
+	assert.Equal(t, "test", value)
+
+	t.Setenv("TEST", "hello mum")
+	value, err = ResolveCredentialValue("env:TEST")
+	require.NoError(t, err)
+	assert.Equal(t, "hello mum", value)
+}
+
+func TestResolveCredentialValues(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(file, []byte("secret\n"), 0o600))
+
+	host, token := "https://example.com", "file:"+file
+	require.NoError(t, ResolveCredentialValues(&host, &token))
+	assert.Equal(t, "https://example.com", host)
+	assert.Equal(t, "secret", token)
+
+	missing := "file:" + filepath.Join(t.TempDir(), "missing")
+	assert.Error(t, ResolveCredentialValues(&missing))
 }
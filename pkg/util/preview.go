@@ -0,0 +1,77 @@
+package util
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/adrg/xdg"
+	"github.com/rs/zerolog/log"
+)
+
+var previewCacheDir = filepath.Join(xdg.CacheHome, "fuzzmux", "preview")
+
+// PreviewCommandOptions configures the execution of preview commands
+type PreviewCommandOptions struct {
+	Timeout  time.Duration // Timeout is the maximum runtime, the partial output is shown afterward
+	CacheAge time.Duration // CacheAge is the time the output is reused, zero disables the cache
+}
+
+// RunPreviewCommand runs the command using sh and returns its output, errors and timeouts are part of the output
+// the output is cached in a file, because fzf runs a new process for every highlighted option
+func RunPreviewCommand(command string, dir string, opts PreviewCommandOptions) string {
+	cacheFile := filepath.Join(previewCacheDir, previewCacheKey(command, dir))
+	if opts.CacheAge > 0 {
+		if info, err := os.Stat(cacheFile); err == nil && time.Since(info.ModTime()) < opts.CacheAge {
+			if output, err := os.ReadFile(cacheFile); err == nil {
+				return string(output)
+			}
+		}
+	}
+
+	output := runPreviewCommand(command, dir, opts.Timeout)
+
+	if opts.CacheAge > 0 {
+		if err := os.MkdirAll(previewCacheDir, 0700); err != nil {
+			log.Debug().Err(err).Msg("failed to create preview cache directory")
+		} else if err = os.WriteFile(cacheFile, []byte(output), 0600); err != nil {
+			log.Debug().Err(err).Msg("failed to write preview cache")
+		}
+	}
+
+	return output
+}
+
+func runPreviewCommand(command string, dir string, timeout time.Duration) string {
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	log.Debug().Str("command", command).Str("dir", dir).Msg("running preview command")
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Dir = dir
+	cmd.WaitDelay = 100 * time.Millisecond // child processes could keep the output open after the timeout
+	output, err := cmd.CombinedOutput()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return string(output) + fmt.Sprintf("\npreview command timed out after %s\n", timeout)
+	} else if err != nil {
+		return string(output) + fmt.Sprintf("\npreview command failed: %s\n", err)
+	}
+
+	return string(output)
+}
+
+// previewCacheKey identifies the output of a command, the directory is part of the key for commands like git log
+func previewCacheKey(command string, dir string) string {
+	hash := sha256.Sum256([]byte(dir + "\x00" + command))
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,41 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunPreviewCommand(t *testing.T) {
+	previewCacheDir = t.TempDir()
+	dir := t.TempDir()
+	counter := filepath.Join(dir, "counter")
+
+	// the output is cached, the command only runs once
+	opts := PreviewCommandOptions{Timeout: 5 * time.Second, CacheAge: time.Minute}
+	assert.Equal(t, dir+"\n", RunPreviewCommand("echo run >> counter && pwd", dir, opts))
+	assert.Equal(t, dir+"\n", RunPreviewCommand("echo run >> counter && pwd", dir, opts))
+	content, _ := os.ReadFile(counter)
+	assert.Equal(t, "run\n", string(content))
+
+	// without cache
+	RunPreviewCommand("echo run >> counter", dir, PreviewCommandOptions{Timeout: 5 * time.Second})
+	content, _ = os.ReadFile(counter)
+	assert.Equal(t, "run\nrun\n", string(content))
+}
+
+func TestRunPreviewCommandErrors(t *testing.T) {
+	previewCacheDir = t.TempDir()
+	opts := PreviewCommandOptions{Timeout: 200 * time.Millisecond}
+
+	output := RunPreviewCommand("echo partial && sleep 5", t.TempDir(), opts)
+	assert.Contains(t, output, "partial\n")
+	assert.Contains(t, output, "preview command timed out after 200ms")
+
+	output = RunPreviewCommand("echo oops >&2 && exit 3", t.TempDir(), opts)
+	assert.Contains(t, output, "oops\n")
+	assert.Contains(t, output, "preview command failed: exit status 3")
+}